@@ -15,12 +15,25 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/victor-nach/postr-backend/internal/config"
+	"github.com/victor-nach/postr-backend/internal/domain"
 	"github.com/victor-nach/postr-backend/internal/handlers"
 	"github.com/victor-nach/postr-backend/internal/infrastructure/db"
+	"github.com/victor-nach/postr-backend/internal/infrastructure/mailer"
 	"github.com/victor-nach/postr-backend/internal/infrastructure/repositories"
+	"github.com/victor-nach/postr-backend/internal/jobs"
+	"github.com/victor-nach/postr-backend/internal/services/apikeysservice"
+	"github.com/victor-nach/postr-backend/internal/services/auditservice"
+	"github.com/victor-nach/postr-backend/internal/services/authservice"
+	"github.com/victor-nach/postr-backend/internal/services/commentsservice"
+	"github.com/victor-nach/postr-backend/internal/services/followsservice"
+	"github.com/victor-nach/postr-backend/internal/services/passwordresetservice"
 	"github.com/victor-nach/postr-backend/internal/services/postsservice"
+	"github.com/victor-nach/postr-backend/internal/services/reactionsservice"
+	"github.com/victor-nach/postr-backend/internal/services/tagsservice"
 	"github.com/victor-nach/postr-backend/internal/services/usersservice"
+	"github.com/victor-nach/postr-backend/internal/services/verificationservice"
 	"github.com/victor-nach/postr-backend/pkg/logger"
+	"github.com/victor-nach/postr-backend/pkg/ratelimit"
 )
 
 func main() {
@@ -51,22 +64,90 @@ func main() {
 	// Initialize repos
 	userRepo := repositories.NewUserRepository(gormDB)
 	postRepo := repositories.NewPostRepository(gormDB)
+	apiKeyRepo := repositories.NewAPIKeyRepository(gormDB)
+	sessionRepo := repositories.NewSessionRepository(gormDB)
+	auditRepo := repositories.NewAuditRepository(gormDB)
+	throttleRepo := repositories.NewLoginThrottleRepository(gormDB)
+	commentRepo := repositories.NewCommentRepository(gormDB)
+	reactionRepo := repositories.NewReactionRepository(gormDB)
+	followRepo := repositories.NewFollowRepository(gormDB)
+	tagRepo := repositories.NewTagRepository(gormDB)
+	mentionRepo := repositories.NewMentionRepository(gormDB)
+	transactor := repositories.NewTransactor(gormDB)
+
+	outbox, err := mailer.NewOutbox(cfg.MailOutboxDir, cfg.MailFrom)
+	if err != nil {
+		logr.Fatal("failed to initialize mailer", zap.Error(err))
+	}
 
 	// Initialize services
-	userSvc := usersservice.New(userRepo, logr)
-	postSvc := postsservice.New(postRepo, userRepo, logr)
+	verificationSvc := verificationservice.New(userRepo, outbox, cfg.JWTSecret, cfg.VerificationTTL, cfg.PublicURL, logr)
+	userSvc := usersservice.New(userRepo, transactor, auditRepo, verificationSvc, logr)
+	postSvc := postsservice.New(postRepo, userRepo, reactionRepo, tagRepo, mentionRepo, transactor, auditRepo, outbox, cfg.PublicURL, logr)
+	authSvc := authservice.New(userRepo, sessionRepo, throttleRepo, cfg.JWTSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL, cfg.LoginLockout, logr)
+	apiKeySvc := apikeysservice.New(apiKeyRepo, userRepo, logr)
+	passwordResetSvc := passwordresetservice.New(userRepo, outbox, cfg.JWTSecret, cfg.PasswordResetTTL, cfg.PublicURL, logr)
+	auditSvc := auditservice.New(auditRepo, logr)
+	commentSvc := commentsservice.New(commentRepo, postRepo, userRepo, transactor, auditRepo, logr)
+	reactionSvc := reactionsservice.New(reactionRepo, postRepo, logr)
+	followSvc := followsservice.New(followRepo, userRepo, logr)
+	tagSvc := tagsservice.New(tagRepo, logr)
+
+	// Start background jobs, they stop when main returns
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	purger := jobs.NewPurger(postRepo, userRepo, sessionRepo, throttleRepo, cfg.PurgeRetention, cfg.PurgeInterval, logr)
+	go purger.Run(jobsCtx)
 
 	// Initialize handlers
-	userHandler := handlers.NewUserHandler(userSvc, logr)
+	userHandler := handlers.NewUserHandler(userSvc, cfg.UserDeletePolicy, logr)
 	postHandler := handlers.NewPostHandler(postSvc,  logr)
+	authHandler := handlers.NewAuthHandler(authSvc, logr)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeySvc, logr)
+	verificationHandler := handlers.NewVerificationHandler(verificationSvc, logr)
+	passwordResetHandler := handlers.NewPasswordResetHandler(passwordResetSvc, logr)
+	auditHandler := handlers.NewAuditHandler(auditSvc, logr)
+	commentHandler := handlers.NewCommentHandler(commentSvc, logr)
+	reactionHandler := handlers.NewReactionHandler(reactionSvc, logr)
+	followHandler := handlers.NewFollowHandler(followSvc, logr)
+	tagHandler := handlers.NewTagHandler(tagSvc, logr)
+
+	limiter := ratelimit.New()
+	authFailureLimit := handlers.AuthFailureLimit(limiter, cfg.AuthFailureLimit, logr)
+	authenticate := handlers.Authenticate(authSvc, apiKeySvc, logr)
+	rateLimit := handlers.RateLimit(limiter, cfg.RateLimit, cfg.RouteRateLimits, logr)
 
-	RunServer(cfg.Port, userHandler, postHandler, logr)
+	router, err := createRouter(routerDeps{
+		trustedProxies:       cfg.TrustedProxies,
+		authFailureLimit:     authFailureLimit,
+		authenticate:         authenticate,
+		rateLimit:            rateLimit,
+		authHandler:          authHandler,
+		verificationHandler:  verificationHandler,
+		passwordResetHandler: passwordResetHandler,
+		userHandler:          userHandler,
+		postHandler:          postHandler,
+		commentHandler:       commentHandler,
+		reactionHandler:      reactionHandler,
+		followHandler:        followHandler,
+		tagHandler:           tagHandler,
+		apiKeyHandler:        apiKeyHandler,
+		auditHandler:         auditHandler,
+	})
+	if err != nil {
+		logr.Fatal("failed to create router", zap.Error(err))
+	}
+
+	RunServer(cfg.Port, router, logr)
+
+	// Requests are drained by now, the reset emails they asked for are still sent
+	passwordResetSvc.Wait()
 }
 
-// RunServer creates and mounts the router, starts the server in a goroutine,
+// RunServer starts the server with the router in a goroutine,
 // and listens for OS signals to gracefully shutdown
-func RunServer(port string, userHandler *handlers.UserHandler, postHandler *handlers.PostHandler, logr *zap.Logger) {
-	router := createRouter(userHandler, postHandler)
+func RunServer(port string, router http.Handler, logr *zap.Logger) {
 
 	srv := &http.Server{
 		Addr:    ":" + port,
@@ -99,19 +180,113 @@ func RunServer(port string, userHandler *handlers.UserHandler, postHandler *hand
 	logr.Info("Server exiting")
 }
 
-func createRouter(userHandler *handlers.UserHandler, postHandler *handlers.PostHandler) http.Handler {
+// routerDeps are what createRouter mounts: the middleware run on every request and the handlers of the routes.
+// Client IPs are only read from X-Forwarded-For when the request comes through one of trustedProxies
+type routerDeps struct {
+	trustedProxies []string
+
+	authFailureLimit gin.HandlerFunc
+	authenticate     gin.HandlerFunc
+	rateLimit        gin.HandlerFunc
+
+	authHandler          *handlers.AuthHandler
+	verificationHandler  *handlers.VerificationHandler
+	passwordResetHandler *handlers.PasswordResetHandler
+	userHandler          *handlers.UserHandler
+	postHandler          *handlers.PostHandler
+	commentHandler       *handlers.CommentHandler
+	reactionHandler      *handlers.ReactionHandler
+	followHandler        *handlers.FollowHandler
+	tagHandler           *handlers.TagHandler
+	apiKeyHandler        *handlers.APIKeyHandler
+	auditHandler         *handlers.AuditHandler
+}
+
+// createRouter mounts the routes. Posts can be read by anyone and anyone can sign up, log in, verify their
+// email and reset their password, everything else needs an access token or API key granting the permission the
+// route requires, see domain.RolePermissions. Every route is rate limited per client, as are invalid credentials
+// per IP before they are checked, and every request is given an id that the audit log records changes with
+func createRouter(deps routerDeps) (http.Handler, error) {
 	router := gin.Default()
+	if err := router.SetTrustedProxies(deps.trustedProxies); err != nil {
+		return nil, err
+	}
 
 	router.Use(cors.Default())
+	router.Use(handlers.RequestID())
+	router.Use(deps.authFailureLimit)
+	router.Use(deps.authenticate)
+	router.Use(deps.rateLimit)
+
+	router.POST("/auth/login", deps.authHandler.Login)
+	router.POST("/auth/refresh", deps.authHandler.Refresh)
+	router.POST("/auth/logout", handlers.RequireAuth(), deps.authHandler.Logout)
+	router.GET("/auth/verify", deps.verificationHandler.VerifyEmail)
+	router.POST("/auth/verify/resend", handlers.RequireAuth(), deps.verificationHandler.ResendVerification)
+	router.POST("/auth/password/forgot", deps.passwordResetHandler.ForgotPassword)
+	router.POST("/auth/password/reset", deps.passwordResetHandler.ResetPassword)
+
+	router.POST("/users", deps.userHandler.CreateUser)
+	router.GET("/users", handlers.RequirePermission(domain.PermUsersRead), deps.userHandler.ListUsers)
+	router.GET("/users/count", handlers.RequirePermission(domain.PermUsersRead), deps.userHandler.CountUsers)
+	router.GET("/users/:id", handlers.RequireSelfOrPermission("id", domain.PermUsersRead), deps.userHandler.GetUserByID)
+	router.GET("/users/:id/posts", deps.postHandler.ListPostsByUserID)
+	router.GET("/users/:id/mentions", deps.postHandler.ListMentions)
+	router.GET("/users/:id/followers", deps.followHandler.ListFollowers)
+	router.GET("/users/:id/following", deps.followHandler.ListFollowing)
+	router.POST("/users/:id/follow", handlers.RequirePermission(domain.PermPostsWrite), deps.followHandler.Follow)
+	router.DELETE("/users/:id/follow", handlers.RequirePermission(domain.PermPostsWrite), deps.followHandler.Unfollow)
+
+	router.PATCH("/users/:id", handlers.RequireSelfOrPermission("id", domain.PermUsersWrite), deps.userHandler.UpdateUser)
+	router.PUT("/users/:id", handlers.RequireSelfOrPermission("id", domain.PermUsersWrite), deps.userHandler.ReplaceUser)
+	router.DELETE("/users/:id", handlers.RequireSelfOrPermission("id", domain.PermUsersWrite), deps.userHandler.DeleteUser)
+	router.POST("/users/:id/restore", handlers.RequirePermission(domain.PermUsersWrite), deps.userHandler.RestoreUser)
+
+	router.GET("/users/:id/sessions", handlers.RequireSelfOrPermission("id", domain.PermSessionsManage), deps.authHandler.ListUserSessions)
+	router.DELETE("/users/:id/sessions", handlers.RequireSelfOrPermission("id", domain.PermSessionsManage), deps.authHandler.RevokeUserSessions)
+	router.DELETE("/users/:id/sessions/:sessionId", handlers.RequireSelfOrPermission("id", domain.PermSessionsManage), deps.authHandler.RevokeUserSession)
+
+	router.GET("/feed", handlers.RequireAuth(), deps.postHandler.Feed)
+
+	router.GET("/posts", deps.postHandler.ListPosts)
+	router.GET("/posts/search", deps.postHandler.SearchPosts)
+	router.GET("/posts/:id", deps.postHandler.GetPost)
+	router.GET("/posts/:id/revisions", deps.postHandler.ListPostRevisions)
+	router.GET("/posts/:id/revisions/diff", deps.postHandler.DiffPostRevisions)
+
+	router.GET("/tags", deps.tagHandler.ListTags)
+	router.GET("/tags/:name/posts", deps.postHandler.ListPostsByTag)
+
+	// Whether the caller wrote the post, or may moderate posts, and whether authors verified their email is
+	// checked by the posts service
+	router.POST("/posts", handlers.RequirePermission(domain.PermPostsWrite), deps.postHandler.CreatePost)
+	router.PATCH("/posts/:id", handlers.RequirePermission(domain.PermPostsWrite), deps.postHandler.UpdatePost)
+	router.DELETE("/posts/:id", handlers.RequirePermission(domain.PermPostsWrite), deps.postHandler.DeletePost)
+	router.POST("/posts/:id/restore", handlers.RequirePermission(domain.PermPostsWrite), deps.postHandler.RestorePost)
+
+	// Whether the caller wrote the comment, or may moderate posts, is checked by the comments service
+	router.GET("/posts/:id/comments", deps.commentHandler.ListComments)
+	router.POST("/posts/:id/comments", handlers.RequirePermission(domain.PermPostsWrite), deps.commentHandler.CreateComment)
+	router.DELETE("/comments/:id", handlers.RequirePermission(domain.PermPostsWrite), deps.commentHandler.DeleteComment)
+
+	router.PUT("/posts/:id/reactions/:kind", handlers.RequirePermission(domain.PermPostsWrite), deps.reactionHandler.React)
+	router.DELETE("/posts/:id/reactions/:kind", handlers.RequirePermission(domain.PermPostsWrite), deps.reactionHandler.Unreact)
+
+	roles := router.Group("/admin/users/:id/roles", handlers.RequirePermission(domain.PermRolesWrite))
+
+	roles.GET("", deps.userHandler.ListUserRoles)
+	roles.PUT("/:role", deps.userHandler.GrantUserRole)
+	roles.DELETE("/:role", deps.userHandler.RevokeUserRole)
+
+	apiKeys := router.Group("/admin/api-keys", handlers.RequirePermission(domain.PermAPIKeysWrite))
+
+	apiKeys.POST("", deps.apiKeyHandler.CreateAPIKey)
+	apiKeys.GET("", deps.apiKeyHandler.ListAPIKeys)
+	apiKeys.DELETE("/:id", deps.apiKeyHandler.RevokeAPIKey)
 
-	router.POST("/users", userHandler.CreateUser)
-	router.GET("/users", userHandler.ListUsers)
-	router.GET("/users/count", userHandler.CountUsers)
-	router.GET("/users/:id", userHandler.GetUserByID)
+	router.DELETE("/admin/users/:id/lockout", handlers.RequirePermission(domain.PermUsersWrite), deps.authHandler.UnlockUser)
 
-	router.POST("/posts", postHandler.CreatePost)
-	router.DELETE("/posts/:id", postHandler.DeletePost)
-	router.GET("/posts/:userId", postHandler.ListPostsByUserID)
+	router.GET("/admin/audit", handlers.RequirePermission(domain.PermAuditRead), deps.auditHandler.ListAuditEvents)
 
-	return router
+	return router, nil
 }
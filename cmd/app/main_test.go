@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+	"github.com/victor-nach/postr-backend/internal/domain/mocks"
+	"github.com/victor-nach/postr-backend/internal/handlers"
+	"github.com/victor-nach/postr-backend/internal/infrastructure/db"
+	"github.com/victor-nach/postr-backend/internal/infrastructure/repositories"
+	"github.com/victor-nach/postr-backend/internal/services/postsservice"
+	"github.com/victor-nach/postr-backend/pkg/migrator"
+	"github.com/victor-nach/postr-backend/pkg/ratelimit"
+)
+
+func TestCreateRouter_TrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Anonymous logouts are refused before reaching the handlers, but are rate limited first
+	newRouter := func(trustedProxies []string) http.Handler {
+		rateLimit := handlers.RateLimit(ratelimit.New(), ratelimit.Limit{Requests: 5, Per: time.Minute}, nil, zap.NewNop())
+		next := func(c *gin.Context) { c.Next() }
+		router, err := createRouter(routerDeps{trustedProxies: trustedProxies, authFailureLimit: next, authenticate: next, rateLimit: rateLimit})
+		require.NoError(t, err)
+		return router
+	}
+	remaining := func(router http.Handler, remoteAddr string, forwardedFor string) string {
+		req, err := http.NewRequest("POST", "/auth/logout", nil)
+		require.NoError(t, err)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		return w.Header().Get("RateLimit-Remaining")
+	}
+
+	// By default a spoofed X-Forwarded-For does not get the client a fresh limit
+	router := newRouter(nil)
+	require.Equal(t, "4", remaining(router, "203.0.113.7:1234", "198.51.100.1"))
+	require.Equal(t, "3", remaining(router, "203.0.113.7:1234", "198.51.100.2"))
+
+	// Behind a trusted proxy, clients are told apart by the address it forwards
+	router = newRouter([]string{"10.0.0.0/8"})
+	require.Equal(t, "4", remaining(router, "10.0.0.2:1234", "198.51.100.1"))
+	require.Equal(t, "4", remaining(router, "10.0.0.2:1234", "198.51.100.2"))
+	require.Equal(t, "3", remaining(router, "10.0.0.3:1234", "198.51.100.2"))
+
+	_, err := createRouter(routerDeps{trustedProxies: []string{"not-an-ip"}})
+	require.Error(t, err)
+}
+
+func TestCreateRouter_LoginClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Failed logins count against the address the login comes from, which sessions and audit events record too,
+	// whatever X-Forwarded-For says
+	var audited string
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockAuthService.EXPECT().Login(gomock.Any(), "alice@example.com", "password", domain.SessionClient{UserAgent: "test", IPAddress: "203.0.113.7"}).
+		DoAndReturn(func(ctx context.Context, email string, password string, client domain.SessionClient) (domain.AccessToken, error) {
+			request, _ := domain.RequestFromContext(ctx)
+			audited = request.IPAddress
+			return domain.AccessToken{}, domain.ErrInvalidCredentials
+		})
+
+	next := func(c *gin.Context) { c.Next() }
+	rateLimit := handlers.RateLimit(ratelimit.New(), ratelimit.Limit{}, nil, zap.NewNop())
+	authHandler := handlers.NewAuthHandler(mockAuthService, zap.NewNop())
+	router, err := createRouter(routerDeps{authFailureLimit: next, authenticate: next, rateLimit: rateLimit, authHandler: authHandler})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("POST", "/auth/login", strings.NewReader(`{"email":"alice@example.com","password":"password"}`))
+	require.NoError(t, err)
+	req.RemoteAddr = "203.0.113.7:1234"
+	req.Header.Set("User-Agent", "test")
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Equal(t, "203.0.113.7", audited)
+}
+
+func TestCreateRouter_FollowPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFollowService := mocks.NewMockFollowService(ctrl)
+	mockFollowService.EXPECT().Follow(gomock.Any(), gomock.Any()).Return(nil)
+	mockFollowService.EXPECT().Unfollow(gomock.Any(), "u1", "u2").Return(nil)
+
+	// Callers are told apart by the scope header, a key's scope limiting what its owner may do
+	authenticate := func(c *gin.Context) {
+		identity := domain.Identity{UserID: "u1", Roles: []domain.Role{domain.RoleMember}}
+		if scope := c.GetHeader("X-Test-Scope"); scope != "" {
+			identity.APIKeyID = "key-1"
+			identity.Scopes = domain.Scopes{domain.Scope(scope)}
+		}
+		c.Request = c.Request.WithContext(domain.ContextWithIdentity(c.Request.Context(), identity))
+	}
+	next := func(c *gin.Context) { c.Next() }
+	rateLimit := handlers.RateLimit(ratelimit.New(), ratelimit.Limit{}, nil, zap.NewNop())
+	followHandler := handlers.NewFollowHandler(mockFollowService, zap.NewNop())
+	router, err := createRouter(routerDeps{authFailureLimit: next, authenticate: authenticate, rateLimit: rateLimit, followHandler: followHandler})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		method string
+		scope  domain.Scope
+		status int
+	}{
+		{"read-only key following", "POST", domain.ScopeReadOnly, http.StatusForbidden},
+		{"read-only key unfollowing", "DELETE", domain.ScopeReadOnly, http.StatusForbidden},
+		{"posts:write key following", "POST", domain.ScopePostsWrite, http.StatusOK},
+		{"session unfollowing", "DELETE", "", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, "/users/u2/follow", nil)
+			require.NoError(t, err)
+			req.Header.Set("X-Test-Scope", string(tt.scope))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusForbidden {
+				require.Contains(t, w.Body.String(), "AUTH-403001")
+			}
+		})
+	}
+}
+
+func TestCreateRouter_LegacyListPostsByUserID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The deprecated route reads through the real service and repositories, on a database with every migration
+	sqlDB, err := sql.Open("sqlite", "file:"+t.TempDir()+"/app.db?_pragma=foreign_keys(1)")
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	require.NoError(t, migrator.Migrate(sqlDB, "file://../../migrations"))
+	gormDB, err := gorm.Open(sqlite.Dialector{Conn: db.UTC(sqlDB)}, &gorm.Config{})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	userRepo := repositories.NewUserRepository(gormDB)
+	postRepo := repositories.NewPostRepository(gormDB)
+	postSvc := postsservice.New(postRepo, userRepo, repositories.NewReactionRepository(gormDB), repositories.NewTagRepository(gormDB),
+		repositories.NewMentionRepository(gormDB), repositories.NewTransactor(gormDB), repositories.NewAuditRepository(gormDB), nil, "", zap.NewNop())
+
+	user := &domain.User{ID: "18de9b2e-7ebc-4624-9bb6-4c1ba4ea11e2", Firstname: "Alice", Lastname: "Smith", Email: "alice@example.com", CreatedAt: time.Now()}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	// More posts than fit in the largest page
+	created := time.Now()
+	for i := range 150 {
+		post := &domain.Post{ID: fmt.Sprintf("post-%03d", i), UserID: user.ID, Title: "Title", Body: "Body", CreatedAt: created.Add(time.Duration(i) * time.Second)}
+		post.UpdatedAt = post.CreatedAt
+		require.NoError(t, postRepo.Create(ctx, post))
+	}
+
+	next := func(c *gin.Context) { c.Next() }
+	rateLimit := handlers.RateLimit(ratelimit.New(), ratelimit.Limit{}, nil, zap.NewNop())
+	postHandler := handlers.NewPostHandler(postSvc, zap.NewNop())
+	router, err := createRouter(routerDeps{authFailureLimit: next, authenticate: next, rateLimit: rateLimit, postHandler: postHandler})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("GET", "/posts/"+user.ID, nil)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, "true", w.Header().Get("Deprecation"))
+
+	var resp struct {
+		Data []domain.Post `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 150)
+	require.Equal(t, "post-149", resp.Data[0].ID)
+	require.Equal(t, "post-000", resp.Data[149].ID)
+
+	// Missing users are not found
+	req, err = http.NewRequest("GET", "/posts/a3c9e5f1-7d2b-4b6a-8e0f-5d1c3b7a9e42", nil)
+	require.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
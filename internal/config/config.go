@@ -1,26 +1,127 @@
 package config
 
 import (
+	"crypto/rand"
+	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+	"github.com/victor-nach/postr-backend/pkg/ratelimit"
 )
 
 const (
 	// Environment variable keys
-	EnvPort   = "PORT"
-	EnvAppEnv = "APP_ENV"
+	EnvPort               = "PORT"
+	EnvAppEnv             = "APP_ENV"
+	EnvUserDeletePolicy   = "USER_DELETE_POLICY"
+	EnvPurgeRetention     = "PURGE_RETENTION"
+	EnvPurgeInterval      = "PURGE_INTERVAL"
+	EnvJWTSecret          = "JWT_SECRET"
+	EnvAccessTokenTTL     = "ACCESS_TOKEN_TTL"
+	EnvRefreshTokenTTL    = "REFRESH_TOKEN_TTL"
+	EnvPublicURL          = "PUBLIC_URL"
+	EnvMailOutboxDir      = "MAIL_OUTBOX_DIR"
+	EnvMailFrom           = "MAIL_FROM"
+	EnvVerificationTTL    = "EMAIL_VERIFICATION_TTL"
+	EnvPasswordResetTTL   = "PASSWORD_RESET_TTL"
+	EnvRateLimit          = "RATE_LIMIT"
+	EnvRouteRateLimits    = "RATE_LIMIT_ROUTES"
+	EnvAuthFailureLimit   = "AUTH_FAILURE_LIMIT"
+	EnvLoginMaxFailures   = "LOGIN_MAX_FAILURES"
+	EnvLoginMaxIPFailures = "LOGIN_MAX_IP_FAILURES"
+	EnvLoginBackoff       = "LOGIN_BACKOFF"
+	EnvLoginMaxBackoff    = "LOGIN_MAX_BACKOFF"
+	EnvLoginLockout       = "LOGIN_LOCKOUT_DURATION"
+	EnvTrustedProxies     = "TRUSTED_PROXIES"
 
 	// Default values
-	DefaultPort   = "8080"
-	DefaultAppEnv = "production"
+	DefaultPort             = "8080"
+	DefaultAppEnv           = "production"
+	DefaultUserDeletePolicy = domain.UserDeleteRestrict
+	DefaultPurgeRetention   = 30 * 24 * time.Hour
+	DefaultPurgeInterval    = time.Hour
+	DefaultAccessTokenTTL   = 15 * time.Minute
+	DefaultRefreshTokenTTL  = 30 * 24 * time.Hour
+	DefaultMailOutboxDir    = "data/outbox"
+	DefaultMailFrom         = "Postr <no-reply@postr.local>"
+	DefaultVerificationTTL  = 24 * time.Hour
+	DefaultPasswordResetTTL = time.Hour
+
+	// MinJWTSecretLength is the least number of bytes of an HS256 signing secret
+	MinJWTSecretLength = 32
+)
+
+var (
+	DefaultRateLimit = ratelimit.Limit{Requests: 300, Per: time.Minute}
+	// DefaultAuthFailureLimit lets an IP address send 30 invalid access tokens or API keys a minute
+	DefaultAuthFailureLimit = ratelimit.Limit{Requests: 30, Per: time.Minute}
+	// DefaultLoginLockout locks an account out for 15 minutes after 5 failed logins, and an IP address after 50
+	DefaultLoginLockout = domain.LoginLockout{
+		MaxFailures:   5,
+		MaxIPFailures: 50,
+		Backoff:       time.Second,
+		MaxBackoff:    time.Minute,
+		Duration:      15 * time.Minute,
+	}
+	// DefaultRouteRateLimits are tighter on the routes most worth flooding, RATE_LIMIT_ROUTES adds to them
+	DefaultRouteRateLimits = map[string]ratelimit.Limit{
+		"POST /posts":                {Requests: 30, Per: time.Minute},
+		"GET /users":                 {Requests: 60, Per: time.Minute},
+		"POST /auth/login":           {Requests: 10, Per: time.Minute},
+		"POST /auth/password/forgot": {Requests: 5, Per: time.Minute},
+	}
 )
 
 // Config holds the application configuration
 type Config struct {
 	Port   string
 	AppEnv string
+
+	// UserDeletePolicy is applied when a delete user request does not specify one
+	UserDeletePolicy domain.UserDeletePolicy
+
+	// PurgeRetention is how long deleted posts and users are kept before they are purged for good
+	PurgeRetention time.Duration
+	// PurgeInterval is how often the purge job runs
+	PurgeInterval time.Duration
+
+	// JWTSecret signs the access tokens
+	JWTSecret []byte
+	// AccessTokenTTL is how long an access token is valid for
+	AccessTokenTTL time.Duration
+	// RefreshTokenTTL is how long a session lasts without being refreshed
+	RefreshTokenTTL time.Duration
+
+	// PublicURL is where clients reach the API, links in emails point to it
+	PublicURL string
+	// MailOutboxDir is the directory emails are written to
+	MailOutboxDir string
+	// MailFrom is the sender of every email
+	MailFrom string
+	// VerificationTTL is how long an email verification link is valid for
+	VerificationTTL time.Duration
+	// PasswordResetTTL is how long a password reset token is valid for
+	PasswordResetTTL time.Duration
+
+	// RateLimit is how fast each client can call a route without a limit of its own in RouteRateLimits
+	RateLimit ratelimit.Limit
+	// RouteRateLimits are the limits of single routes, keyed by method and path such as "GET /users/:id"
+	RouteRateLimits map[string]ratelimit.Limit
+	// AuthFailureLimit is how many requests with invalid credentials each IP address can send
+	AuthFailureLimit ratelimit.Limit
+	// LoginLockout is how failed logins back off and lock out accounts and IP addresses
+	LoginLockout domain.LoginLockout
+	// TrustedProxies are the IPs and CIDR ranges of the proxies whose X-Forwarded-For headers are believed.
+	// Without any, clients are told apart by the address they connect from
+	TrustedProxies []string
 }
 
 // Load reads configuration from the environment and loads the .env file in the project root if available
@@ -42,15 +143,273 @@ func Load(logger *zap.Logger) (*Config, error) {
 		appEnv = DefaultAppEnv
 	}
 
+	deletePolicy := DefaultUserDeletePolicy
+	if v, ok := os.LookupEnv(EnvUserDeletePolicy); ok {
+		deletePolicy = domain.UserDeletePolicy(v)
+		if !deletePolicy.Valid() {
+			return nil, fmt.Errorf("invalid %s %q", EnvUserDeletePolicy, v)
+		}
+	}
+
+	purgeRetention, err := durationEnv(EnvPurgeRetention, DefaultPurgeRetention)
+	if err != nil {
+		return nil, err
+	}
+
+	purgeInterval, err := durationEnv(EnvPurgeInterval, DefaultPurgeInterval)
+	if err != nil {
+		return nil, err
+	}
+	if purgeInterval <= 0 {
+		return nil, fmt.Errorf("invalid %s %q, must be positive", EnvPurgeInterval, purgeInterval)
+	}
+
+	// Development runs may do without a secret, tokens then stop working when the app restarts
+	jwtSecret := []byte(os.Getenv(EnvJWTSecret))
+	if len(jwtSecret) == 0 && appEnv == "development" {
+		jwtSecret = make([]byte, MinJWTSecretLength)
+		if _, err := rand.Read(jwtSecret); err != nil {
+			return nil, fmt.Errorf("failed to generate a %s: %w", EnvJWTSecret, err)
+		}
+		logger.Warn("no JWT_SECRET set, using a random one")
+	}
+	if len(jwtSecret) < MinJWTSecretLength {
+		return nil, fmt.Errorf("%s must be at least %d bytes long", EnvJWTSecret, MinJWTSecretLength)
+	}
+
+	accessTokenTTL, err := durationEnv(EnvAccessTokenTTL, DefaultAccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	if accessTokenTTL <= 0 {
+		return nil, fmt.Errorf("invalid %s %q, must be positive", EnvAccessTokenTTL, accessTokenTTL)
+	}
+
+	refreshTokenTTL, err := durationEnv(EnvRefreshTokenTTL, DefaultRefreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	if refreshTokenTTL <= accessTokenTTL {
+		return nil, fmt.Errorf("invalid %s %q, must be longer than %s", EnvRefreshTokenTTL, refreshTokenTTL, EnvAccessTokenTTL)
+	}
+
+	publicURL, ok := os.LookupEnv(EnvPublicURL)
+	if !ok {
+		publicURL = "http://localhost:" + port
+	}
+	publicURL = strings.TrimSuffix(publicURL, "/")
+	if u, err := url.Parse(publicURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid %s %q, must be an http or https URL", EnvPublicURL, publicURL)
+	}
+
+	mailOutboxDir, ok := os.LookupEnv(EnvMailOutboxDir)
+	if !ok {
+		mailOutboxDir = DefaultMailOutboxDir
+	}
+
+	mailFrom, ok := os.LookupEnv(EnvMailFrom)
+	if !ok {
+		mailFrom = DefaultMailFrom
+	}
+
+	verificationTTL, err := durationEnv(EnvVerificationTTL, DefaultVerificationTTL)
+	if err != nil {
+		return nil, err
+	}
+	if verificationTTL <= 0 {
+		return nil, fmt.Errorf("invalid %s %q, must be positive", EnvVerificationTTL, verificationTTL)
+	}
+
+	passwordResetTTL, err := durationEnv(EnvPasswordResetTTL, DefaultPasswordResetTTL)
+	if err != nil {
+		return nil, err
+	}
+	if passwordResetTTL <= 0 {
+		return nil, fmt.Errorf("invalid %s %q, must be positive", EnvPasswordResetTTL, passwordResetTTL)
+	}
+
+	rateLimit := DefaultRateLimit
+	if v, ok := os.LookupEnv(EnvRateLimit); ok {
+		rateLimit, err = ratelimit.ParseLimit(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvRateLimit, err)
+		}
+	}
+
+	routeRateLimits, err := routeRateLimitsEnv(EnvRouteRateLimits)
+	if err != nil {
+		return nil, err
+	}
+
+	authFailureLimit := DefaultAuthFailureLimit
+	if v, ok := os.LookupEnv(EnvAuthFailureLimit); ok {
+		authFailureLimit, err = ratelimit.ParseLimit(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvAuthFailureLimit, err)
+		}
+	}
+
+	loginLockout, err := loginLockoutEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	trustedProxies, err := trustedProxiesEnv(EnvTrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{
-		Port:   port,
-		AppEnv: appEnv,
+		Port:             port,
+		AppEnv:           appEnv,
+		UserDeletePolicy: deletePolicy,
+		PurgeRetention:   purgeRetention,
+		PurgeInterval:    purgeInterval,
+		JWTSecret:        jwtSecret,
+		AccessTokenTTL:   accessTokenTTL,
+		RefreshTokenTTL:  refreshTokenTTL,
+		PublicURL:        publicURL,
+		MailOutboxDir:    mailOutboxDir,
+		MailFrom:         mailFrom,
+		VerificationTTL:  verificationTTL,
+		PasswordResetTTL: passwordResetTTL,
+		RateLimit:        rateLimit,
+		RouteRateLimits:  routeRateLimits,
+		AuthFailureLimit: authFailureLimit,
+		LoginLockout:     loginLockout,
+		TrustedProxies:   trustedProxies,
 	}
 
 	logger.Info("Configuration loaded",
 		zap.String("Port", cfg.Port),
 		zap.String("AppEnv", cfg.AppEnv),
+		zap.String("UserDeletePolicy", string(cfg.UserDeletePolicy)),
+		zap.Duration("PurgeRetention", cfg.PurgeRetention),
+		zap.Duration("PurgeInterval", cfg.PurgeInterval),
+		zap.Duration("AccessTokenTTL", cfg.AccessTokenTTL),
+		zap.Duration("RefreshTokenTTL", cfg.RefreshTokenTTL),
+		zap.String("PublicURL", cfg.PublicURL),
+		zap.String("MailOutboxDir", cfg.MailOutboxDir),
+		zap.String("MailFrom", cfg.MailFrom),
+		zap.Duration("VerificationTTL", cfg.VerificationTTL),
+		zap.Duration("PasswordResetTTL", cfg.PasswordResetTTL),
+		zap.Stringer("RateLimit", cfg.RateLimit),
+		zap.Any("RouteRateLimits", cfg.RouteRateLimits),
+		zap.Stringer("AuthFailureLimit", cfg.AuthFailureLimit),
+		zap.Int("LoginMaxFailures", cfg.LoginLockout.MaxFailures),
+		zap.Int("LoginMaxIPFailures", cfg.LoginLockout.MaxIPFailures),
+		zap.Duration("LoginBackoff", cfg.LoginLockout.Backoff),
+		zap.Duration("LoginMaxBackoff", cfg.LoginLockout.MaxBackoff),
+		zap.Duration("LoginLockoutDuration", cfg.LoginLockout.Duration),
+		zap.Strings("TrustedProxies", cfg.TrustedProxies),
 	)
 
 	return cfg, nil
 }
+
+// durationEnv reads a non-negative Go duration such as "720h" from the environment
+func durationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s %q, must be a duration such as 720h", key, v)
+	}
+	return d, nil
+}
+
+// intEnv reads a non-negative integer from the environment
+func intEnv(key string, fallback int) (int, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q, must be a whole number of at least 0", key, v)
+	}
+	return n, nil
+}
+
+// loginLockoutEnv reads the login lockout thresholds from the environment on top of DefaultLoginLockout
+func loginLockoutEnv() (domain.LoginLockout, error) {
+	lockout := DefaultLoginLockout
+
+	var err error
+	if lockout.MaxFailures, err = intEnv(EnvLoginMaxFailures, lockout.MaxFailures); err != nil {
+		return domain.LoginLockout{}, err
+	}
+	if lockout.MaxIPFailures, err = intEnv(EnvLoginMaxIPFailures, lockout.MaxIPFailures); err != nil {
+		return domain.LoginLockout{}, err
+	}
+	if lockout.Backoff, err = durationEnv(EnvLoginBackoff, lockout.Backoff); err != nil {
+		return domain.LoginLockout{}, err
+	}
+	if lockout.MaxBackoff, err = durationEnv(EnvLoginMaxBackoff, lockout.MaxBackoff); err != nil {
+		return domain.LoginLockout{}, err
+	}
+	if lockout.Duration, err = durationEnv(EnvLoginLockout, lockout.Duration); err != nil {
+		return domain.LoginLockout{}, err
+	}
+
+	if lockout.MaxBackoff < lockout.Backoff {
+		return domain.LoginLockout{}, fmt.Errorf("invalid %s %q, must be at least %s", EnvLoginMaxBackoff, lockout.MaxBackoff, EnvLoginBackoff)
+	}
+	// Failures are forgotten after Duration, without one they would never add up to a backoff or a lockout
+	if lockout.Duration <= 0 {
+		return domain.LoginLockout{}, fmt.Errorf("invalid %s %q, must be positive", EnvLoginLockout, lockout.Duration)
+	}
+	return lockout, nil
+}
+
+// routeRateLimitsEnv reads route limits such as "POST /posts=30/1m,GET /users=0" from the environment on top of
+// DefaultRouteRateLimits, a limit of 0 lifts the limit of the route
+func routeRateLimitsEnv(key string) (map[string]ratelimit.Limit, error) {
+	limits := make(map[string]ratelimit.Limit, len(DefaultRouteRateLimits))
+	for route, limit := range DefaultRouteRateLimits {
+		limits[route] = limit
+	}
+
+	v, ok := os.LookupEnv(key)
+	if !ok || strings.TrimSpace(v) == "" {
+		return limits, nil
+	}
+
+	for _, entry := range strings.Split(v, ",") {
+		route, raw, ok := strings.Cut(entry, "=")
+		method, path, _ := strings.Cut(strings.TrimSpace(route), " ")
+		path = strings.TrimSpace(path)
+		if !ok || method == "" || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid %s entry %q, must be a route and a limit such as POST /posts=30/1m", key, entry)
+		}
+
+		limit, err := ratelimit.ParseLimit(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry %q: %w", key, entry, err)
+		}
+		limits[strings.ToUpper(method)+" "+path] = limit
+	}
+	return limits, nil
+}
+
+// trustedProxiesEnv reads a comma separated list of IPs and CIDR ranges such as "10.0.0.0/8,192.0.2.1" from the
+// environment, no proxy is trusted by default
+func trustedProxiesEnv(key string) ([]string, error) {
+	var proxies []string
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if _, _, err := net.ParseCIDR(entry); err != nil && net.ParseIP(entry) == nil {
+			return nil, fmt.Errorf("invalid %s entry %q, must be an IP or a CIDR range such as 10.0.0.0/8", key, entry)
+		}
+		proxies = append(proxies, entry)
+	}
+	return proxies, nil
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scope limits what an API key may do on behalf of its owner
+type Scope string
+
+const (
+	// ScopeReadOnly lets the key read what its owner can read, without any permission
+	ScopeReadOnly Scope = "read-only"
+	// ScopePostsWrite lets the key write posts as its owner
+	ScopePostsWrite Scope = "posts:write"
+	// ScopeUsersRead lets the key view any user
+	ScopeUsersRead Scope = "users:read"
+)
+
+// ScopePermissions lists the permissions each scope lets through, a key can never do more than its owner's roles allow
+var ScopePermissions = map[Scope][]Permission{
+	ScopeReadOnly:   {},
+	ScopePostsWrite: {PermPostsWrite},
+	ScopeUsersRead:  {PermUsersRead},
+}
+
+// Valid reports whether s is one of the supported scopes
+func (s Scope) Valid() bool {
+	_, ok := ScopePermissions[s]
+	return ok
+}
+
+// Scopes is stored as a comma separated list
+type Scopes []Scope
+
+func (s Scopes) Value() (driver.Value, error) {
+	names := make([]string, len(s))
+	for i, scope := range s {
+		names[i] = string(scope)
+	}
+	return strings.Join(names, ","), nil
+}
+
+func (s *Scopes) Scan(value any) error {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	case nil:
+	default:
+		return fmt.Errorf("cannot scan %T into Scopes", value)
+	}
+
+	*s = Scopes{}
+	for _, name := range strings.Split(raw, ",") {
+		if name != "" {
+			*s = append(*s, Scope(name))
+		}
+	}
+	return nil
+}
+
+func (Scopes) GormDataType() string {
+	return "text"
+}
+
+// allows reports whether any of the scopes lets the permission through
+func (s Scopes) allows(permission Permission) bool {
+	for _, scope := range s {
+		for _, p := range ScopePermissions[scope] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// APIKey authenticates a client without a human user behind it, the client acts as the key's owner
+// within the key's scopes. Only a hash of the key is stored, the key itself is shown once when minted
+type APIKey struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	UserID string `json:"userId"`
+	// Prefix is the start of the key, enough to tell keys apart
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     Scopes     `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
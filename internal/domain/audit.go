@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AuditAction is a change recorded in the audit log, named target.verb
+type AuditAction string
+
+const (
+	AuditUserCreate     AuditAction = "user.create"
+	AuditUserUpdate     AuditAction = "user.update"
+	AuditUserDelete     AuditAction = "user.delete"
+	AuditUserRestore    AuditAction = "user.restore"
+	AuditUserRoleGrant  AuditAction = "user.role.grant"
+	AuditUserRoleRevoke AuditAction = "user.role.revoke"
+	AuditPostCreate     AuditAction = "post.create"
+	AuditPostUpdate     AuditAction = "post.update"
+	AuditPostDelete     AuditAction = "post.delete"
+	AuditPostRestore    AuditAction = "post.restore"
+	AuditCommentCreate  AuditAction = "comment.create"
+	AuditCommentDelete  AuditAction = "comment.delete"
+)
+
+// AuditActions lists every supported AuditAction
+var AuditActions = []AuditAction{
+	AuditUserCreate, AuditUserUpdate, AuditUserDelete, AuditUserRestore, AuditUserRoleGrant, AuditUserRoleRevoke,
+	AuditPostCreate, AuditPostUpdate, AuditPostDelete, AuditPostRestore,
+	AuditCommentCreate, AuditCommentDelete,
+}
+
+// AuditTargetTypes lists the kinds of records audit events are about
+var AuditTargetTypes = []string{"user", "post", "comment"}
+
+// TargetType returns the kind of record the action changes
+func (a AuditAction) TargetType() string {
+	target, _, _ := strings.Cut(string(a), ".")
+	return target
+}
+
+type (
+	// AuditEvent records who changed what, with the state of the record before and after the change. Events are
+	// written in the transaction of the change and are never updated or deleted. ActorID is empty for anonymous
+	// callers, such as users signing up
+	AuditEvent struct {
+		ID            string      `json:"id"`
+		ActorID       string      `json:"actorId"`
+		ActorAPIKeyID string      `json:"actorApiKeyId,omitempty" gorm:"column:actor_api_key_id"`
+		Action        AuditAction `json:"action"`
+		TargetType    string      `json:"targetType"`
+		TargetID      string      `json:"targetId"`
+		Before        Snapshot    `json:"before"`
+		After         Snapshot    `json:"after"`
+		RequestID     string      `json:"requestId"`
+		IPAddress     string      `json:"ipAddress"`
+		CreatedAt     time.Time   `json:"createdAt"`
+	}
+
+	// AuditQuery filters and pages the audit log, zero filter fields are not filtered on
+	AuditQuery struct {
+		ActorID     string
+		Action      AuditAction
+		TargetType  string
+		TargetID    string
+		RequestID   string
+		CreatedFrom time.Time
+		CreatedTo   time.Time
+		// SortDesc lists the most recent events first
+		SortDesc bool
+		Page     PageRequest
+	}
+
+	PaginatedAuditEvents struct {
+		Pagination Pagination   `json:"pagination"`
+		Events     []AuditEvent `json:"events"`
+	}
+
+	// RequestInfo identifies the HTTP request a change is made in
+	RequestInfo struct {
+		ID        string
+		IPAddress string
+	}
+)
+
+// NewAuditEvent describes a change made by the caller carried by ctx, before and after are the states of the target
+// on either side of the change, nil when it did not or no longer exists
+func NewAuditEvent(ctx context.Context, action AuditAction, targetID string, before any, after any) (AuditEvent, error) {
+	event := AuditEvent{
+		Action:     action,
+		TargetType: action.TargetType(),
+		TargetID:   targetID,
+		CreatedAt:  time.Now(),
+	}
+
+	if identity, ok := IdentityFromContext(ctx); ok {
+		event.ActorID = identity.UserID
+		event.ActorAPIKeyID = identity.APIKeyID
+	}
+	if request, ok := RequestFromContext(ctx); ok {
+		event.RequestID = request.ID
+		event.IPAddress = request.IPAddress
+	}
+
+	var err error
+	if event.Before, err = NewSnapshot(before); err != nil {
+		return AuditEvent{}, err
+	}
+	if event.After, err = NewSnapshot(after); err != nil {
+		return AuditEvent{}, err
+	}
+	return event, nil
+}
+
+// Snapshot is the JSON state of a record, stored as text. The empty Snapshot stands for no record and is stored as NULL
+type Snapshot json.RawMessage
+
+// NewSnapshot captures v as it is now, a nil v gives the empty Snapshot
+func NewSnapshot(v any) (Snapshot, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot %T: %w", v, err)
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+	return Snapshot(data), nil
+}
+
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	if len(s) == 0 {
+		return []byte("null"), nil
+	}
+	return s, nil
+}
+
+func (s *Snapshot) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*s = nil
+		return nil
+	}
+	*s = append((*s)[:0], data...)
+	return nil
+}
+
+func (s Snapshot) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	return string(s), nil
+}
+
+func (s *Snapshot) Scan(value any) error {
+	switch v := value.(type) {
+	case string:
+		*s = Snapshot(v)
+	case []byte:
+		*s = append(Snapshot(nil), v...)
+	case nil:
+		*s = nil
+	default:
+		return fmt.Errorf("cannot scan %T into Snapshot", value)
+	}
+	return nil
+}
+
+func (Snapshot) GormDataType() string {
+	return "text"
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "context"
+
+type identityKey struct{}
+
+// ContextWithIdentity returns a copy of ctx carrying the authenticated caller
+func ContextWithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the authenticated caller carried by ctx, if any
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+type requestKey struct{}
+
+// ContextWithRequest returns a copy of ctx carrying the HTTP request it is handling
+func ContextWithRequest(ctx context.Context, request RequestInfo) context.Context {
+	return context.WithValue(ctx, requestKey{}, request)
+}
+
+// RequestFromContext returns the HTTP request carried by ctx, if any
+func RequestFromContext(ctx context.Context) (RequestInfo, bool) {
+	request, ok := ctx.Value(requestKey{}).(RequestInfo)
+	return request, ok
+}
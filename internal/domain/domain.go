@@ -4,17 +4,118 @@ import (
 	"context"
 )
 
-//go:generate mockgen -destination=./mocks/mock.go -package=mocks github.com/victor-nach/postr-backend/internal/domain UserService
+//go:generate mockgen -destination=./mocks/mock.go -package=mocks github.com/victor-nach/postr-backend/internal/domain UserService,PostService,AuthService,APIKeyService,VerificationService,PasswordResetService,AuditService,CommentService,ReactionService,FollowService,TagService,Mailer
 type UserService interface {
-	Create(ctx context.Context, user *User) error
+	// Create stores the user along with a hash of the password they sign in with
+	Create(ctx context.Context, user *User, password string) error
 	Get(ctx context.Context, id string) (*User, error)
-	List(ctx context.Context, pageNumber int, pageSize int) (PaginatedUsers, error)
+	Update(ctx context.Context, id string, update UserUpdate) (*User, error)
+	List(ctx context.Context, query UserQuery) (PaginatedUsers, error)
 	Count(ctx context.Context) (int, error)
+	Delete(ctx context.Context, id string, policy UserDeletePolicy) error
+	Restore(ctx context.Context, id string) (*User, error)
+	ListRoles(ctx context.Context, id string) ([]Role, error)
+	// GrantRole grants the role to the user and returns all of their roles
+	GrantRole(ctx context.Context, id string, role Role) ([]Role, error)
+	// RevokeRole takes the role away from the user and returns the roles they have left
+	RevokeRole(ctx context.Context, id string, role Role) ([]Role, error)
 }
 
-//go:generate mockgen -destination=./mocks/mock.go -package=mocks github.com/victor-nach/postr-backend/internal/domain PostService
 type PostService interface {
 	Create(ctx context.Context, post *Post) error
-	List(ctx context.Context, userId string) ([]Post, error)
+	Get(ctx context.Context, id string) (*Post, error)
+	Update(ctx context.Context, id string, update PostUpdate) (*Post, error)
+	List(ctx context.Context, query PostQuery) (PaginatedPosts, error)
+	Search(ctx context.Context, search PostSearch) (PaginatedPostSearchResults, error)
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) (*Post, error)
+	ListRevisions(ctx context.Context, id string) ([]PostRevision, error)
+	DiffRevisions(ctx context.Context, id string, from int, to int) (PostDiff, error)
+	// Feed pages through the posts of the users userID follows, most recent first
+	Feed(ctx context.Context, userID string, page PageRequest) (PaginatedPosts, error)
+}
+
+type AuthService interface {
+	// Login checks the user's credentials and starts a session, issuing an access and a refresh token
+	Login(ctx context.Context, email string, password string, client SessionClient) (AccessToken, error)
+	// Refresh exchanges a refresh token for new tokens, a refresh token used twice revokes its session
+	Refresh(ctx context.Context, refreshToken string, client SessionClient) (AccessToken, error)
+	// Authenticate returns the identity an access token was issued to, as long as its session is active
+	Authenticate(ctx context.Context, token string) (Identity, error)
+	// ListSessions returns the active sessions of the user, most recently used first
+	ListSessions(ctx context.Context, userID string) ([]Session, error)
+	RevokeSession(ctx context.Context, userID string, sessionID string) error
+	// RevokeSessions revokes every session of the user, logging them out everywhere
+	RevokeSessions(ctx context.Context, userID string) error
+	// Unlock lifts the login lockout of the user's account and forgets its failed logins
+	Unlock(ctx context.Context, userID string) error
+}
+
+type CommentService interface {
+	// Create adds the comment under its post, only users who verified their email can comment
+	Create(ctx context.Context, comment *Comment) error
+	// List pages through the comments of a post, oldest first unless the query asks otherwise
+	List(ctx context.Context, query CommentQuery) (PaginatedComments, error)
+	// Delete removes the comment for good, only its author or a moderator can
+	Delete(ctx context.Context, id string) error
+}
+
+type ReactionService interface {
+	// React adds the reaction to its post, reacting again with the same kind changes nothing. It returns the
+	// reaction counts of the post as they are after the change
+	React(ctx context.Context, reaction Reaction) ([]ReactionCount, error)
+	// Unreact takes the reaction off its post, taking off a reaction that is not there changes nothing
+	Unreact(ctx context.Context, reaction Reaction) ([]ReactionCount, error)
+}
+
+type FollowService interface {
+	// Follow makes follow.FollowerID follow follow.FolloweeID. Following a user again keeps the original follow,
+	// which follow is set to
+	Follow(ctx context.Context, follow *Follow) error
+	// Unfollow stops followerID following followeeID, unfollowing a user who is not followed changes nothing
+	Unfollow(ctx context.Context, followerID string, followeeID string) error
+	// List pages through the followers of a user, or the users they follow
+	List(ctx context.Context, query FollowQuery) (PaginatedFollowUsers, error)
+}
+
+type TagService interface {
+	// List pages through the tags carried by posts that are not deleted, most used first
+	List(ctx context.Context, query TagQuery) (PaginatedTags, error)
+}
+
+type APIKeyService interface {
+	// Create mints a key for apiKey.UserID and returns it, only a hash of it is kept
+	Create(ctx context.Context, apiKey *APIKey) (string, error)
+	List(ctx context.Context) ([]APIKey, error)
+	// Revoke stops the key from authenticating, revoking a revoked key changes nothing
+	Revoke(ctx context.Context, id string) error
+	// Authenticate returns the identity of the key's owner, limited to the key's scopes
+	Authenticate(ctx context.Context, key string) (Identity, error)
+}
+
+type VerificationService interface {
+	// SendVerification emails the user a link confirming they own their email
+	SendVerification(ctx context.Context, userID string) error
+	// VerifyEmail marks the email the token was sent to as verified and returns its user
+	VerifyEmail(ctx context.Context, token string) (*User, error)
+}
+
+type PasswordResetService interface {
+	// ForgotPassword emails a reset token to the user registered with the email, if there is one. It never
+	// tells whether there is, failures are only logged
+	ForgotPassword(ctx context.Context, email string)
+	// ResetPassword sets a new password with a reset token and logs the user out everywhere
+	ResetPassword(ctx context.Context, token string, password string) error
+	// Wait returns once the reset emails asked for so far are sent, ForgotPassword sends them after answering
+	Wait()
+}
+
+type AuditService interface {
+	// List pages through the audit log, oldest events first unless the query asks otherwise
+	List(ctx context.Context, query AuditQuery) (PaginatedAuditEvents, error)
+}
+
+// Mailer sends emails, see the mailer package for the implementations
+type Mailer interface {
+	Send(ctx context.Context, email Email) error
 }
@@ -57,18 +57,192 @@ var (
 		Message: "Invalid input data",
 	}
 
+	ErrConflict = DomainError{
+		Status:  errorStatus,
+		Code:    "APP-409",
+		Message: "Resource already exists",
+	}
+
+	ErrRateLimited = DomainError{
+		Status:  errorStatus,
+		Code:    "APP-429",
+		Message: "Too many requests, try again later",
+	}
+
+	ErrInvalidReference = DomainError{
+		Status:  errorStatus,
+		Code:    "APP-422001",
+		Message: "Referenced resource does not exist",
+	}
+
+	ErrMissingValue = DomainError{
+		Status:  errorStatus,
+		Code:    "APP-422002",
+		Message: "Required value is missing",
+	}
+
+	ErrUnauthenticated = DomainError{
+		Status:  errorStatus,
+		Code:    "AUTH-401001",
+		Message: "Authentication required",
+	}
+
+	ErrInvalidCredentials = DomainError{
+		Status:  errorStatus,
+		Code:    "AUTH-401002",
+		Message: "Invalid email or password",
+	}
+
+	ErrInvalidToken = DomainError{
+		Status:  errorStatus,
+		Code:    "AUTH-401003",
+		Message: "Invalid or expired access token",
+	}
+
+	ErrInvalidAPIKey = DomainError{
+		Status:  errorStatus,
+		Code:    "AUTH-401004",
+		Message: "Invalid or revoked API key",
+	}
+
+	ErrInvalidRefreshToken = DomainError{
+		Status:  errorStatus,
+		Code:    "AUTH-401005",
+		Message: "Invalid, expired or revoked refresh token",
+	}
+
+	ErrRefreshTokenReused = DomainError{
+		Status:  errorStatus,
+		Code:    "AUTH-401006",
+		Message: "Refresh token already used, the session has been revoked",
+	}
+
+	ErrInvalidResetToken = DomainError{
+		Status:  errorStatus,
+		Code:    "AUTH-400001",
+		Message: "Invalid, expired or used password reset token",
+	}
+
+	ErrLoginLocked = DomainError{
+		Status:  errorStatus,
+		Code:    "AUTH-429001",
+		Message: "Too many failed logins, try again later",
+	}
+
+	ErrForbidden = DomainError{
+		Status:  errorStatus,
+		Code:    "AUTH-403001",
+		Message: "You do not have permission to perform this action",
+	}
+
+	ErrLastAdmin = DomainError{
+		Status:  errorStatus,
+		Code:    "AUTH-409001",
+		Message: "The last admin cannot lose the admin role",
+	}
+
+	ErrAPIKeyNotFound = DomainError{
+		Status:  errorStatus,
+		Code:    "KEY-404001",
+		Message: "API key not found",
+	}
+
+	ErrSessionNotFound = DomainError{
+		Status:  errorStatus,
+		Code:    "SES-404001",
+		Message: "Session not found",
+	}
+
 	ErrUserNotFound = DomainError{
 		Status:  errorStatus,
 		Code:    "USR-404001",
 		Message: "User not found",
 	}
 
+	ErrEmailAlreadyRegistered = DomainError{
+		Status:  errorStatus,
+		Code:    "USR-409001",
+		Message: "Email already registered",
+	}
+
+	ErrUserHasPosts = DomainError{
+		Status:  errorStatus,
+		Code:    "USR-409002",
+		Message: "User has existing posts",
+	}
+
+	ErrInvalidVerificationToken = DomainError{
+		Status:  errorStatus,
+		Code:    "USR-400001",
+		Message: "Invalid or expired verification token",
+	}
+
+	ErrCannotFollowSelf = DomainError{
+		Status:  errorStatus,
+		Code:    "USR-400002",
+		Message: "You cannot follow yourself",
+	}
+
+	ErrEmailAlreadyVerified = DomainError{
+		Status:  errorStatus,
+		Code:    "USR-409003",
+		Message: "Email already verified",
+	}
+
+	ErrHandleTaken = DomainError{
+		Status:  errorStatus,
+		Code:    "USR-409004",
+		Message: "Handle already taken",
+	}
+
 	ErrPostNotFound = DomainError{
 		Status:  errorStatus,
 		Code:    "PST-404001",
 		Message: "Post not found",
 	}
 
+	ErrPostForbidden = DomainError{
+		Status:  errorStatus,
+		Code:    "PST-403001",
+		Message: "Only the author or a moderator can change this post",
+	}
+
+	ErrEmailNotVerified = DomainError{
+		Status:  errorStatus,
+		Code:    "PST-403002",
+		Message: "Verify your email before posting",
+	}
+
+	ErrPostAuthorDeleted = DomainError{
+		Status:  errorStatus,
+		Code:    "PST-409001",
+		Message: "Post author is deleted, restore the user first",
+	}
+
+	ErrPostEditConflict = DomainError{
+		Status:  errorStatus,
+		Code:    "PST-409002",
+		Message: "The post was edited meanwhile, reload it and try again",
+	}
+
+	ErrPostRevisionNotFound = DomainError{
+		Status:  errorStatus,
+		Code:    "PST-404002",
+		Message: "Post revision not found",
+	}
+
+	ErrCommentNotFound = DomainError{
+		Status:  errorStatus,
+		Code:    "CMT-404001",
+		Message: "Comment not found",
+	}
+
+	ErrCommentForbidden = DomainError{
+		Status:  errorStatus,
+		Code:    "CMT-403001",
+		Message: "Only the author or a moderator can delete this comment",
+	}
+
 	ErrCreateUser = DomainError{
 		Status:  errorStatus,
 		Code:    "USR-400101",
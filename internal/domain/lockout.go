@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// LoginLockout configures how failed logins slow down and then lock out further logins, per account and per
+// IP address. A zero MaxFailures or MaxIPFailures turns the lockout of accounts or IP addresses off, a zero
+// Backoff turns off the waits between failures
+type LoginLockout struct {
+	// MaxFailures is how many failed logins in a row lock an account out
+	MaxFailures int
+	// MaxIPFailures is how many failed logins in a row from an IP address lock it out, whatever the accounts
+	MaxIPFailures int
+	// Backoff is the wait after the first failure before the next login is let through, it doubles with each
+	// further failure up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Duration is how long a lockout lasts, failures older than that are forgotten
+	Duration time.Duration
+}
+
+// Wait returns how long logins are held back after the given number of failures in a row
+func (l LoginLockout) Wait(failures int) time.Duration {
+	if failures <= 0 || l.Backoff <= 0 {
+		return 0
+	}
+
+	wait := l.Backoff
+	for i := 1; i < failures && wait < l.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, l.MaxBackoff)
+}
+
+// LoginThrottle tracks the recent failed logins of an account or an IP address, named by Key.
+// Lockouts counts every time it was locked out
+type LoginThrottle struct {
+	Key          string `gorm:"primaryKey"`
+	Failures     int
+	LastFailedAt *time.Time
+	LockedUntil  *time.Time
+	Lockouts     int
+}
+
+// LoginLockedError rejects a login while its account or IP address is locked out or waiting out a backoff,
+// it is ErrLoginLocked to errors.Is
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e LoginLockedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrLoginLocked, e.RetryAfter)
+}
+
+func (e LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
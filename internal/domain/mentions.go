@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// MinHandleLength and MaxHandleLength bound the length of a user handle, in characters
+	MinHandleLength = 3
+	MaxHandleLength = 30
+	// MaxPostMentions is the most users a post mentions, later mentions are left as plain text
+	MaxPostMentions = 10
+)
+
+// HandlePattern is what a user handle is made of: letters, digits and underscores
+var HandlePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// mentionPattern finds the @mentions of a post body, by user id or by handle. A mention starts a word, so the
+// @ of an email address is not taken for one
+var mentionPattern = regexp.MustCompile(`(?:^|[^\pL\pN_.@+-])@([0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}|[A-Za-z0-9_]+)`)
+
+// userIDPattern tells the mentions by user id apart from the mentions by handle
+var userIDPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// Mention records that a post @mentions a user
+type Mention struct {
+	PostID    string `gorm:"primaryKey"`
+	UserID    string `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
+
+// Mentions are the handles and user ids @mentioned in a post body, lowercased and each listed once
+type Mentions struct {
+	Handles []string
+	UserIDs []string
+}
+
+// Empty reports whether nobody is mentioned
+func (m Mentions) Empty() bool {
+	return len(m.Handles) == 0 && len(m.UserIDs) == 0
+}
+
+// ParseMentions returns the first MaxPostMentions @handle and @<userId> mentions in body, in the order they first
+// appear. Words too short or too long to be a handle are not mentions
+func ParseMentions(body string) Mentions {
+	mentions := Mentions{Handles: []string{}, UserIDs: []string{}}
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		if len(mentions.Handles)+len(mentions.UserIDs) == MaxPostMentions {
+			break
+		}
+		name := strings.ToLower(match[1])
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		switch {
+		case userIDPattern.MatchString(name):
+			mentions.UserIDs = append(mentions.UserIDs, name)
+		case len(name) >= MinHandleLength && len(name) <= MaxHandleLength:
+			mentions.Handles = append(mentions.Handles, name)
+		}
+	}
+	return mentions
+}
@@ -1,9 +1,42 @@
 package domain
 
 import (
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/pkg/diff"
+)
+
+// DeletedUserID is the id of the tombstone user that posts and comments are reassigned to when their author is deleted
+const DeletedUserID = "00000000-0000-0000-0000-000000000000"
+
+// UserDeletePolicy decides what happens to a user's posts when the user is deleted
+type UserDeletePolicy string
+
+const (
+	// UserDeleteCascade deletes the user's posts along with the user
+	UserDeleteCascade UserDeletePolicy = "cascade"
+	// UserDeleteReassign moves the user's posts and comments to the tombstone user
+	UserDeleteReassign UserDeletePolicy = "reassign"
+	// UserDeleteRestrict refuses to delete a user that still has posts
+	UserDeleteRestrict UserDeletePolicy = "restrict"
 )
 
+// UserDeletePolicies lists every supported UserDeletePolicy
+var UserDeletePolicies = []UserDeletePolicy{UserDeleteCascade, UserDeleteReassign, UserDeleteRestrict}
+
+// Valid reports whether p is one of the supported policies
+func (p UserDeletePolicy) Valid() bool {
+	for _, policy := range UserDeletePolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
 type (
 	User struct {
 		ID        string    `json:"id"`
@@ -15,6 +48,27 @@ type (
 		State     string    `json:"state"`
 		Zipcode   string    `json:"zipcode"`
 		CreatedAt time.Time `json:"createdAt"`
+		// Handle is the name the user is @mentioned by, stored lowercased, nil until they pick one
+		Handle *string `json:"handle"`
+		// PasswordHash is the bcrypt hash of the user's password, empty for users who cannot sign in
+		PasswordHash string `json:"-"`
+		// EmailVerifiedAt is when the user confirmed they own their email, nil until they do
+		EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+		// DeletedAt is set on soft deleted users, which are left out of every read unless asked for
+		DeletedAt gorm.DeletedAt `json:"deletedAt"`
+	}
+
+	// UserUpdate holds the fields of a partial user update, nil fields are left unchanged
+	UserUpdate struct {
+		Firstname *string
+		Lastname  *string
+		Email     *string
+		Street    *string
+		City      *string
+		State     *string
+		Zipcode   *string
+		// Handle replaces the user's handle, an empty handle removes it
+		Handle *string
 	}
 
 	Post struct {
@@ -23,6 +77,192 @@ type (
 		Title     string    `json:"title"`
 		Body      string    `json:"body"`
 		CreatedAt time.Time `json:"createdAt"`
+		UpdatedAt time.Time `json:"updatedAt"`
+		// DeletedAt is set on soft deleted posts, which are left out of every read unless asked for
+		DeletedAt gorm.DeletedAt `json:"deletedAt"`
+		// CommentCount is kept up to date by the database as comments come and go
+		CommentCount int `json:"commentCount" gorm:"->;-:migration"`
+		// Reactions counts the reactions of every kind to the post, they are filled in when the post is read
+		Reactions []ReactionCount `json:"reactions" gorm:"-"`
+		// Tags are the names of the post's tags, given with it or written as #hashtags in its body
+		Tags []string `json:"tags" gorm:"-"`
+	}
+
+	// Comment is a reply under a post
+	Comment struct {
+		ID        string    `json:"id"`
+		PostID    string    `json:"postId"`
+		UserID    string    `json:"userId"`
+		Body      string    `json:"body"`
+		CreatedAt time.Time `json:"createdAt"`
+	}
+
+	// CommentQuery pages the comments of a post
+	CommentQuery struct {
+		PostID string
+		// SortDesc lists the most recent comments first
+		SortDesc bool
+		Page     PageRequest
+	}
+
+	// Follow is a user following another, whose posts then appear in the follower's feed
+	Follow struct {
+		ID         string    `json:"id"`
+		FollowerID string    `json:"followerId"`
+		FolloweeID string    `json:"followeeId"`
+		CreatedAt  time.Time `json:"createdAt"`
+	}
+
+	// FollowUser is a user listed among the followers of a user, or among the users they follow
+	FollowUser struct {
+		ID         string    `json:"id"`
+		Firstname  string    `json:"firstname"`
+		Lastname   string    `json:"lastname"`
+		FollowedAt time.Time `json:"followedAt"`
+		// FollowID is the follow the user is listed for, which pages are keyed on
+		FollowID string `json:"-"`
+	}
+
+	// FollowQuery pages the followers of a user, most recent first
+	FollowQuery struct {
+		UserID string
+		// Following lists the users UserID follows instead of their followers
+		Following bool
+		Page      PageRequest
+	}
+
+	// PostQuery filters, sorts and pages a post listing, zero filter fields are not filtered on
+	PostQuery struct {
+		UserID          string
+		Tag             string
+		MentionedUserID string
+		CreatedFrom     time.Time
+		CreatedTo       time.Time
+		SortBy          PostSortField
+		SortDesc        bool
+		Page            PageRequest
+		// IncludeDeleted lists soft deleted posts along with the others
+		IncludeDeleted bool
+	}
+
+	// PostSearch is a full-text search over post titles and bodies. Words in Query must all match,
+	// "quoted words" match as a phrase and a word ending in * matches as a prefix
+	PostSearch struct {
+		Query string
+		Page  PageRequest
+	}
+
+	// PostSearchResult is a post matching a search. TitleHighlight and Snippet are escaped HTML wrapping the
+	// matched words in <mark> tags, a lower Rank is a better match
+	PostSearchResult struct {
+		Post
+		TitleHighlight string  `json:"titleHighlight"`
+		Snippet        string  `json:"snippet"`
+		Rank           float64 `json:"rank"`
+	}
+
+	// PostUpdate holds the fields of a post edit, nil fields are left unchanged
+	PostUpdate struct {
+		Title *string
+		Body  *string
+		// Tags replaces the tags given with the post, the hashtags of its body are tags whatever they are
+		Tags *[]string
+	}
+
+	// PostRevision is one version of a post, versions are numbered from 1 in the order they were written
+	PostRevision struct {
+		ID        string    `json:"id,omitempty"`
+		PostID    string    `json:"postId"`
+		Version   int       `json:"version"`
+		Title     string    `json:"title"`
+		Body      string    `json:"body"`
+		CreatedAt time.Time `json:"createdAt"`
+	}
+
+	// PostDiff holds the line changes between two versions of a post
+	PostDiff struct {
+		PostID string      `json:"postId"`
+		From   int         `json:"from"`
+		To     int         `json:"to"`
+		Title  []diff.Line `json:"title"`
+		Body   []diff.Line `json:"body"`
+	}
+
+	// UserQuery filters, sorts and pages a user listing, zero filter fields are not filtered on.
+	// Text filters ignore case
+	UserQuery struct {
+		City          string
+		State         string
+		Zipcode       string
+		ZipcodePrefix string
+		EmailDomain   string
+		// NamePrefix matches the start of either the first or the last name
+		NamePrefix  string
+		CreatedFrom time.Time
+		CreatedTo   time.Time
+		SortBy      UserSortField
+		SortDesc    bool
+		Page        PageRequest
+		// IncludeDeleted lists soft deleted users along with the others
+		IncludeDeleted bool
+	}
+
+	// Identity is the authenticated caller of a request. SessionID is set for callers using an access token,
+	// callers using an API key act as the key's owner and have APIKeyID and Scopes set instead
+	Identity struct {
+		UserID    string
+		Roles     []Role
+		SessionID string
+		APIKeyID  string
+		Scopes    Scopes
+	}
+
+	// UserRole grants a role to a user
+	UserRole struct {
+		UserID    string    `json:"userId" gorm:"primaryKey"`
+		Role      Role      `json:"role" gorm:"primaryKey"`
+		CreatedAt time.Time `json:"createdAt"`
+	}
+
+	// AccessToken is a signed token authenticating its bearer until it expires, along with the refresh token
+	// that exchanges for the next one
+	AccessToken struct {
+		AccessToken string    `json:"accessToken"`
+		TokenType   string    `json:"tokenType"`
+		ExpiresIn   int       `json:"expiresIn"`
+		ExpiresAt   time.Time `json:"expiresAt"`
+		// RefreshToken can be used once, POST /auth/refresh returns a new one along with the access token
+		RefreshToken     string    `json:"refreshToken"`
+		RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+	}
+
+	// Session is a login of a user, kept alive by refreshing its tokens until it expires or is revoked.
+	// Only a hash of the current refresh token is stored
+	Session struct {
+		ID               string     `json:"id"`
+		UserID           string     `json:"userId"`
+		RefreshTokenHash string     `json:"-"`
+		UserAgent        string     `json:"userAgent"`
+		IPAddress        string     `json:"ipAddress"`
+		CreatedAt        time.Time  `json:"createdAt"`
+		LastUsedAt       time.Time  `json:"lastUsedAt"`
+		ExpiresAt        time.Time  `json:"expiresAt"`
+		RevokedAt        *time.Time `json:"-"`
+		// Current marks the session of the caller
+		Current bool `json:"current" gorm:"-"`
+	}
+
+	// SessionClient describes the client a session is started or refreshed from
+	SessionClient struct {
+		UserAgent string
+		IPAddress string
+	}
+
+	// Email is a plain text email to a single recipient, sent through a Mailer
+	Email struct {
+		To      string
+		Subject string
+		Body    string
 	}
 
 	PaginatedUsers struct {
@@ -30,9 +270,107 @@ type (
 		Users      []User     `json:"users"`
 	}
 
+	PaginatedPosts struct {
+		Pagination Pagination `json:"pagination"`
+		Posts      []Post     `json:"posts"`
+	}
+
+	PaginatedComments struct {
+		Pagination Pagination `json:"pagination"`
+		Comments   []Comment  `json:"comments"`
+	}
+
+	PaginatedFollowUsers struct {
+		Pagination Pagination   `json:"pagination"`
+		Users      []FollowUser `json:"users"`
+	}
+
+	PaginatedPostSearchResults struct {
+		Pagination Pagination         `json:"pagination"`
+		Results    []PostSearchResult `json:"results"`
+	}
+
+	// PageRequest selects one page of a listing. Listings are paged by page number unless a
+	// cursor is given or Keyset is set, in which case PageSize rows after the cursor are returned
+	PageRequest struct {
+		PageNumber int
+		PageSize   int
+		Cursor     string
+		// Keyset requests cursor pagination for the first page, which has no cursor yet
+		Keyset bool
+	}
+
+	// Pagination describes the returned page. The page counters are only set when paging by page number,
+	// the cursors only when paging by cursor
 	Pagination struct {
-		CurrentPage int `json:"current_page"`
-		TotalPages  int `json:"total_pages"`
-		TotalSize   int `json:"total_size"`
+		CurrentPage int    `json:"current_page"`
+		TotalPages  int    `json:"total_pages"`
+		TotalSize   int    `json:"total_size"`
+		NextCursor  string `json:"next_cursor,omitempty"`
+		PrevCursor  string `json:"prev_cursor,omitempty"`
 	}
 )
+
+// UserSortField is a field user listings can be sorted by
+type UserSortField string
+
+const (
+	UserSortCreatedAt UserSortField = "createdAt"
+	UserSortLastname  UserSortField = "lastname"
+)
+
+// PostSortField is a field post listings can be sorted by
+type PostSortField string
+
+const (
+	PostSortCreatedAt PostSortField = "createdAt"
+	PostSortTitle     PostSortField = "title"
+)
+
+// Apply copies the set fields of the update onto the user
+func (u UserUpdate) Apply(user *User) {
+	if u.Firstname != nil {
+		user.Firstname = *u.Firstname
+	}
+	if u.Lastname != nil {
+		user.Lastname = *u.Lastname
+	}
+	// A new email has to be verified again
+	if u.Email != nil && *u.Email != user.Email {
+		user.Email = *u.Email
+		user.EmailVerifiedAt = nil
+	}
+	if u.Street != nil {
+		user.Street = *u.Street
+	}
+	if u.City != nil {
+		user.City = *u.City
+	}
+	if u.State != nil {
+		user.State = *u.State
+	}
+	if u.Zipcode != nil {
+		user.Zipcode = *u.Zipcode
+	}
+	if u.Handle != nil {
+		user.Handle = nil
+		if handle := strings.ToLower(*u.Handle); handle != "" {
+			user.Handle = &handle
+		}
+	}
+}
+
+// EmailVerified reports whether the user confirmed they own their email
+func (u User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// Apply copies the set fields of the update onto the post
+func (u PostUpdate) Apply(post *Post) {
+	if u.Title != nil {
+		post.Title = *u.Title
+	}
+	if u.Body != nil {
+		post.Body = *u.Body
+	}
+}
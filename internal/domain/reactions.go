@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// ReactionKind is one of the fixed set of reactions users can leave on posts
+type ReactionKind string
+
+const (
+	ReactionLike  ReactionKind = "like"
+	ReactionLove  ReactionKind = "love"
+	ReactionLaugh ReactionKind = "laugh"
+	ReactionWow   ReactionKind = "wow"
+	ReactionSad   ReactionKind = "sad"
+	ReactionAngry ReactionKind = "angry"
+)
+
+// ReactionKinds lists every supported ReactionKind, in the order their counts are shown
+var ReactionKinds = []ReactionKind{ReactionLike, ReactionLove, ReactionLaugh, ReactionWow, ReactionSad, ReactionAngry}
+
+// Valid reports whether k is one of the supported reaction kinds
+func (k ReactionKind) Valid() bool {
+	for _, kind := range ReactionKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Reaction is a user's reaction to a post, a user reacts at most once with each kind to a post
+type Reaction struct {
+	PostID    string       `json:"postId" gorm:"primaryKey"`
+	UserID    string       `json:"userId" gorm:"primaryKey"`
+	Kind      ReactionKind `json:"kind" gorm:"primaryKey"`
+	CreatedAt time.Time    `json:"createdAt"`
+}
+
+// ReactionCount is the number of reactions of one kind to a post, and whether the caller is among them
+type ReactionCount struct {
+	Kind    ReactionKind `json:"kind"`
+	Count   int          `json:"count"`
+	Reacted bool         `json:"reacted"`
+}
+
+// NewReactionCounts lists the count of every reaction kind, zero when the kind is missing from counts
+func NewReactionCounts(counts map[ReactionKind]int, reacted map[ReactionKind]bool) []ReactionCount {
+	reactions := make([]ReactionCount, len(ReactionKinds))
+	for i, kind := range ReactionKinds {
+		reactions[i] = ReactionCount{Kind: kind, Count: counts[kind], Reacted: reacted[kind]}
+	}
+	return reactions
+}
@@ -0,0 +1,88 @@
+package domain
+
+// Role is a set of permissions granted to a user
+type Role string
+
+const (
+	// RoleMember is granted to every user on sign up, revoking it bars the user from posting
+	RoleMember Role = "member"
+	// RoleModerator looks after the content of other users
+	RoleModerator Role = "moderator"
+	// RoleAdmin may do anything, including granting roles
+	RoleAdmin Role = "admin"
+)
+
+// Roles lists every supported Role
+var Roles = []Role{RoleMember, RoleModerator, RoleAdmin}
+
+// Valid reports whether r is one of the supported roles
+func (r Role) Valid() bool {
+	for _, role := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Permission allows an action that is not open to everyone
+type Permission string
+
+const (
+	// PermUsersRead allows viewing any user, their address included
+	PermUsersRead Permission = "users:read"
+	// PermUsersWrite allows editing, deleting and restoring any user
+	PermUsersWrite Permission = "users:write"
+	// PermRolesWrite allows granting and revoking roles
+	PermRolesWrite Permission = "roles:write"
+	// PermPostsWrite allows writing posts, and editing and deleting one's own
+	PermPostsWrite Permission = "posts:write"
+	// PermPostsModerate allows editing, deleting and restoring any post, and listing deleted posts
+	PermPostsModerate Permission = "posts:moderate"
+	// PermAPIKeysWrite allows minting, listing and revoking API keys
+	PermAPIKeysWrite Permission = "apikeys:write"
+	// PermSessionsManage allows listing and revoking the sessions of any user
+	PermSessionsManage Permission = "sessions:manage"
+	// PermAuditRead allows querying the audit log
+	PermAuditRead Permission = "audit:read"
+)
+
+// RolePermissions is the permission matrix, the permissions granted by each role
+var RolePermissions = map[Role][]Permission{
+	RoleMember:    {PermPostsWrite},
+	RoleModerator: {PermPostsWrite, PermPostsModerate, PermUsersRead},
+	RoleAdmin:     {PermPostsWrite, PermPostsModerate, PermUsersRead, PermUsersWrite, PermRolesWrite, PermAPIKeysWrite, PermSessionsManage, PermAuditRead},
+}
+
+// HasRole reports whether the caller was granted the role
+func (i Identity) HasRole(role Role) bool {
+	for _, r := range i.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSelf reports whether the caller is the user, signed in as themselves. API keys only act for their
+// owner within their scopes
+func (i Identity) IsSelf(userID string) bool {
+	return i.APIKeyID == "" && i.UserID == userID
+}
+
+// Can reports whether any of the caller's roles grants the permission, and for API keys whether the key's
+// scopes let it through
+func (i Identity) Can(permission Permission) bool {
+	if i.APIKeyID != "" && !i.Scopes.allows(permission) {
+		return false
+	}
+
+	for _, role := range i.Roles {
+		for _, p := range RolePermissions[role] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
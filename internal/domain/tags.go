@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxTagLength is the longest a tag name can be, in characters
+	MaxTagLength = 50
+	// MaxPostTags is the most tags a post carries, given with it and written as hashtags together
+	MaxPostTags = 10
+)
+
+// hashtagPattern finds the #hashtags of a post body. A hashtag starts a word, so fragments in URLs and HTML
+// entities such as &#39; are not taken for tags
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\pL\pN_#&/])#([\pL\pN_]+)`)
+
+// Tag is a name posts are grouped by, Posts is the number of posts carrying it
+type Tag struct {
+	ID    string `json:"-"`
+	Name  string `json:"name"`
+	Posts int    `json:"posts" gorm:"-:migration;->"`
+}
+
+// PostTag links a post to one of its tags
+type PostTag struct {
+	PostID string `gorm:"primaryKey"`
+	TagID  string `gorm:"primaryKey"`
+}
+
+// TagQuery pages the tags in use, most used first. Prefix, when set, only keeps the tags starting with it
+type TagQuery struct {
+	Prefix string
+	Page   PageRequest
+}
+
+// PaginatedTags is a page of tags
+type PaginatedTags struct {
+	Pagination
+	Tags []Tag `json:"tags"`
+}
+
+// NormalizeTag turns a tag as written, with or without its leading #, into the name it is stored under. It
+// reports false when there is no valid tag left: tags are made of letters, digits and underscores, and are not
+// only digits
+func NormalizeTag(tag string) (string, bool) {
+	name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if name == "" || len([]rune(name)) > MaxTagLength {
+		return "", false
+	}
+
+	digits := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return "", false
+		}
+		if !unicode.IsDigit(r) {
+			digits = false
+		}
+	}
+	if digits {
+		return "", false
+	}
+	return name, true
+}
+
+// ParseHashtags returns the names of the valid #hashtags in body, in the order they first appear
+func ParseHashtags(body string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, match := range hashtagPattern.FindAllStringSubmatch(body, -1) {
+		if name, ok := NormalizeTag(match[1]); ok && !seen[name] {
+			seen[name] = true
+			tags = append(tags, name)
+		}
+	}
+	return tags
+}
+
+// PostTags merges the tags given with a post and the hashtags of its body into the post's tag names, each once
+// and sorted by name as they are read back. Invalid tags are left out, as are the tags past MaxPostTags, the given
+// tags coming before the hashtags in the order they are written
+func PostTags(tags []string, body string) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, tag := range append(append([]string{}, tags...), ParseHashtags(body)...) {
+		if len(names) == MaxPostTags {
+			break
+		}
+		name, ok := NormalizeTag(tag)
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
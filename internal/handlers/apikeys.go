@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-ozzo/ozzo-validation/v4"
+	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type APIKeyHandler struct {
+	service domain.APIKeyService
+	logger  *zap.Logger
+}
+
+func NewAPIKeyHandler(service domain.APIKeyService, logger *zap.Logger) *APIKeyHandler {
+	logger = logger.With(zap.String("package", "handlers"))
+
+	return &APIKeyHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateAPIKey mints an API key, the key is only part of this response
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "CreateAPIKey"))
+
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logr.Error("Error binding JSON", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+
+	// Validate request body
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	identity, ok := domain.IdentityFromContext(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, domain.ErrUnauthenticated)
+		return
+	}
+
+	apiKey := &domain.APIKey{
+		Name:   req.Name,
+		UserID: req.UserID,
+		Scopes: make(domain.Scopes, len(req.Scopes)),
+	}
+	if apiKey.UserID == "" {
+		apiKey.UserID = identity.UserID
+	}
+	for i, scope := range req.Scopes {
+		apiKey.Scopes[i] = domain.Scope(scope)
+	}
+
+	key, err := h.service.Create(c.Request.Context(), apiKey)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("API key created successfully", zap.String("id", apiKey.ID), zap.String("userId", apiKey.UserID))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "API key created successfully",
+		Data:    CreatedAPIKey{APIKey: *apiKey, Key: key},
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListAPIKeys returns every API key, revoked ones included
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "ListAPIKeys"))
+
+	apiKeys, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("API keys listed successfully", zap.Int("count", len(apiKeys)))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "API keys listed successfully",
+		Data:    apiKeys,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RevokeAPIKey stops the API key from authenticating, it stays listed as revoked
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "RevokeAPIKey"))
+
+	id := c.Param("id")
+	if err := h.service.Revoke(c.Request.Context(), id); err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("API key revoked successfully", zap.String("id", id))
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-ozzo/ozzo-validation/v4"
+	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type AuditHandler struct {
+	service domain.AuditService
+	logger  *zap.Logger
+}
+
+func NewAuditHandler(service domain.AuditService, logger *zap.Logger) *AuditHandler {
+	logger = logger.With(zap.String("package", "handlers"))
+
+	return &AuditHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListAuditEvents serves a page of the audit log, most recent events first unless order=asc
+func (h *AuditHandler) ListAuditEvents(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "ListAuditEvents"))
+
+	var req listAuditEventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logr.Error("Error binding query", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	events, err := h.service.List(c.Request.Context(), newAuditQuery(req))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, domain.ErrInternalServer)
+		return
+	}
+
+	logr.Info("Audit events listed successfully", zap.Int("count", len(events.Events)))
+
+	resp := APIResponse{
+		Status:     successStatus,
+		Message:    "Audit events listed successfully",
+		Pagination: &events.Pagination,
+		Data:       events.Events,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func newAuditQuery(req listAuditEventsRequest) domain.AuditQuery {
+	query := domain.AuditQuery{
+		ActorID:    strings.TrimSpace(req.ActorID),
+		Action:     domain.AuditAction(req.Action),
+		TargetType: req.TargetType,
+		TargetID:   strings.TrimSpace(req.TargetID),
+		RequestID:  strings.TrimSpace(req.RequestID),
+		SortDesc:   req.Order != sortAsc,
+	}
+
+	if req.CreatedFrom != "" {
+		query.CreatedFrom, _, _ = parseTimeParam(req.CreatedFrom)
+	}
+	if req.CreatedTo != "" {
+		query.CreatedTo, _ = parseTimeParamEnd(req.CreatedTo)
+	}
+
+	query.Page = req.toPageRequest()
+	return query
+}
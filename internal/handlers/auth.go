@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-ozzo/ozzo-validation/v4"
+	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type AuthHandler struct {
+	service domain.AuthService
+	logger  *zap.Logger
+}
+
+func NewAuthHandler(service domain.AuthService, logger *zap.Logger) *AuthHandler {
+	logger = logger.With(zap.String("package", "handlers"))
+
+	return &AuthHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Login exchanges an email and password for an access token
+func (h *AuthHandler) Login(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "Login"))
+
+	var req loginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logr.Error("Error binding JSON", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+
+	// Validate request body
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	token, err := h.service.Login(c.Request.Context(), req.Email, req.Password, sessionClient(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, err)
+			return
+		}
+
+		var locked domain.LoginLockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(seconds(locked.RetryAfter)))
+			c.JSON(http.StatusTooManyRequests, domain.ErrLoginLocked)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("User logged in successfully")
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "Logged in successfully",
+		Data:    token,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Refresh exchanges a refresh token for a new access token and refresh token
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "Refresh"))
+
+	var req refreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logr.Error("Error binding JSON", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	// Validate request body
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	token, err := h.service.Refresh(c.Request.Context(), req.RefreshToken, sessionClient(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRefreshToken) || errors.Is(err, domain.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("Session refreshed successfully")
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "Session refreshed successfully",
+		Data:    token,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Logout revokes the caller's session, or all of their sessions
+func (h *AuthHandler) Logout(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "Logout"))
+
+	// The body is optional, without one only the current session ends
+	var req logoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logr.Error("Error binding JSON", zap.Error(err))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+			return
+		}
+	}
+
+	identity, ok := domain.IdentityFromContext(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, domain.ErrUnauthenticated)
+		return
+	}
+
+	// API keys have no session to end, they are revoked instead
+	if identity.SessionID == "" {
+		c.JSON(http.StatusForbidden, domain.ErrForbidden)
+		return
+	}
+
+	var err error
+	if req.All {
+		err = h.service.RevokeSessions(c.Request.Context(), identity.UserID)
+	} else {
+		err = h.service.RevokeSession(c.Request.Context(), identity.UserID, identity.SessionID)
+	}
+	if err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("User logged out successfully", zap.String("userId", identity.UserID), zap.Bool("all", req.All))
+	c.Status(http.StatusNoContent)
+}
+
+// ListUserSessions returns the active sessions of the user, marking the caller's own
+func (h *AuthHandler) ListUserSessions(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "ListUserSessions"))
+
+	id := c.Param("id")
+	sessions, err := h.service.ListSessions(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	identity, _ := domain.IdentityFromContext(c.Request.Context())
+	for i := range sessions {
+		sessions[i].Current = identity.SessionID != "" && sessions[i].ID == identity.SessionID
+	}
+
+	logr.Info("User sessions listed successfully", zap.String("id", id), zap.Int("count", len(sessions)))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "User sessions listed successfully",
+		Data:    sessions,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RevokeUserSession ends one session of the user, the access tokens issued to it stop working at once
+func (h *AuthHandler) RevokeUserSession(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "RevokeUserSession"))
+
+	id := c.Param("id")
+	sessionID := c.Param("sessionId")
+	if err := h.service.RevokeSession(c.Request.Context(), id, sessionID); err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("User session revoked successfully", zap.String("id", id), zap.String("sessionId", sessionID))
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeUserSessions ends every session of the user
+func (h *AuthHandler) RevokeUserSessions(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "RevokeUserSessions"))
+
+	id := c.Param("id")
+	if err := h.service.RevokeSessions(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("User sessions revoked successfully", zap.String("id", id))
+	c.Status(http.StatusNoContent)
+}
+
+// UnlockUser lifts the login lockout of the user's account, they can sign in again straight away
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "UnlockUser"))
+
+	id := c.Param("id")
+	if err := h.service.Unlock(c.Request.Context(), id); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("User unlocked successfully", zap.String("id", id))
+	c.Status(http.StatusNoContent)
+}
+
+// sessionClient describes the client making the request, for the sessions it starts or refreshes and the failed
+// logins it counts against. Its IP address is only taken from X-Forwarded-For behind a trusted proxy, so clients
+// cannot pick the address their failed logins count against
+func sessionClient(c *gin.Context) domain.SessionClient {
+	return domain.SessionClient{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
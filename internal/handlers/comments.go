@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type CommentHandler struct {
+	service domain.CommentService
+	logger  *zap.Logger
+}
+
+func NewCommentHandler(service domain.CommentService, logger *zap.Logger) *CommentHandler {
+	logger = logger.With(zap.String("package", "handlers"))
+
+	return &CommentHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateComment adds a comment by the authenticated caller under the post in the id path parameter
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "CreateComment"))
+
+	identity, ok := domain.IdentityFromContext(c.Request.Context())
+	if !ok {
+		logr.Error("Unauthenticated request")
+		c.JSON(http.StatusUnauthorized, domain.ErrUnauthenticated)
+		return
+	}
+
+	var req createCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logr.Error("Error binding JSON", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	req.Body = strings.TrimSpace(req.Body)
+
+	// Validate request body
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	comment := &domain.Comment{
+		ID:        uuid.NewString(),
+		PostID:    c.Param("id"),
+		UserID:    identity.UserID,
+		Body:      req.Body,
+		CreatedAt: time.Now(),
+	}
+
+	if err := h.service.Create(c.Request.Context(), comment); err != nil {
+		if errors.Is(err, domain.ErrPostNotFound) || errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrEmailNotVerified) {
+			c.JSON(http.StatusForbidden, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("Comment created successfully", zap.String("id", comment.ID), zap.String("postId", comment.PostID))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "Comment created successfully",
+		Data:    comment,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListComments serves a page of the comments of the post in the id path parameter, oldest first unless order=desc
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "ListComments"))
+
+	var req listCommentsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logr.Error("Error binding query", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	postID := c.Param("id")
+	comments, err := h.service.List(c.Request.Context(), newCommentQuery(postID, req))
+	if err != nil {
+		if errors.Is(err, domain.ErrPostNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, domain.ErrInternalServer)
+		return
+	}
+
+	logr.Info("Comments listed successfully", zap.String("postId", postID), zap.Int("count", len(comments.Comments)))
+
+	resp := APIResponse{
+		Status:     successStatus,
+		Message:    "Comments listed successfully",
+		Pagination: &comments.Pagination,
+		Data:       comments.Comments,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteComment deletes a comment, only its author or a moderator can
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "DeleteComment"))
+
+	id := c.Param("id")
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		if errors.Is(err, domain.ErrCommentNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrCommentForbidden) {
+			c.JSON(http.StatusForbidden, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrUnauthenticated) {
+			c.JSON(http.StatusUnauthorized, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("Comment deleted successfully", zap.String("id", id))
+	c.Status(http.StatusNoContent)
+}
+
+// newCommentQuery turns a validated listCommentsRequest into a comment query, filling in the defaults.
+// Comments read as a conversation, oldest first, unless asked otherwise
+func newCommentQuery(postID string, req listCommentsRequest) domain.CommentQuery {
+	query := domain.CommentQuery{
+		PostID:   postID,
+		SortDesc: req.Order == sortDesc,
+	}
+
+	query.Page = req.toPageRequest()
+	return query
+}
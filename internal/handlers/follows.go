@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type FollowHandler struct {
+	service domain.FollowService
+	logger  *zap.Logger
+}
+
+func NewFollowHandler(service domain.FollowService, logger *zap.Logger) *FollowHandler {
+	logger = logger.With(zap.String("package", "handlers"))
+
+	return &FollowHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Follow makes the authenticated caller follow the user in the id path parameter
+func (h *FollowHandler) Follow(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "Follow"))
+
+	identity, ok := domain.IdentityFromContext(c.Request.Context())
+	if !ok {
+		logr.Error("Unauthenticated request")
+		c.JSON(http.StatusUnauthorized, domain.ErrUnauthenticated)
+		return
+	}
+
+	follow := &domain.Follow{
+		ID:         uuid.NewString(),
+		FollowerID: identity.UserID,
+		FolloweeID: c.Param("id"),
+		CreatedAt:  time.Now(),
+	}
+
+	if err := h.service.Follow(c.Request.Context(), follow); err != nil {
+		if errors.Is(err, domain.ErrCannotFollowSelf) {
+			c.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("User followed successfully", zap.String("followerId", follow.FollowerID), zap.String("followeeId", follow.FolloweeID))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "User followed successfully",
+		Data:    follow,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Unfollow stops the authenticated caller following the user in the id path parameter
+func (h *FollowHandler) Unfollow(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "Unfollow"))
+
+	identity, ok := domain.IdentityFromContext(c.Request.Context())
+	if !ok {
+		logr.Error("Unauthenticated request")
+		c.JSON(http.StatusUnauthorized, domain.ErrUnauthenticated)
+		return
+	}
+
+	followeeID := c.Param("id")
+	if err := h.service.Unfollow(c.Request.Context(), identity.UserID, followeeID); err != nil {
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("User unfollowed successfully", zap.String("followerId", identity.UserID), zap.String("followeeId", followeeID))
+	c.Status(http.StatusNoContent)
+}
+
+// ListFollowers serves a page of the followers of the user in the id path parameter, most recent first
+func (h *FollowHandler) ListFollowers(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "ListFollowers"))
+
+	h.list(c, logr, false)
+}
+
+// ListFollowing serves a page of the users the user in the id path parameter follows, most recent first
+func (h *FollowHandler) ListFollowing(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "ListFollowing"))
+
+	h.list(c, logr, true)
+}
+
+// list serves a page of the users on the other end of the follows of the user in the id path parameter
+func (h *FollowHandler) list(c *gin.Context, logr *zap.Logger, following bool) {
+	var req listFollowsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logr.Error("Error binding query", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	userID := c.Param("id")
+	users, err := h.service.List(c.Request.Context(), newFollowQuery(userID, following, req))
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, domain.ErrInternalServer)
+		return
+	}
+
+	logr.Info("Follows listed successfully", zap.String("userId", userID), zap.Int("count", len(users.Users)))
+
+	resp := APIResponse{
+		Status:     successStatus,
+		Message:    "Follows listed successfully",
+		Pagination: &users.Pagination,
+		Data:       users.Users,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// newFollowQuery turns a validated listFollowsRequest into a follow query, filling in the defaults
+func newFollowQuery(userID string, following bool, req listFollowsRequest) domain.FollowQuery {
+	query := domain.FollowQuery{
+		UserID:    userID,
+		Following: following,
+	}
+
+	query.Page = req.toPageRequest()
+	return query
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/victor-nach/postr-backend/internal/domain"
 	"github.com/victor-nach/postr-backend/internal/domain/mocks"
+	"github.com/victor-nach/postr-backend/pkg/ratelimit"
 )
 
 func TestPostHandler_CreatePost(t *testing.T) {
@@ -26,10 +27,12 @@ func TestPostHandler_CreatePost(t *testing.T) {
 	logger := zap.NewNop()
 	handler := NewPostHandler(mockPostService, logger)
 
-	reqBody := `{"userId": "b63df572-9bd1-4a4f-9f0d-2a8155a81fde", "title": "Test Title", "body": "Test Body"}`
+	// The author is the caller, a userId in the body is ignored
+	reqBody := `{"userId": "someone-else", "title": "Test Title", "body": "Test Body"}`
 	req, err := http.NewRequest("POST", "/posts", strings.NewReader(reqBody))
 	require.NoError(t, err)
 	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(domain.ContextWithIdentity(req.Context(), domain.Identity{UserID: "b63df572-9bd1-4a4f-9f0d-2a8155a81fde"}))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -62,6 +65,27 @@ func TestPostHandler_CreatePost(t *testing.T) {
 	require.Equal(t, "Test Body", data["body"])
 }
 
+func TestPostHandler_CreatePost_Unauthenticated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostService := mocks.NewMockPostService(ctrl)
+	handler := NewPostHandler(mockPostService, zap.NewNop())
+
+	req, err := http.NewRequest("POST", "/posts", strings.NewReader(`{"title": "Test Title", "body": "Test Body"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.CreatePost(c)
+
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Contains(t, w.Body.String(), "AUTH-401001")
+}
+
 func TestPostHandler_ListPostsByUserID(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -70,13 +94,13 @@ func TestPostHandler_ListPostsByUserID(t *testing.T) {
 	logger := zap.NewNop()
 	handler := NewPostHandler(mockPostService, logger)
 
-	req, err := http.NewRequest("GET", "/posts/b63df572-9bd1-4a4f-9f0d-2a8155a81fde", nil)
+	req, err := http.NewRequest("GET", "/users/b63df572-9bd1-4a4f-9f0d-2a8155a81fde/posts", nil)
 	require.NoError(t, err)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
-	c.Params = gin.Params{gin.Param{Key: "userId", Value: "b63df572-9bd1-4a4f-9f0d-2a8155a81fde"}}
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "b63df572-9bd1-4a4f-9f0d-2a8155a81fde"}}
 
 	expectedPosts := []domain.Post{
 		{
@@ -95,7 +119,15 @@ func TestPostHandler_ListPostsByUserID(t *testing.T) {
 		},
 	}
 
-	mockPostService.EXPECT().List(gomock.Any(), "b63df572-9bd1-4a4f-9f0d-2a8155a81fde").Return(expectedPosts, nil).Times(1)
+	expectedQuery := domain.PostQuery{
+		UserID:   "b63df572-9bd1-4a4f-9f0d-2a8155a81fde",
+		SortBy:   domain.PostSortCreatedAt,
+		SortDesc: true,
+		Page:     domain.PageRequest{PageNumber: 1, PageSize: 10},
+	}
+	pagination := domain.Pagination{CurrentPage: 1, TotalPages: 1, TotalSize: 2}
+	mockPostService.EXPECT().List(gomock.Any(), expectedQuery).
+		Return(domain.PaginatedPosts{Pagination: pagination, Posts: expectedPosts}, nil).Times(1)
 
 	handler.ListPostsByUserID(c)
 
@@ -106,8 +138,2267 @@ func TestPostHandler_ListPostsByUserID(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "success", resp.Status)
 	require.Equal(t, "Posts listed successfully", resp.Message)
+	require.Equal(t, &pagination, resp.Pagination)
 
 	dataSlice, ok := resp.Data.([]interface{})
 	require.True(t, ok, "expected Data to be a slice")
 	require.Len(t, dataSlice, len(expectedPosts))
 }
+
+func TestPostHandler_ListPosts_Query(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostService := mocks.NewMockPostService(ctrl)
+	logger := zap.NewNop()
+	handler := NewPostHandler(mockPostService, logger)
+
+	req, err := http.NewRequest("GET", "/posts?sortBy=title&cursor=abc&limit=5&createdFrom=2025-02-01T00:00:00Z&createdTo=2025-02-10", nil)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	createdTo := time.Date(2025, 2, 11, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)
+	expectedQuery := domain.PostQuery{
+		CreatedFrom: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		CreatedTo:   createdTo,
+		SortBy:      domain.PostSortTitle,
+		Page:        domain.PageRequest{Cursor: "abc", PageSize: 5, Keyset: true},
+	}
+	pagination := domain.Pagination{NextCursor: "def", PrevCursor: "abc"}
+	mockPostService.EXPECT().List(gomock.Any(), expectedQuery).
+		Return(domain.PaginatedPosts{Pagination: pagination, Posts: []domain.Post{}}, nil).Times(1)
+
+	handler.ListPosts(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, &pagination, resp.Pagination)
+}
+
+func TestPostHandler_ListPosts_InvalidQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostService := mocks.NewMockPostService(ctrl)
+	logger := zap.NewNop()
+	handler := NewPostHandler(mockPostService, logger)
+
+	req, err := http.NewRequest("GET", "/posts?sortBy=body&order=up&pageSize=500&createdFrom=yesterday&tag=2025", nil)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.ListPosts(c)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp domain.DomainError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, domain.ErrInvalidInput.Code, resp.Code)
+	for _, field := range []string{"sortBy", "order", "pageSize", "createdFrom", "tag"} {
+		require.Contains(t, resp.FieldErrors, field)
+	}
+}
+
+func TestUserHandler_CreateUser_ConstraintErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"email taken", domain.ErrEmailAlreadyRegistered, http.StatusConflict, "USR-409001"},
+		{"handle taken", domain.ErrHandleTaken, http.StatusConflict, "USR-409004"},
+		{"conflict", domain.ErrConflict, http.StatusConflict, "APP-409"},
+		{"invalid reference", domain.ErrInvalidReference, http.StatusUnprocessableEntity, "APP-422001"},
+		{"missing value", domain.ErrMissingValue, http.StatusUnprocessableEntity, "APP-422002"},
+		{"internal", domain.ErrInternalServer, http.StatusInternalServerError, "APP-500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUserService := mocks.NewMockUserService(ctrl)
+			handler := NewUserHandler(mockUserService, domain.UserDeleteRestrict, zap.NewNop())
+
+			reqBody := `{"firstname": "Jane", "lastname": "Doe", "email": "jane@example.com", "password": "correct horse", "street": "1 Main St", "city": "Springfield", "state": "IL", "zipcode": "62701"}`
+			req, err := http.NewRequest("POST", "/users", strings.NewReader(reqBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			mockUserService.EXPECT().Create(gomock.Any(), gomock.Any(), "correct horse").Return(tt.err)
+
+			handler.CreateUser(c)
+
+			require.Equal(t, tt.status, w.Code)
+
+			var resp domain.DomainError
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, "error", resp.Status)
+			require.Equal(t, tt.code, resp.Code)
+		})
+	}
+}
+
+func TestUserHandler_UpdateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	logger := zap.NewNop()
+	handler := NewUserHandler(mockUserService, domain.UserDeleteRestrict, logger)
+
+	reqBody := `{"city": "Baltimore", "zipcode": "21201"}`
+	req, err := http.NewRequest("PATCH", "/users/b63df572-9bd1-4a4f-9f0d-2a8155a81fde", strings.NewReader(reqBody))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "b63df572-9bd1-4a4f-9f0d-2a8155a81fde"}}
+
+	mockUserService.EXPECT().Update(gomock.Any(), "b63df572-9bd1-4a4f-9f0d-2a8155a81fde", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, id string, update domain.UserUpdate) (*domain.User, error) {
+			require.Equal(t, "Baltimore", *update.City)
+			require.Equal(t, "21201", *update.Zipcode)
+			require.Nil(t, update.Firstname)
+			require.Nil(t, update.Email)
+			return &domain.User{ID: id, Firstname: "Dana", City: *update.City, Zipcode: *update.Zipcode}, nil
+		}).Times(1)
+
+	handler.UpdateUser(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp APIResponse
+	err = json.Unmarshal(w.Body.Bytes(), &resp)
+	require.NoError(t, err)
+	require.Equal(t, "success", resp.Status)
+
+	data, ok := resp.Data.(map[string]interface{})
+	require.True(t, ok, "expected Data to be a map")
+	require.Equal(t, "Baltimore", data["city"])
+	require.Equal(t, "Dana", data["firstname"])
+}
+
+func TestUserHandler_UpdateUser_ValidationErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	logger := zap.NewNop()
+	handler := NewUserHandler(mockUserService, domain.UserDeleteRestrict, logger)
+
+	reqBody := `{"email": "not-an-email", "firstname": "A", "handle": "no spaces"}`
+	req, err := http.NewRequest("PATCH", "/users/b63df572-9bd1-4a4f-9f0d-2a8155a81fde", strings.NewReader(reqBody))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "b63df572-9bd1-4a4f-9f0d-2a8155a81fde"}}
+
+	handler.UpdateUser(c)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp domain.DomainError
+	err = json.Unmarshal(w.Body.Bytes(), &resp)
+	require.NoError(t, err)
+	require.Equal(t, domain.ErrInvalidInput.Code, resp.Code)
+	require.Contains(t, resp.FieldErrors, "email")
+	require.Contains(t, resp.FieldErrors, "firstname")
+	require.Contains(t, resp.FieldErrors, "handle")
+	require.NotContains(t, resp.FieldErrors, "lastname")
+}
+
+func TestUserHandler_UpdateUser_Handle(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		// want is the handle the user is left with, none when empty
+		want string
+	}{
+		{"set", `{"handle": "Jane_Doe"}`, "jane_doe"},
+		{"remove", `{"handle": ""}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUserService := mocks.NewMockUserService(ctrl)
+			handler := NewUserHandler(mockUserService, domain.UserDeleteRestrict, zap.NewNop())
+
+			req, err := http.NewRequest("PATCH", "/users/b63df572-9bd1-4a4f-9f0d-2a8155a81fde", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Params = gin.Params{gin.Param{Key: "id", Value: "b63df572-9bd1-4a4f-9f0d-2a8155a81fde"}}
+
+			mockUserService.EXPECT().Update(gomock.Any(), "b63df572-9bd1-4a4f-9f0d-2a8155a81fde", gomock.Any()).
+				DoAndReturn(func(ctx context.Context, id string, update domain.UserUpdate) (*domain.User, error) {
+					old := "old_handle"
+					user := &domain.User{ID: id, Handle: &old}
+					update.Apply(user)
+					if tt.want == "" {
+						require.Nil(t, user.Handle)
+					} else {
+						require.Equal(t, tt.want, *user.Handle)
+					}
+					return user, nil
+				})
+
+			handler.UpdateUser(c)
+
+			require.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+}
+
+func TestUserHandler_DeleteUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	logger := zap.NewNop()
+	handler := NewUserHandler(mockUserService, domain.UserDeleteRestrict, logger)
+
+	newContext := func(target string) (*gin.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest("DELETE", target, nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "b63df572-9bd1-4a4f-9f0d-2a8155a81fde"}}
+		return c, w
+	}
+
+	// Falls back to the configured policy
+	c, w := newContext("/users/b63df572-9bd1-4a4f-9f0d-2a8155a81fde")
+	mockUserService.EXPECT().Delete(gomock.Any(), "b63df572-9bd1-4a4f-9f0d-2a8155a81fde", domain.UserDeleteRestrict).
+		Return(domain.ErrUserHasPosts).Times(1)
+	handler.DeleteUser(c)
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	// Uses the policy from the request
+	c, w = newContext("/users/b63df572-9bd1-4a4f-9f0d-2a8155a81fde?policy=cascade")
+	mockUserService.EXPECT().Delete(gomock.Any(), "b63df572-9bd1-4a4f-9f0d-2a8155a81fde", domain.UserDeleteCascade).
+		Return(nil).Times(1)
+	handler.DeleteUser(c)
+	require.Equal(t, http.StatusNoContent, c.Writer.Status())
+
+	// Rejects unknown policies
+	c, w = newContext("/users/b63df572-9bd1-4a4f-9f0d-2a8155a81fde?policy=shred")
+	handler.DeleteUser(c)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp domain.DomainError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Contains(t, resp.FieldErrors, "policy")
+}
+
+func TestUserHandler_ListUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	logger := zap.NewNop()
+	handler := NewUserHandler(mockUserService, domain.UserDeleteRestrict, logger)
+
+	newContext := func(target string) (*gin.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest("GET", target, nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		return c, w
+	}
+
+	// Page numbers default when left out
+	c, w := newContext("/users?pageSize=2")
+	mockUserService.EXPECT().List(gomock.Any(), domain.UserQuery{SortBy: domain.UserSortCreatedAt, Page: domain.PageRequest{PageNumber: 1, PageSize: 2}}).
+		Return(domain.PaginatedUsers{Pagination: domain.Pagination{CurrentPage: 1, TotalPages: 1, TotalSize: 1}}, nil).Times(1)
+	handler.ListUsers(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Pages out of bounds never reach the service
+	for _, query := range []string{"pageNumber=abc", "pageNumber=-1", "pageSize=-5", "pageSize=1000", "pageNumber=2&cursor=abc"} {
+		c, w = newContext("/users?" + query)
+		handler.ListUsers(c)
+		require.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	// A cursor switches to keyset pagination
+	c, w = newContext("/users?cursor=abc&limit=5")
+	pagination := domain.Pagination{NextCursor: "def", PrevCursor: "ghi"}
+	mockUserService.EXPECT().List(gomock.Any(), domain.UserQuery{SortBy: domain.UserSortCreatedAt, Page: domain.PageRequest{Cursor: "abc", PageSize: 5, Keyset: true}}).
+		Return(domain.PaginatedUsers{Pagination: pagination}, nil).Times(1)
+	handler.ListUsers(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, &pagination, resp.Pagination)
+
+	// Stale cursors are the client's to fix
+	c, w = newContext("/users?cursor=stale")
+	mockUserService.EXPECT().List(gomock.Any(), domain.UserQuery{SortBy: domain.UserSortCreatedAt, Page: domain.PageRequest{Cursor: "stale", PageSize: 10, Keyset: true}}).
+		Return(domain.PaginatedUsers{}, domain.ErrInvalidInput).Times(1)
+	handler.ListUsers(c)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Limits are capped
+	c, w = newContext("/users?limit=1000")
+	handler.ListUsers(c)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUserHandler_ListUsers_Filters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	logger := zap.NewNop()
+	handler := NewUserHandler(mockUserService, domain.UserDeleteRestrict, logger)
+
+	newContext := func(target string) (*gin.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest("GET", target, nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		return c, w
+	}
+
+	c, w := newContext("/users?city=Chicago&state[eq]=IL&zipcode[prefix]=606&emailDomain=@acme.corp&name=Em" +
+		"&createdAt[gte]=2025-02-01T00:00:00Z&createdAt[lte]=2025-02-10&sortBy=lastname&order=desc")
+	expectedQuery := domain.UserQuery{
+		City:          "Chicago",
+		State:         "IL",
+		ZipcodePrefix: "606",
+		EmailDomain:   "acme.corp",
+		NamePrefix:    "Em",
+		CreatedFrom:   time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		CreatedTo:     time.Date(2025, 2, 11, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond),
+		SortBy:        domain.UserSortLastname,
+		SortDesc:      true,
+		Page:          domain.PageRequest{PageNumber: 1, PageSize: 10},
+	}
+	mockUserService.EXPECT().List(gomock.Any(), expectedQuery).Return(domain.PaginatedUsers{}, nil).Times(1)
+	handler.ListUsers(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Unknown fields and operators are rejected
+	c, w = newContext("/users?country=US&city[prefix]=Chi&createdAt=2025-02-01&name[eq&sortBy=email")
+	handler.ListUsers(c)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp domain.DomainError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, domain.ErrInvalidInput.Code, resp.Code)
+	require.Contains(t, resp.FieldErrors, "sortBy")
+
+	c, w = newContext("/users?country=US&city[prefix]=Chi&createdAt=2025-02-01&name[eq&createdAt[lte]=soon")
+	handler.ListUsers(c)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	resp = domain.DomainError{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	for _, param := range []string{"country", "city[prefix]", "createdAt", "name[eq", "createdAt[lte]"} {
+		require.Contains(t, resp.FieldErrors, param)
+	}
+}
+
+func TestPostHandler_SearchPosts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostService := mocks.NewMockPostService(ctrl)
+	logger := zap.NewNop()
+	handler := NewPostHandler(mockPostService, logger)
+
+	newContext := func(target string) (*gin.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest("GET", target, nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		return c, w
+	}
+
+	c, w := newContext(`/posts/search?q=%22day+at%22+bea*&pageNumber=2`)
+	pagination := domain.Pagination{CurrentPage: 2, TotalPages: 2, TotalSize: 11}
+	mockPostService.EXPECT().Search(gomock.Any(), domain.PostSearch{Query: `"day at" bea*`, Page: domain.PageRequest{PageNumber: 2, PageSize: 10}}).
+		Return(domain.PaginatedPostSearchResults{
+			Pagination: pagination,
+			Results:    []domain.PostSearchResult{{Post: domain.Post{ID: "post1"}, Snippet: "A <mark>day at</mark> the <mark>beach</mark>"}},
+		}, nil).Times(1)
+	handler.SearchPosts(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, &pagination, resp.Pagination)
+
+	dataSlice, ok := resp.Data.([]interface{})
+	require.True(t, ok, "expected Data to be a slice")
+	require.Len(t, dataSlice, 1)
+	result := dataSlice[0].(map[string]interface{})
+	require.Equal(t, "post1", result["id"])
+	require.Equal(t, "A <mark>day at</mark> the <mark>beach</mark>", result["snippet"])
+
+	// A search is required
+	c, w = newContext("/posts/search?q=+")
+	handler.SearchPosts(c)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var errResp domain.DomainError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResp))
+	require.Contains(t, errResp.FieldErrors, "q")
+}
+
+func TestUserHandler_RestoreUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	logger := zap.NewNop()
+	handler := NewUserHandler(mockUserService, domain.UserDeleteRestrict, logger)
+
+	newContext := func() (*gin.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest("POST", "/users/b63df572-9bd1-4a4f-9f0d-2a8155a81fde/restore", nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "b63df572-9bd1-4a4f-9f0d-2a8155a81fde"}}
+		return c, w
+	}
+
+	c, w := newContext()
+	mockUserService.EXPECT().Restore(gomock.Any(), "b63df572-9bd1-4a4f-9f0d-2a8155a81fde").
+		Return(&domain.User{ID: "b63df572-9bd1-4a4f-9f0d-2a8155a81fde"}, nil).Times(1)
+	handler.RestoreUser(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	c, w = newContext()
+	mockUserService.EXPECT().Restore(gomock.Any(), "b63df572-9bd1-4a4f-9f0d-2a8155a81fde").
+		Return(nil, domain.ErrUserNotFound).Times(1)
+	handler.RestoreUser(c)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPostHandler_RestorePost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostService := mocks.NewMockPostService(ctrl)
+	logger := zap.NewNop()
+	handler := NewPostHandler(mockPostService, logger)
+
+	newContext := func() (*gin.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest("POST", "/posts/post1/restore", nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "post1"}}
+		return c, w
+	}
+
+	c, w := newContext()
+	mockPostService.EXPECT().Restore(gomock.Any(), "post1").Return(&domain.Post{ID: "post1"}, nil).Times(1)
+	handler.RestorePost(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	c, w = newContext()
+	mockPostService.EXPECT().Restore(gomock.Any(), "post1").Return(nil, domain.ErrPostNotFound).Times(1)
+	handler.RestorePost(c)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	c, w = newContext()
+	mockPostService.EXPECT().Restore(gomock.Any(), "post1").Return(nil, domain.ErrPostAuthorDeleted).Times(1)
+	handler.RestorePost(c)
+	require.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestPostHandler_UpdatePost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostService := mocks.NewMockPostService(ctrl)
+	logger := zap.NewNop()
+	handler := NewPostHandler(mockPostService, logger)
+
+	reqBody := `{"title": "  New Title  "}`
+	req, err := http.NewRequest("PATCH", "/posts/post1", strings.NewReader(reqBody))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "post1"}}
+
+	mockPostService.EXPECT().Update(gomock.Any(), "post1", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, id string, update domain.PostUpdate) (*domain.Post, error) {
+			require.Equal(t, "New Title", *update.Title)
+			require.Nil(t, update.Body)
+			return &domain.Post{ID: id, Title: *update.Title, Body: "Body"}, nil
+		}).Times(1)
+
+	handler.UpdatePost(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp APIResponse
+	err = json.Unmarshal(w.Body.Bytes(), &resp)
+	require.NoError(t, err)
+	require.Equal(t, "Post updated successfully", resp.Message)
+
+	data, ok := resp.Data.(map[string]interface{})
+	require.True(t, ok, "expected Data to be a map")
+	require.Equal(t, "New Title", data["title"])
+}
+
+func TestPostHandler_UpdatePost_EditConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostService := mocks.NewMockPostService(ctrl)
+	handler := NewPostHandler(mockPostService, zap.NewNop())
+
+	req, err := http.NewRequest("PATCH", "/posts/post1", strings.NewReader(`{"title": "New Title"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "post1"}}
+
+	mockPostService.EXPECT().Update(gomock.Any(), "post1", gomock.Any()).Return(nil, domain.ErrPostEditConflict)
+
+	handler.UpdatePost(c)
+
+	require.Equal(t, http.StatusConflict, w.Code)
+	require.Contains(t, w.Body.String(), "PST-409002")
+}
+
+func TestPostHandler_DeletePost_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"deleted", nil, http.StatusNoContent},
+		{"not found", domain.ErrPostNotFound, http.StatusNotFound},
+		{"unauthenticated", domain.ErrUnauthenticated, http.StatusUnauthorized},
+		{"not the author", domain.ErrPostForbidden, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPostService := mocks.NewMockPostService(ctrl)
+			handler := NewPostHandler(mockPostService, zap.NewNop())
+
+			req, err := http.NewRequest("DELETE", "/posts/post1", nil)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.DELETE("/posts/:id", handler.DeletePost)
+
+			mockPostService.EXPECT().Delete(gomock.Any(), "post1").Return(tt.err)
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestPostHandler_GetPost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostService := mocks.NewMockPostService(ctrl)
+	logger := zap.NewNop()
+	handler := NewPostHandler(mockPostService, logger)
+
+	req, err := http.NewRequest("GET", "/posts/post1", nil)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "post1"}}
+
+	mockPostService.EXPECT().Get(gomock.Any(), "post1").
+		Return(&domain.Post{ID: "post1", UserID: "12345", Title: "Title 1", Body: "Body 1"}, nil).Times(1)
+
+	handler.GetPost(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("Deprecation"))
+
+	var resp APIResponse
+	err = json.Unmarshal(w.Body.Bytes(), &resp)
+	require.NoError(t, err)
+
+	data, ok := resp.Data.(map[string]interface{})
+	require.True(t, ok, "expected Data to be a map")
+	require.Equal(t, "post1", data["id"])
+}
+
+func TestPostHandler_GetPost_LegacyUserID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostService := mocks.NewMockPostService(ctrl)
+	logger := zap.NewNop()
+	handler := NewPostHandler(mockPostService, logger)
+
+	req, err := http.NewRequest("GET", "/posts/12345", nil)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "12345"}}
+
+	mockPostService.EXPECT().Get(gomock.Any(), "12345").Return(nil, domain.ErrPostNotFound).Times(1)
+	mockPostService.EXPECT().List(gomock.Any(), domain.PostQuery{UserID: "12345", SortBy: domain.PostSortCreatedAt, SortDesc: true, Page: domain.PageRequest{PageSize: maxPageSize, Keyset: true}}).
+		Return(domain.PaginatedPosts{Posts: []domain.Post{{ID: "post1", UserID: "12345"}}}, nil).Times(1)
+
+	handler.GetPost(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "true", w.Header().Get("Deprecation"))
+	require.Contains(t, w.Header().Get("Link"), "/users/12345/posts")
+
+	var resp APIResponse
+	err = json.Unmarshal(w.Body.Bytes(), &resp)
+	require.NoError(t, err)
+
+	dataSlice, ok := resp.Data.([]interface{})
+	require.True(t, ok, "expected Data to be a slice")
+	require.Len(t, dataSlice, 1)
+}
+
+func TestPostHandler_GetPost_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostService := mocks.NewMockPostService(ctrl)
+	logger := zap.NewNop()
+	handler := NewPostHandler(mockPostService, logger)
+
+	req, err := http.NewRequest("GET", "/posts/missing", nil)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "missing"}}
+
+	mockPostService.EXPECT().Get(gomock.Any(), "missing").Return(nil, domain.ErrPostNotFound).Times(1)
+	mockPostService.EXPECT().List(gomock.Any(), domain.PostQuery{UserID: "missing", SortBy: domain.PostSortCreatedAt, SortDesc: true, Page: domain.PageRequest{PageSize: maxPageSize, Keyset: true}}).
+		Return(domain.PaginatedPosts{}, domain.ErrUserNotFound).Times(1)
+
+	handler.GetPost(c)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	var resp domain.DomainError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, domain.ErrPostNotFound.Code, resp.Code)
+}
+
+func TestAuthHandler_Login(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		token   domain.AccessToken
+		err     error
+		status  int
+		calls   int
+		message string
+	}{
+		{
+			name:   "success",
+			body:   `{"email": " jane@example.com ", "password": "correct horse"}`,
+			token:  domain.AccessToken{AccessToken: "signed.token.value", TokenType: "Bearer", ExpiresIn: 900},
+			status: http.StatusOK,
+			calls:  1,
+		},
+		{
+			name:   "invalid credentials",
+			body:   `{"email": "jane@example.com", "password": "correct horse"}`,
+			err:    domain.ErrInvalidCredentials,
+			status: http.StatusUnauthorized,
+			calls:  1,
+		},
+		{
+			name:   "locked out",
+			body:   `{"email": "jane@example.com", "password": "correct horse"}`,
+			err:    domain.LoginLockedError{RetryAfter: 1500 * time.Millisecond},
+			status: http.StatusTooManyRequests,
+			calls:  1,
+		},
+		{
+			name:   "missing password",
+			body:   `{"email": "jane@example.com"}`,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuthService := mocks.NewMockAuthService(ctrl)
+			handler := NewAuthHandler(mockAuthService, zap.NewNop())
+
+			req, err := http.NewRequest("POST", "/auth/login", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			mockAuthService.EXPECT().Login(gomock.Any(), "jane@example.com", "correct horse", gomock.Any()).Return(tt.token, tt.err).Times(tt.calls)
+
+			handler.Login(c)
+
+			require.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				var resp APIResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				data, ok := resp.Data.(map[string]interface{})
+				require.True(t, ok, "expected Data to be a map")
+				require.Equal(t, "signed.token.value", data["accessToken"])
+				require.Equal(t, "Bearer", data["tokenType"])
+			}
+			if tt.status == http.StatusTooManyRequests {
+				require.Equal(t, "2", w.Header().Get("Retry-After"))
+				require.Contains(t, w.Body.String(), domain.ErrLoginLocked.Code)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		authErr  error
+		calls    int
+		keyErr   error
+		keyCalls int
+		status   int
+		code     string
+		identity string
+	}{
+		{name: "anonymous", status: http.StatusOK},
+		{name: "valid token", header: "Bearer good", calls: 1, status: http.StatusOK, identity: "u1"},
+		{name: "lowercase scheme", header: "bearer good", calls: 1, status: http.StatusOK, identity: "u1"},
+		{name: "invalid token", header: "Bearer bad", authErr: domain.ErrInvalidToken, calls: 1, status: http.StatusUnauthorized, code: "AUTH-401003"},
+		{name: "other scheme", header: "Basic dXNlcjpwYXNz", status: http.StatusUnauthorized, code: "AUTH-401003"},
+		{name: "no token", header: "Bearer", status: http.StatusUnauthorized, code: "AUTH-401003"},
+		{name: "valid API key", header: "ApiKey postr_good", keyCalls: 1, status: http.StatusOK, identity: "k1"},
+		{name: "invalid API key", header: "ApiKey postr_bad", keyErr: domain.ErrInvalidAPIKey, keyCalls: 1, status: http.StatusUnauthorized, code: "AUTH-401004"},
+		{name: "no API key", header: "ApiKey ", status: http.StatusUnauthorized, code: "AUTH-401003"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuthService := mocks.NewMockAuthService(ctrl)
+			mockAuthService.EXPECT().Authenticate(gomock.Any(), "good").Return(domain.Identity{UserID: "u1"}, nil).MaxTimes(tt.calls)
+			mockAuthService.EXPECT().Authenticate(gomock.Any(), "bad").Return(domain.Identity{}, tt.authErr).MaxTimes(tt.calls)
+			mockAPIKeyService := mocks.NewMockAPIKeyService(ctrl)
+			mockAPIKeyService.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Return(domain.Identity{UserID: "k1", APIKeyID: "key-1"}, tt.keyErr).Times(tt.keyCalls)
+
+			router := gin.New()
+			router.Use(Authenticate(mockAuthService, mockAPIKeyService, zap.NewNop()))
+			router.GET("/whoami", func(c *gin.Context) {
+				identity, _ := domain.IdentityFromContext(c.Request.Context())
+				c.String(http.StatusOK, identity.UserID)
+			})
+
+			req, err := http.NewRequest("GET", "/whoami", nil)
+			require.NoError(t, err)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				require.Equal(t, tt.identity, w.Body.String())
+			} else {
+				require.Contains(t, w.Body.String(), tt.code)
+				require.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestRequireAuth(t *testing.T) {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID := c.GetHeader("X-Test-User"); userID != "" {
+			c.Request = c.Request.WithContext(domain.ContextWithIdentity(c.Request.Context(), domain.Identity{UserID: userID}))
+		}
+	})
+	router.POST("/posts", RequireAuth(), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	req, err := http.NewRequest("POST", "/posts", nil)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Contains(t, w.Body.String(), "AUTH-401001")
+
+	req.Header.Set("X-Test-User", "u1")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestRequirePermission(t *testing.T) {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID := c.GetHeader("X-Test-User"); userID != "" {
+			identity := domain.Identity{UserID: userID, Roles: []domain.Role{domain.Role(c.GetHeader("X-Test-Role"))}}
+			if scope := c.GetHeader("X-Test-Scope"); scope != "" {
+				identity.APIKeyID = "key-1"
+				identity.Scopes = domain.Scopes{domain.Scope(scope)}
+			}
+			c.Request = c.Request.WithContext(domain.ContextWithIdentity(c.Request.Context(), identity))
+		}
+	})
+	router.GET("/users", RequirePermission(domain.PermUsersRead), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/users/:id", RequireSelfOrPermission("id", domain.PermUsersRead), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		user   string
+		role   domain.Role
+		scope  domain.Scope
+		status int
+	}{
+		{"anonymous", "/users", "", "", "", http.StatusUnauthorized},
+		{"member", "/users", "u1", domain.RoleMember, "", http.StatusForbidden},
+		{"moderator", "/users", "u1", domain.RoleModerator, "", http.StatusOK},
+		{"admin", "/users", "u1", domain.RoleAdmin, "", http.StatusOK},
+		{"anonymous on a user", "/users/u1", "", "", "", http.StatusUnauthorized},
+		{"member on self", "/users/u1", "u1", domain.RoleMember, "", http.StatusOK},
+		{"member on another user", "/users/u2", "u1", domain.RoleMember, "", http.StatusForbidden},
+		{"moderator on another user", "/users/u2", "u1", domain.RoleModerator, "", http.StatusOK},
+		{"admin key", "/users", "u1", domain.RoleAdmin, domain.ScopeUsersRead, http.StatusOK},
+		{"read-only admin key", "/users", "u1", domain.RoleAdmin, domain.ScopeReadOnly, http.StatusForbidden},
+		{"member key", "/users", "u1", domain.RoleMember, domain.ScopeUsersRead, http.StatusForbidden},
+		{"member key on its owner", "/users/u1", "u1", domain.RoleMember, domain.ScopeReadOnly, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tt.path, nil)
+			require.NoError(t, err)
+			req.Header.Set("X-Test-User", tt.user)
+			req.Header.Set("X-Test-Role", string(tt.role))
+			req.Header.Set("X-Test-Scope", string(tt.scope))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+			switch tt.status {
+			case http.StatusUnauthorized:
+				require.Contains(t, w.Body.String(), "AUTH-401001")
+			case http.StatusForbidden:
+				require.Contains(t, w.Body.String(), "AUTH-403001")
+			}
+		})
+	}
+}
+
+func TestPostHandler_ListPosts_IncludeDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostService := mocks.NewMockPostService(ctrl)
+	handler := NewPostHandler(mockPostService, zap.NewNop())
+
+	newContext := func(identity *domain.Identity) (*gin.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest("GET", "/posts?includeDeleted=true", nil)
+		require.NoError(t, err)
+		if identity != nil {
+			req = req.WithContext(domain.ContextWithIdentity(req.Context(), *identity))
+		}
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		return c, w
+	}
+
+	// Only moderators see deleted posts
+	c, w := newContext(nil)
+	handler.ListPosts(c)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), "AUTH-403001")
+
+	c, w = newContext(&domain.Identity{UserID: "u1", Roles: []domain.Role{domain.RoleMember}})
+	handler.ListPosts(c)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	c, w = newContext(&domain.Identity{UserID: "u1", Roles: []domain.Role{domain.RoleModerator}})
+	mockPostService.EXPECT().List(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, query domain.PostQuery) (domain.PaginatedPosts, error) {
+			require.True(t, query.IncludeDeleted)
+			return domain.PaginatedPosts{}, nil
+		}).Times(1)
+	handler.ListPosts(c)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestUserHandler_UserRoles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	handler := NewUserHandler(mockUserService, domain.UserDeleteRestrict, zap.NewNop())
+
+	newContext := func(method string, role string) (*gin.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest(method, "/admin/users/u1/roles/"+role, nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = gin.Params{{Key: "id", Value: "u1"}, {Key: "role", Value: role}}
+		return c, w
+	}
+
+	c, w := newContext("PUT", "moderator")
+	mockUserService.EXPECT().GrantRole(gomock.Any(), "u1", domain.RoleModerator).
+		Return([]domain.Role{domain.RoleMember, domain.RoleModerator}, nil).Times(1)
+	handler.GrantUserRole(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, []interface{}{"member", "moderator"}, resp.Data)
+
+	c, w = newContext("PUT", "superuser")
+	handler.GrantUserRole(c)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), `"role"`)
+
+	c, w = newContext("PUT", "admin")
+	mockUserService.EXPECT().GrantRole(gomock.Any(), "u1", domain.RoleAdmin).Return(nil, domain.ErrUserNotFound).Times(1)
+	handler.GrantUserRole(c)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	c, w = newContext("DELETE", "admin")
+	mockUserService.EXPECT().RevokeRole(gomock.Any(), "u1", domain.RoleAdmin).Return(nil, domain.ErrLastAdmin).Times(1)
+	handler.RevokeUserRole(c)
+	require.Equal(t, http.StatusConflict, w.Code)
+	require.Contains(t, w.Body.String(), "AUTH-409001")
+}
+
+func TestAPIKeyHandler_CreateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeyService := mocks.NewMockAPIKeyService(ctrl)
+	handler := NewAPIKeyHandler(mockAPIKeyService, zap.NewNop())
+
+	newContext := func(body string) (*gin.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest("POST", "/admin/api-keys", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(domain.ContextWithIdentity(req.Context(), domain.Identity{UserID: "admin-1", Roles: []domain.Role{domain.RoleAdmin}}))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		return c, w
+	}
+
+	// The key acts as the admin minting it unless told otherwise
+	c, w := newContext(`{"name": "nightly export", "scopes": ["users:read", "posts:write"]}`)
+	mockAPIKeyService.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, apiKey *domain.APIKey) (string, error) {
+			require.Equal(t, "nightly export", apiKey.Name)
+			require.Equal(t, "admin-1", apiKey.UserID)
+			require.Equal(t, domain.Scopes{domain.ScopeUsersRead, domain.ScopePostsWrite}, apiKey.Scopes)
+			apiKey.ID = "key-1"
+			apiKey.KeyHash = "hash"
+			return "postr_secret", nil
+		}).Times(1)
+	handler.CreateAPIKey(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	data, ok := resp.Data.(map[string]interface{})
+	require.True(t, ok, "expected Data to be a map")
+	require.Equal(t, "key-1", data["id"])
+	require.Equal(t, "postr_secret", data["key"])
+	require.NotContains(t, w.Body.String(), "hash")
+
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"no name", `{"scopes": ["read-only"]}`, "name"},
+		{"no scopes", `{"name": "export"}`, "scopes"},
+		{"unknown scope", `{"name": "export", "scopes": ["users:write"]}`, "scopes"},
+		{"read-only with others", `{"name": "export", "scopes": ["read-only", "posts:write"]}`, "scopes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newContext(tt.body)
+			handler.CreateAPIKey(c)
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			require.Contains(t, w.Body.String(), `"`+tt.field+`"`)
+		})
+	}
+
+	c, w = newContext(`{"name": "export", "scopes": ["read-only"], "userId": "nobody"}`)
+	mockAPIKeyService.EXPECT().Create(gomock.Any(), gomock.Any()).Return("", domain.ErrUserNotFound).Times(1)
+	handler.CreateAPIKey(c)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAuthHandler_Refresh(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		calls  int
+		status int
+	}{
+		{"refreshed", `{"refreshToken": "s1.secret"}`, nil, 1, http.StatusOK},
+		{"missing token", `{}`, nil, 0, http.StatusBadRequest},
+		{"invalid token", `{"refreshToken": "s1.secret"}`, domain.ErrInvalidRefreshToken, 1, http.StatusUnauthorized},
+		{"reused token", `{"refreshToken": "s1.secret"}`, domain.ErrRefreshTokenReused, 1, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuthService := mocks.NewMockAuthService(ctrl)
+			handler := NewAuthHandler(mockAuthService, zap.NewNop())
+
+			req, err := http.NewRequest("POST", "/auth/refresh", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("User-Agent", "postr-test")
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			mockAuthService.EXPECT().Refresh(gomock.Any(), "s1.secret", gomock.Any()).
+				DoAndReturn(func(ctx context.Context, refreshToken string, client domain.SessionClient) (domain.AccessToken, error) {
+					require.Equal(t, "postr-test", client.UserAgent)
+					return domain.AccessToken{AccessToken: "signed.token.value", RefreshToken: "s1.next"}, tt.err
+				}).Times(tt.calls)
+
+			handler.Refresh(c)
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestAuthHandler_Logout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	handler := NewAuthHandler(mockAuthService, zap.NewNop())
+
+	newContext := func(body string, identity domain.Identity) (*gin.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest("POST", "/auth/logout", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(domain.ContextWithIdentity(req.Context(), identity))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		return c, w
+	}
+
+	// Without a body only the current session ends
+	c, w := newContext("", domain.Identity{UserID: "u1", SessionID: "s1"})
+	mockAuthService.EXPECT().RevokeSession(gomock.Any(), "u1", "s1").Return(nil).Times(1)
+	handler.Logout(c)
+	require.Equal(t, http.StatusNoContent, c.Writer.Status())
+
+	c, w = newContext(`{"all": true}`, domain.Identity{UserID: "u1", SessionID: "s1"})
+	mockAuthService.EXPECT().RevokeSessions(gomock.Any(), "u1").Return(nil).Times(1)
+	handler.Logout(c)
+	require.Equal(t, http.StatusNoContent, c.Writer.Status())
+
+	// API keys have no session to log out of
+	c, w = newContext("", domain.Identity{UserID: "u1", APIKeyID: "k1"})
+	handler.Logout(c)
+	require.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAuthHandler_ListUserSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	handler := NewAuthHandler(mockAuthService, zap.NewNop())
+
+	req, err := http.NewRequest("GET", "/users/u1/sessions", nil)
+	require.NoError(t, err)
+	req = req.WithContext(domain.ContextWithIdentity(req.Context(), domain.Identity{UserID: "u1", SessionID: "s2"}))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "u1"}}
+
+	mockAuthService.EXPECT().ListSessions(gomock.Any(), "u1").
+		Return([]domain.Session{{ID: "s1", UserID: "u1", RefreshTokenHash: "hash-1"}, {ID: "s2", UserID: "u1", RefreshTokenHash: "hash-2"}}, nil).Times(1)
+
+	handler.ListUserSessions(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotContains(t, w.Body.String(), "hash-")
+
+	var resp APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	sessions, ok := resp.Data.([]interface{})
+	require.True(t, ok, "expected Data to be a slice")
+	require.Len(t, sessions, 2)
+	require.Equal(t, false, sessions[0].(map[string]interface{})["current"])
+	require.Equal(t, true, sessions[1].(map[string]interface{})["current"])
+}
+
+func TestAuthHandler_UnlockUser(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"success", nil, http.StatusNoContent},
+		{"user not found", domain.ErrUserNotFound, http.StatusNotFound},
+		{"internal error", domain.ErrInternalServer, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuthService := mocks.NewMockAuthService(ctrl)
+			handler := NewAuthHandler(mockAuthService, zap.NewNop())
+
+			req, err := http.NewRequest("DELETE", "/admin/users/u1/lockout", nil)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Params = gin.Params{{Key: "id", Value: "u1"}}
+
+			mockAuthService.EXPECT().Unlock(gomock.Any(), "u1").Return(tt.err).Times(1)
+
+			handler.UnlockUser(c)
+
+			require.Equal(t, tt.status, c.Writer.Status())
+		})
+	}
+}
+
+func TestPostHandler_CreatePost_Unverified(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostService := mocks.NewMockPostService(ctrl)
+	handler := NewPostHandler(mockPostService, zap.NewNop())
+
+	req, err := http.NewRequest("POST", "/posts", strings.NewReader(`{"title": "Test Title", "body": "Test Body"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(domain.ContextWithIdentity(req.Context(), domain.Identity{UserID: "u1"}))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	mockPostService.EXPECT().Create(gomock.Any(), gomock.Any()).Return(domain.ErrEmailNotVerified).Times(1)
+
+	handler.CreatePost(c)
+
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), domain.ErrEmailNotVerified.Code)
+}
+
+func TestVerificationHandler_VerifyEmail(t *testing.T) {
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		name   string
+		query  string
+		err    error
+		calls  int
+		status int
+	}{
+		{"verified", "?token=signed.token.value", nil, 1, http.StatusOK},
+		{"missing token", "", nil, 0, http.StatusBadRequest},
+		{"invalid token", "?token=signed.token.value", domain.ErrInvalidVerificationToken, 1, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockVerificationService := mocks.NewMockVerificationService(ctrl)
+			handler := NewVerificationHandler(mockVerificationService, zap.NewNop())
+
+			req, err := http.NewRequest("GET", "/auth/verify"+tt.query, nil)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			var user *domain.User
+			if tt.err == nil {
+				user = &domain.User{ID: "u1", EmailVerifiedAt: &verifiedAt}
+			}
+			mockVerificationService.EXPECT().VerifyEmail(gomock.Any(), "signed.token.value").Return(user, tt.err).Times(tt.calls)
+
+			handler.VerifyEmail(c)
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestVerificationHandler_ResendVerification(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"sent", nil, http.StatusNoContent},
+		{"already verified", domain.ErrEmailAlreadyVerified, http.StatusConflict},
+		{"send failed", domain.ErrInternalServer, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockVerificationService := mocks.NewMockVerificationService(ctrl)
+			handler := NewVerificationHandler(mockVerificationService, zap.NewNop())
+
+			req, err := http.NewRequest("POST", "/auth/verify/resend", nil)
+			require.NoError(t, err)
+			req = req.WithContext(domain.ContextWithIdentity(req.Context(), domain.Identity{UserID: "u1", SessionID: "s1"}))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			mockVerificationService.EXPECT().SendVerification(gomock.Any(), "u1").Return(tt.err).Times(1)
+
+			handler.ResendVerification(c)
+
+			require.Equal(t, tt.status, c.Writer.Status())
+		})
+	}
+}
+
+func TestPasswordResetHandler_ForgotPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPasswordResetService := mocks.NewMockPasswordResetService(ctrl)
+	handler := NewPasswordResetHandler(mockPasswordResetService, zap.NewNop())
+
+	newContext := func(body string) (*gin.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest("POST", "/auth/password/forgot", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		return c, w
+	}
+
+	// Registered or not, the answer is the same
+	c, w := newContext(`{"email": " alice@example.com "}`)
+	mockPasswordResetService.EXPECT().ForgotPassword(gomock.Any(), "alice@example.com").Times(1)
+	handler.ForgotPassword(c)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	c, w = newContext(`{"email": "not-an-email"}`)
+	handler.ForgotPassword(c)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPasswordResetHandler_ResetPassword(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		calls  int
+		status int
+	}{
+		{"reset", `{"token": "signed.token.value", "password": "new password"}`, nil, 1, http.StatusNoContent},
+		{"short password", `{"token": "signed.token.value", "password": "short"}`, nil, 0, http.StatusBadRequest},
+		{"missing token", `{"password": "new password"}`, nil, 0, http.StatusBadRequest},
+		{"invalid token", `{"token": "signed.token.value", "password": "new password"}`, domain.ErrInvalidResetToken, 1, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPasswordResetService := mocks.NewMockPasswordResetService(ctrl)
+			handler := NewPasswordResetHandler(mockPasswordResetService, zap.NewNop())
+
+			req, err := http.NewRequest("POST", "/auth/password/reset", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			mockPasswordResetService.EXPECT().ResetPassword(gomock.Any(), "signed.token.value", "new password").Return(tt.err).Times(tt.calls)
+
+			handler.ResetPassword(c)
+
+			require.Equal(t, tt.status, c.Writer.Status())
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID := c.GetHeader("X-Test-User"); userID != "" {
+			identity := domain.Identity{UserID: userID, APIKeyID: c.GetHeader("X-Test-Key")}
+			c.Request = c.Request.WithContext(domain.ContextWithIdentity(c.Request.Context(), identity))
+		}
+	})
+	routeLimits := map[string]ratelimit.Limit{
+		"POST /posts":    {Requests: 2, Per: time.Minute},
+		"GET /posts/:id": {},
+	}
+	router.Use(RateLimit(ratelimit.New(), ratelimit.Limit{Requests: 5, Per: time.Minute}, routeLimits, zap.NewNop()))
+	router.POST("/posts", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/posts/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/users", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	do := func(method string, path string, user string, key string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, nil)
+		require.NoError(t, err)
+		req.RemoteAddr = "203.0.113.7:1234"
+		if user != "" {
+			req.Header.Set("X-Test-User", user)
+			req.Header.Set("X-Test-Key", key)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/posts", "u1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+	require.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	require.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	require.Equal(t, "30", w.Header().Get("RateLimit-Reset"))
+
+	require.Equal(t, http.StatusOK, do("POST", "/posts", "u1", "").Code)
+
+	w = do("POST", "/posts", "u1", "")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	require.Equal(t, "30", w.Header().Get("Retry-After"))
+
+	var resp domain.DomainError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, domain.ErrRateLimited.Code, resp.Code)
+
+	// Other users, the user's API keys and anonymous callers each have limits of their own
+	require.Equal(t, http.StatusOK, do("POST", "/posts", "u2", "").Code)
+	require.Equal(t, http.StatusOK, do("POST", "/posts", "u1", "key-1").Code)
+	require.Equal(t, http.StatusOK, do("POST", "/posts", "", "").Code)
+
+	// Routes without a limit of their own get the default one, a zero limit lifts it
+	w = do("GET", "/users", "u1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "5", w.Header().Get("RateLimit-Limit"))
+
+	w = do("GET", "/posts/p1", "u1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("RateLimit-Limit"))
+}
+
+func TestAuthFailureLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Credentials over the limit are refused before they are checked
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockAuthService.EXPECT().Authenticate(gomock.Any(), "good").Return(domain.Identity{UserID: "u1"}, nil).Times(4)
+	mockAuthService.EXPECT().Authenticate(gomock.Any(), "bad").Return(domain.Identity{}, domain.ErrInvalidToken).Times(3)
+	mockAPIKeyService := mocks.NewMockAPIKeyService(ctrl)
+
+	router := gin.New()
+	router.Use(AuthFailureLimit(ratelimit.New(), ratelimit.Limit{Requests: 2, Per: time.Minute}, zap.NewNop()))
+	router.Use(Authenticate(mockAuthService, mockAPIKeyService, zap.NewNop()))
+	router.GET("/whoami", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	do := func(token string, remoteAddr string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", "/whoami", nil)
+		require.NoError(t, err)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Valid credentials do not count
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, do("good", "203.0.113.7:1234").Code)
+	}
+
+	require.Equal(t, http.StatusUnauthorized, do("bad", "203.0.113.7:1234").Code)
+	require.Equal(t, http.StatusUnauthorized, do("bad", "203.0.113.7:1234").Code)
+
+	w := do("bad", "203.0.113.7:1234")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "30", w.Header().Get("Retry-After"))
+	require.Equal(t, http.StatusTooManyRequests, do("good", "203.0.113.7:1234").Code)
+
+	// Other addresses have limits of their own
+	require.Equal(t, http.StatusUnauthorized, do("bad", "198.51.100.1:1234").Code)
+	require.Equal(t, http.StatusOK, do("good", "198.51.100.1:1234").Code)
+}
+
+func TestAuditHandler_ListAuditEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuditService := mocks.NewMockAuditService(ctrl)
+	handler := NewAuditHandler(mockAuditService, zap.NewNop())
+
+	do := func(target string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", target, nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+
+		handler.ListAuditEvents(c)
+		return w
+	}
+
+	// The most recent events come first by default
+	expectedQuery := domain.AuditQuery{
+		ActorID:     "u1",
+		Action:      domain.AuditPostDelete,
+		TargetType:  "post",
+		CreatedFrom: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		SortDesc:    true,
+		Page:        domain.PageRequest{PageNumber: 2, PageSize: 20},
+	}
+	events := []domain.AuditEvent{{ID: "e1", ActorID: "u1", Action: domain.AuditPostDelete, TargetType: "post", TargetID: "p1", Before: domain.Snapshot(`{"title":"Gone"}`)}}
+	pagination := domain.Pagination{CurrentPage: 2, TotalPages: 2, TotalSize: 21}
+	mockAuditService.EXPECT().List(gomock.Any(), expectedQuery).
+		Return(domain.PaginatedAuditEvents{Pagination: pagination, Events: events}, nil)
+
+	w := do("/admin/audit?actorId=u1&action=post.delete&targetType=post&createdFrom=2025-02-01T00:00:00Z&pageNumber=2&pageSize=20")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Pagination domain.Pagination   `json:"pagination"`
+		Data       []domain.AuditEvent `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, pagination, resp.Pagination)
+	require.Len(t, resp.Data, 1)
+	require.JSONEq(t, `{"title":"Gone"}`, string(resp.Data[0].Before))
+	require.Nil(t, resp.Data[0].After)
+
+	mockAuditService.EXPECT().List(gomock.Any(), domain.AuditQuery{Page: domain.PageRequest{Cursor: "abc", PageSize: 5, Keyset: true}}).
+		Return(domain.PaginatedAuditEvents{}, nil)
+	require.Equal(t, http.StatusOK, do("/admin/audit?order=asc&cursor=abc&limit=5").Code)
+
+	w = do("/admin/audit?action=post.publish&targetType=session&order=up&pageSize=500&createdTo=tomorrow")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var derr domain.DomainError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &derr))
+	for _, field := range []string{"action", "targetType", "order", "pageSize", "createdTo"} {
+		require.Contains(t, derr.FieldErrors, field)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	router := gin.New()
+	router.Use(RequestID())
+
+	var request domain.RequestInfo
+	router.GET("/", func(c *gin.Context) {
+		request, _ = domain.RequestFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	do := func(id string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", "/", nil)
+		require.NoError(t, err)
+		req.RemoteAddr = "203.0.113.7:1234"
+		if id != "" {
+			req.Header.Set("X-Request-ID", id)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// The client's id is kept
+	w := do("trace-42")
+	require.Equal(t, "trace-42", w.Header().Get("X-Request-ID"))
+	require.Equal(t, domain.RequestInfo{ID: "trace-42", IPAddress: "203.0.113.7"}, request)
+
+	// One is generated when there is none, or it is unusable
+	for _, id := range []string{"", "has spaces", strings.Repeat("a", 129)} {
+		w = do(id)
+		require.NotEqual(t, id, w.Header().Get("X-Request-ID"))
+		require.NotEmpty(t, w.Header().Get("X-Request-ID"))
+		require.Equal(t, w.Header().Get("X-Request-ID"), request.ID)
+	}
+}
+
+func TestCommentHandler_CreateComment(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		calls  int
+		status int
+	}{
+		{"created", `{"body": " Nice post "}`, nil, 1, http.StatusOK},
+		{"post not found", `{"body": "Nice post"}`, domain.ErrPostNotFound, 1, http.StatusNotFound},
+		{"unverified author", `{"body": "Nice post"}`, domain.ErrEmailNotVerified, 1, http.StatusForbidden},
+		{"empty body", `{"body": "  "}`, nil, 0, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCommentService := mocks.NewMockCommentService(ctrl)
+			handler := NewCommentHandler(mockCommentService, zap.NewNop())
+
+			req, err := http.NewRequest("POST", "/posts/post1/comments", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(domain.ContextWithIdentity(req.Context(), domain.Identity{UserID: "u1"}))
+
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.POST("/posts/:id/comments", handler.CreateComment)
+
+			mockCommentService.EXPECT().Create(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, comment *domain.Comment) error {
+					require.Equal(t, "post1", comment.PostID)
+					require.Equal(t, "u1", comment.UserID)
+					require.Equal(t, "Nice post", comment.Body)
+					require.NotEmpty(t, comment.ID)
+					return tt.err
+				}).Times(tt.calls)
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestCommentHandler_ListComments(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		want   domain.CommentQuery
+		err    error
+		calls  int
+		status int
+	}{
+		{
+			name:   "defaults",
+			want:   domain.CommentQuery{PostID: "post1", Page: domain.PageRequest{PageNumber: 1, PageSize: 10}},
+			calls:  1,
+			status: http.StatusOK,
+		},
+		{
+			name:   "most recent first by cursor",
+			query:  "?order=desc&limit=5",
+			want:   domain.CommentQuery{PostID: "post1", SortDesc: true, Page: domain.PageRequest{PageSize: 5, Keyset: true}},
+			calls:  1,
+			status: http.StatusOK,
+		},
+		{
+			name:   "post not found",
+			want:   domain.CommentQuery{PostID: "post1", Page: domain.PageRequest{PageNumber: 1, PageSize: 10}},
+			err:    domain.ErrPostNotFound,
+			calls:  1,
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "page number with cursor",
+			query:  "?pageNumber=2&cursor=abc",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCommentService := mocks.NewMockCommentService(ctrl)
+			handler := NewCommentHandler(mockCommentService, zap.NewNop())
+
+			req, err := http.NewRequest("GET", "/posts/post1/comments"+tt.query, nil)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.GET("/posts/:id/comments", handler.ListComments)
+
+			mockCommentService.EXPECT().List(gomock.Any(), tt.want).
+				Return(domain.PaginatedComments{Comments: []domain.Comment{{ID: "c1", PostID: "post1"}}}, tt.err).Times(tt.calls)
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestCommentHandler_DeleteComment(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"deleted", nil, http.StatusNoContent},
+		{"not found", domain.ErrCommentNotFound, http.StatusNotFound},
+		{"unauthenticated", domain.ErrUnauthenticated, http.StatusUnauthorized},
+		{"not the author", domain.ErrCommentForbidden, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCommentService := mocks.NewMockCommentService(ctrl)
+			handler := NewCommentHandler(mockCommentService, zap.NewNop())
+
+			req, err := http.NewRequest("DELETE", "/comments/c1", nil)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.DELETE("/comments/:id", handler.DeleteComment)
+
+			mockCommentService.EXPECT().Delete(gomock.Any(), "c1").Return(tt.err)
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestReactionHandler(t *testing.T) {
+	reactions := domain.NewReactionCounts(map[domain.ReactionKind]int{domain.ReactionLike: 1}, map[domain.ReactionKind]bool{domain.ReactionLike: true})
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		identity bool
+		err      error
+		calls    int
+		status   int
+	}{
+		{"react", "PUT", "/posts/post1/reactions/like", true, nil, 1, http.StatusOK},
+		{"unreact", "DELETE", "/posts/post1/reactions/like", true, nil, 1, http.StatusOK},
+		{"post not found", "PUT", "/posts/post1/reactions/like", true, domain.ErrPostNotFound, 1, http.StatusNotFound},
+		{"unknown kind", "PUT", "/posts/post1/reactions/meh", true, nil, 0, http.StatusBadRequest},
+		{"unauthenticated", "DELETE", "/posts/post1/reactions/like", false, nil, 0, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockReactionService := mocks.NewMockReactionService(ctrl)
+			handler := NewReactionHandler(mockReactionService, zap.NewNop())
+
+			req, err := http.NewRequest(tt.method, tt.path, nil)
+			require.NoError(t, err)
+			if tt.identity {
+				req = req.WithContext(domain.ContextWithIdentity(req.Context(), domain.Identity{UserID: "u1"}))
+			}
+
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.PUT("/posts/:id/reactions/:kind", handler.React)
+			router.DELETE("/posts/:id/reactions/:kind", handler.Unreact)
+
+			want := domain.Reaction{PostID: "post1", UserID: "u1", Kind: domain.ReactionLike}
+			if tt.method == "PUT" {
+				mockReactionService.EXPECT().React(gomock.Any(), want).Return(reactions, tt.err).Times(tt.calls)
+			} else {
+				mockReactionService.EXPECT().Unreact(gomock.Any(), want).Return(reactions, tt.err).Times(tt.calls)
+			}
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				var resp struct {
+					Data []domain.ReactionCount `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				require.Equal(t, reactions, resp.Data)
+			}
+		})
+	}
+}
+
+func TestPostHandler_Feed(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		identity bool
+		want     domain.PageRequest
+		err      error
+		calls    int
+		status   int
+	}{
+		{"defaults", "", true, domain.PageRequest{PageSize: 10, Keyset: true}, nil, 1, http.StatusOK},
+		{"cursor", "?cursor=abc&limit=5", true, domain.PageRequest{Cursor: "abc", PageSize: 5, Keyset: true}, nil, 1, http.StatusOK},
+		{"bad cursor", "?cursor=abc", true, domain.PageRequest{Cursor: "abc", PageSize: 10, Keyset: true}, domain.ErrInvalidInput, 1, http.StatusBadRequest},
+		{"limit too large", "?limit=1000", true, domain.PageRequest{}, nil, 0, http.StatusBadRequest},
+		{"unauthenticated", "", false, domain.PageRequest{}, nil, 0, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPostService := mocks.NewMockPostService(ctrl)
+			handler := NewPostHandler(mockPostService, zap.NewNop())
+
+			req, err := http.NewRequest("GET", "/feed"+tt.query, nil)
+			require.NoError(t, err)
+			if tt.identity {
+				req = req.WithContext(domain.ContextWithIdentity(req.Context(), domain.Identity{UserID: "u1"}))
+			}
+
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.GET("/feed", handler.Feed)
+
+			mockPostService.EXPECT().Feed(gomock.Any(), "u1", tt.want).
+				Return(domain.PaginatedPosts{Posts: []domain.Post{{ID: "post1"}}}, tt.err).Times(tt.calls)
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestFollowHandler_Follow(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		identity bool
+		err      error
+		calls    int
+		status   int
+	}{
+		{"follow", "POST", true, nil, 1, http.StatusOK},
+		{"follow self", "POST", true, domain.ErrCannotFollowSelf, 1, http.StatusBadRequest},
+		{"followee not found", "POST", true, domain.ErrUserNotFound, 1, http.StatusNotFound},
+		{"unfollow", "DELETE", true, nil, 1, http.StatusNoContent},
+		{"unauthenticated", "POST", false, nil, 0, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockFollowService := mocks.NewMockFollowService(ctrl)
+			handler := NewFollowHandler(mockFollowService, zap.NewNop())
+
+			req, err := http.NewRequest(tt.method, "/users/u2/follow", nil)
+			require.NoError(t, err)
+			if tt.identity {
+				req = req.WithContext(domain.ContextWithIdentity(req.Context(), domain.Identity{UserID: "u1"}))
+			}
+
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.POST("/users/:id/follow", handler.Follow)
+			router.DELETE("/users/:id/follow", handler.Unfollow)
+
+			if tt.method == "POST" {
+				mockFollowService.EXPECT().Follow(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, follow *domain.Follow) error {
+						require.Equal(t, "u1", follow.FollowerID)
+						require.Equal(t, "u2", follow.FolloweeID)
+						return tt.err
+					}).Times(tt.calls)
+			} else {
+				mockFollowService.EXPECT().Unfollow(gomock.Any(), "u1", "u2").Return(tt.err).Times(tt.calls)
+			}
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestFollowHandler_List(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		want   domain.FollowQuery
+		err    error
+		calls  int
+		status int
+	}{
+		{
+			name:   "followers",
+			path:   "/users/u1/followers",
+			want:   domain.FollowQuery{UserID: "u1", Page: domain.PageRequest{PageNumber: 1, PageSize: 10}},
+			calls:  1,
+			status: http.StatusOK,
+		},
+		{
+			name:   "following by cursor",
+			path:   "/users/u1/following?limit=5",
+			want:   domain.FollowQuery{UserID: "u1", Following: true, Page: domain.PageRequest{PageSize: 5, Keyset: true}},
+			calls:  1,
+			status: http.StatusOK,
+		},
+		{
+			name:   "user not found",
+			path:   "/users/u1/followers",
+			want:   domain.FollowQuery{UserID: "u1", Page: domain.PageRequest{PageNumber: 1, PageSize: 10}},
+			err:    domain.ErrUserNotFound,
+			calls:  1,
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "page number with cursor",
+			path:   "/users/u1/following?pageNumber=2&cursor=abc",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockFollowService := mocks.NewMockFollowService(ctrl)
+			handler := NewFollowHandler(mockFollowService, zap.NewNop())
+
+			req, err := http.NewRequest("GET", tt.path, nil)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.GET("/users/:id/followers", handler.ListFollowers)
+			router.GET("/users/:id/following", handler.ListFollowing)
+
+			mockFollowService.EXPECT().List(gomock.Any(), tt.want).
+				Return(domain.PaginatedFollowUsers{Users: []domain.FollowUser{{ID: "u2"}}}, tt.err).Times(tt.calls)
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestPostHandler_CreatePost_Tags(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		calls  int
+		status int
+	}{
+		{"tags", `{"title": "Weekend", "body": "At the #beach", "tags": ["#Travel", "food"]}`, 1, http.StatusOK},
+		{"invalid tag", `{"title": "Weekend", "body": "At the beach", "tags": ["no spaces"]}`, 0, http.StatusBadRequest},
+		{"too many tags", `{"title": "Weekend", "body": "At the beach", "tags": ["a","b","c","d","e","f","g","h","i","j","k"]}`, 0, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPostService := mocks.NewMockPostService(ctrl)
+			handler := NewPostHandler(mockPostService, zap.NewNop())
+
+			req, err := http.NewRequest("POST", "/posts", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req = req.WithContext(domain.ContextWithIdentity(req.Context(), domain.Identity{UserID: "u1"}))
+
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.POST("/posts", handler.CreatePost)
+
+			mockPostService.EXPECT().Create(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, post *domain.Post) error {
+					require.Equal(t, []string{"#Travel", "food"}, post.Tags)
+					return nil
+				}).Times(tt.calls)
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestParseTimeParam(t *testing.T) {
+	// Plain dates are the same days on servers in any time zone
+	local := time.Local
+	time.Local = time.FixedZone("UTC-5", -5*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	from, dateOnly, err := parseTimeParam("2025-02-10")
+	require.NoError(t, err)
+	require.True(t, dateOnly)
+	require.Equal(t, time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC), from)
+
+	to, err := parseTimeParamEnd("2025-02-10")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 2, 11, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond), to)
+
+	at, dateOnly, err := parseTimeParam("2025-02-10T08:00:00-05:00")
+	require.NoError(t, err)
+	require.False(t, dateOnly)
+	require.True(t, at.Equal(time.Date(2025, 2, 10, 13, 0, 0, 0, time.UTC)))
+}
+
+func TestPostHandler_BodyLength(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Bodies are counted in characters, not bytes
+	mockPostService := mocks.NewMockPostService(ctrl)
+	mockPostService.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	handler := NewPostHandler(mockPostService, zap.NewNop())
+
+	router := gin.New()
+	router.POST("/posts", handler.CreatePost)
+	router.PATCH("/posts/:id", handler.UpdatePost)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"longest body", "POST", "/posts", strings.Repeat("é", maxPostBodyLength), http.StatusOK},
+		{"body too long", "POST", "/posts", strings.Repeat("a", maxPostBodyLength+1), http.StatusBadRequest},
+		{"edited body too long", "PATCH", "/posts/post1", strings.Repeat("\n", maxPostBodyLength+1), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.path, strings.NewReader(`{"title": "Long", "body": "`+tt.body+`"}`))
+			require.NoError(t, err)
+			req = req.WithContext(domain.ContextWithIdentity(req.Context(), domain.Identity{UserID: "u1"}))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestPostHandler_UpdatePost_Tags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostService := mocks.NewMockPostService(ctrl)
+	handler := NewPostHandler(mockPostService, zap.NewNop())
+
+	router := gin.New()
+	router.PATCH("/posts/:id", handler.UpdatePost)
+
+	// Tags alone are an update
+	mockPostService.EXPECT().Update(gomock.Any(), "post1", gomock.Any()).
+		DoAndReturn(func(_ context.Context, id string, update domain.PostUpdate) (*domain.Post, error) {
+			require.Nil(t, update.Title)
+			require.Equal(t, []string{"food"}, *update.Tags)
+			return &domain.Post{ID: id, Tags: *update.Tags}, nil
+		})
+
+	req, err := http.NewRequest("PATCH", "/posts/post1", strings.NewReader(`{"tags": ["food"]}`))
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	req, err = http.NewRequest("PATCH", "/posts/post1", strings.NewReader(`{"tags": ["#"]}`))
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPostHandler_ListPostsByTag(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		want   domain.PostQuery
+		calls  int
+		status int
+	}{
+		{
+			name: "tag",
+			path: "/tags/%23GoLang/posts?limit=5",
+			want: domain.PostQuery{
+				Tag:      "golang",
+				SortBy:   domain.PostSortCreatedAt,
+				SortDesc: true,
+				Page:     domain.PageRequest{PageSize: 5, Keyset: true},
+			},
+			calls:  1,
+			status: http.StatusOK,
+		},
+		{
+			name:   "digits only",
+			path:   "/tags/2025/posts",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPostService := mocks.NewMockPostService(ctrl)
+			handler := NewPostHandler(mockPostService, zap.NewNop())
+
+			req, err := http.NewRequest("GET", tt.path, nil)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.GET("/tags/:name/posts", handler.ListPostsByTag)
+
+			mockPostService.EXPECT().List(gomock.Any(), tt.want).
+				Return(domain.PaginatedPosts{Posts: []domain.Post{{ID: "post1", Tags: []string{"golang"}}}}, nil).Times(tt.calls)
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestTagHandler_ListTags(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		want   domain.TagQuery
+		err    error
+		calls  int
+		status int
+	}{
+		{
+			name:   "defaults",
+			want:   domain.TagQuery{Page: domain.PageRequest{PageNumber: 1, PageSize: 10}},
+			calls:  1,
+			status: http.StatusOK,
+		},
+		{
+			name:   "prefix",
+			query:  "?prefix=%23Go&pageNumber=2&pageSize=5",
+			want:   domain.TagQuery{Prefix: "go", Page: domain.PageRequest{PageNumber: 2, PageSize: 5}},
+			calls:  1,
+			status: http.StatusOK,
+		},
+		{
+			name:   "invalid prefix",
+			query:  "?prefix=go-",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "internal error",
+			want:   domain.TagQuery{Page: domain.PageRequest{PageNumber: 1, PageSize: 10}},
+			err:    domain.ErrInternalServer,
+			calls:  1,
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockTagService := mocks.NewMockTagService(ctrl)
+			handler := NewTagHandler(mockTagService, zap.NewNop())
+
+			req, err := http.NewRequest("GET", "/tags"+tt.query, nil)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.GET("/tags", handler.ListTags)
+
+			mockTagService.EXPECT().List(gomock.Any(), tt.want).
+				Return(domain.PaginatedTags{Tags: []domain.Tag{{Name: "golang", Posts: 3}}}, tt.err).Times(tt.calls)
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestPostHandler_ListMentions(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"success", nil, http.StatusOK},
+		{"user not found", domain.ErrUserNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPostService := mocks.NewMockPostService(ctrl)
+			handler := NewPostHandler(mockPostService, zap.NewNop())
+
+			req, err := http.NewRequest("GET", "/users/b63df572-9bd1-4a4f-9f0d-2a8155a81fde/mentions?limit=5", nil)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.GET("/users/:id/mentions", handler.ListMentions)
+
+			want := domain.PostQuery{
+				MentionedUserID: "b63df572-9bd1-4a4f-9f0d-2a8155a81fde",
+				SortBy:          domain.PostSortCreatedAt,
+				SortDesc:        true,
+				Page:            domain.PageRequest{PageSize: 5, Keyset: true},
+			}
+			mockPostService.EXPECT().List(gomock.Any(), want).
+				Return(domain.PaginatedPosts{Posts: []domain.Post{{ID: "post1"}}}, tt.err)
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
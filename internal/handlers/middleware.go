@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+const (
+	// requestIDHeader carries the id of a request, clients may set it to trace their requests
+	requestIDHeader = "X-Request-ID"
+	// maxRequestIDLength is the longest request id taken from a client
+	maxRequestIDLength = 128
+	// invalidCredentialsKey marks the requests Authenticate refused the credentials of, for AuthFailureLimit
+	invalidCredentialsKey = "invalidCredentials"
+)
+
+// RequestID puts the id and client IP of the request on the request context, so the changes made in it can be
+// traced back to it. The id sent by the client is kept if it is usable, one is generated otherwise, and it is sent
+// back in the response either way. Like every client IP, the IP is only taken from X-Forwarded-For behind a
+// trusted proxy
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Header(requestIDHeader, id)
+
+		request := domain.RequestInfo{ID: id, IPAddress: c.ClientIP()}
+		c.Request = c.Request.WithContext(domain.ContextWithRequest(c.Request.Context(), request))
+		c.Next()
+	}
+}
+
+// validRequestID accepts ids of printable ASCII characters, without spaces, that are not too long
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// Authenticate puts the identity of the caller on the request context when the request carries a bearer
+// access token or an API key. Requests without either go through anonymously, requests with an invalid one are
+// refused. Requests made with an API key are logged along with the key
+func Authenticate(authService domain.AuthService, apiKeyService domain.APIKeyService, logger *zap.Logger) gin.HandlerFunc {
+	logger = logger.With(zap.String("package", "handlers"), zap.String("method", "Authenticate"))
+
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		scheme, credentials, _ := strings.Cut(header, " ")
+		credentials = strings.TrimSpace(credentials)
+
+		var identity domain.Identity
+		var err error
+		switch {
+		case strings.EqualFold(scheme, "Bearer") && credentials != "":
+			identity, err = authService.Authenticate(c.Request.Context(), credentials)
+		case strings.EqualFold(scheme, "ApiKey") && credentials != "":
+			identity, err = apiKeyService.Authenticate(c.Request.Context(), credentials)
+		default:
+			err = domain.ErrInvalidToken
+		}
+		if err != nil {
+			if errors.Is(err, domain.ErrInvalidToken) || errors.Is(err, domain.ErrInvalidAPIKey) {
+				c.Set(invalidCredentialsKey, true)
+				unauthorized(c, err)
+				return
+			}
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, err)
+			return
+		}
+
+		c.Request = c.Request.WithContext(domain.ContextWithIdentity(c.Request.Context(), identity))
+		c.Next()
+
+		if identity.APIKeyID != "" {
+			logger.Info("API key request",
+				zap.String("api_key_id", identity.APIKeyID),
+				zap.String("user_id", identity.UserID),
+				zap.String("http_method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.Int("status", c.Writer.Status()),
+			)
+		}
+	}
+}
+
+// RequireAuth refuses requests that Authenticate did not find an identity for
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := domain.IdentityFromContext(c.Request.Context()); !ok {
+			unauthorized(c, domain.ErrUnauthenticated)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermission refuses requests from callers whose roles do not grant the permission
+func RequirePermission(permission domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := domain.IdentityFromContext(c.Request.Context())
+		if !ok {
+			unauthorized(c, domain.ErrUnauthenticated)
+			return
+		}
+
+		if !identity.Can(permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, domain.ErrForbidden)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSelfOrPermission lets callers signed in as themselves act on their own user, named by the path
+// parameter param, acting on any other user, or through an API key, needs the permission
+func RequireSelfOrPermission(param string, permission domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := domain.IdentityFromContext(c.Request.Context())
+		if !ok {
+			unauthorized(c, domain.ErrUnauthenticated)
+			return
+		}
+
+		if !identity.IsSelf(c.Param(param)) && !identity.Can(permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, domain.ErrForbidden)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func unauthorized(c *gin.Context, err error) {
+	c.Header("WWW-Authenticate", `Bearer realm="postr", ApiKey realm="postr"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, err)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-ozzo/ozzo-validation/v4"
+	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type PasswordResetHandler struct {
+	service domain.PasswordResetService
+	logger  *zap.Logger
+}
+
+func NewPasswordResetHandler(service domain.PasswordResetService, logger *zap.Logger) *PasswordResetHandler {
+	logger = logger.With(zap.String("package", "handlers"))
+
+	return &PasswordResetHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ForgotPassword emails a reset token to the user registered with the email. It answers the same whether or not
+// there is one, so it cannot be used to find out who is registered
+func (h *PasswordResetHandler) ForgotPassword(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "ForgotPassword"))
+
+	var req forgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logr.Error("Error binding JSON", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+
+	// Validate request body
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	h.service.ForgotPassword(c.Request.Context(), req.Email)
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "If the email is registered, a password reset token has been sent to it",
+	}
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// ResetPassword sets a new password with a reset token, logging the user out everywhere
+func (h *PasswordResetHandler) ResetPassword(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "ResetPassword"))
+
+	var req resetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logr.Error("Error binding JSON", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	req.Token = strings.TrimSpace(req.Token)
+
+	// Validate request body
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	if err := h.service.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		if errors.Is(err, domain.ErrInvalidResetToken) {
+			c.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("Password reset successfully")
+
+	c.Status(http.StatusNoContent)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -28,9 +29,17 @@ func NewPostHandler(service domain.PostService, logger *zap.Logger) *PostHandler
 	}
 }
 
+// CreatePost creates a post authored by the authenticated caller
 func (h *PostHandler) CreatePost(c *gin.Context) {
 	logr := h.logger.With(zap.String("method", "CreatePost"))
 
+	identity, ok := domain.IdentityFromContext(c.Request.Context())
+	if !ok {
+		logr.Error("Unauthenticated request")
+		c.JSON(http.StatusUnauthorized, domain.ErrUnauthenticated)
+		return
+	}
+
 	var req createPostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logr.Error("Error binding JSON", zap.Error(err))
@@ -39,7 +48,6 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 	}
 
 	// Trim whitespace from the request fields
-	req.UserID = strings.TrimSpace(req.UserID)
 	req.Title = strings.TrimSpace(req.Title)
 	req.Body = strings.TrimSpace(req.Body)
 
@@ -58,9 +66,10 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 
 	post := &domain.Post{
 		ID:        uuid.NewString(),
-		UserID:    req.UserID,
+		UserID:    identity.UserID,
 		Title:     req.Title,
 		Body:      req.Body,
+		Tags:      req.Tags,
 		CreatedAt: time.Now(),
 	}
 
@@ -70,6 +79,16 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, domain.ErrEmailNotVerified) {
+			c.JSON(http.StatusForbidden, err)
+			return
+		}
+
+		if status, ok := constraintStatus(err); ok {
+			c.JSON(status, err)
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, err)
 		return
 	}
@@ -84,28 +103,319 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// GetPost returns a single post
+//
+// GET /posts/:id used to list the posts of the user with that id, requests for an id that is
+// not a post but is a user are still answered that way, flagged with a Deprecation header
+func (h *PostHandler) GetPost(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "GetPost"))
+
+	id := c.Param("id")
+	post, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrPostNotFound) {
+			h.legacyListPostsByUserID(c, id)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("Post retrieved successfully", zap.String("id", id))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "Post retrieved successfully",
+		Data:    post,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListPosts lists posts, optionally filtered by the userId query parameter
+func (h *PostHandler) ListPosts(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "ListPosts"))
+
+	userId := strings.TrimSpace(c.Query("userId"))
+	h.list(c, logr, func(query *domain.PostQuery) { query.UserID = userId })
+}
+
+// ListPostsByUserID lists the posts of the user in the id path parameter
 func (h *PostHandler) ListPostsByUserID(c *gin.Context) {
 	logr := h.logger.With(zap.String("method", "ListPostsByUserID"))
 
-	userId := c.Param("userId")
+	userId := c.Param("id")
 	if userId == "" {
 		h.logger.Error("Missing userId path parameter")
 		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
 		return
 	}
 
-	posts, err := h.service.List(c.Request.Context(), userId)
+	h.list(c, logr, func(query *domain.PostQuery) { query.UserID = userId })
+}
+
+// ListPostsByTag lists the posts carrying the tag in the name path parameter
+func (h *PostHandler) ListPostsByTag(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "ListPostsByTag"))
+
+	tag, ok := domain.NormalizeTag(c.Param("name"))
+	if !ok {
+		logr.Info("Invalid tag", zap.String("name", c.Param("name")))
+		verrs := validation.Errors{"name": errInvalidTag}
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+		return
+	}
+
+	h.list(c, logr, func(query *domain.PostQuery) { query.Tag = tag })
+}
+
+// ListMentions lists the posts mentioning the user in the id path parameter
+func (h *PostHandler) ListMentions(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "ListMentions"))
+
+	userId := c.Param("id")
+	if userId == "" {
+		logr.Error("Missing userId path parameter")
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	h.list(c, logr, func(query *domain.PostQuery) { query.MentionedUserID = userId })
+}
+
+// list serves a page of posts as selected by the listPostsRequest query parameters, scope narrows the query down
+// to the posts of a user, of a tag or mentioning a user
+func (h *PostHandler) list(c *gin.Context, logr *zap.Logger, scope func(query *domain.PostQuery)) {
+	var req listPostsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logr.Error("Error binding query", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	// Deleted posts are only listed to those who may restore them
+	if req.IncludeDeleted {
+		identity, _ := domain.IdentityFromContext(c.Request.Context())
+		if !identity.Can(domain.PermPostsModerate) {
+			logr.Info("Caller may not list deleted posts", zap.String("userId", identity.UserID))
+			c.JSON(http.StatusForbidden, domain.ErrForbidden)
+			return
+		}
+	}
+
+	query := newPostQuery(req)
+	scope(&query)
+
+	paginatedPosts, err := h.service.List(c.Request.Context(), query)
 	if err != nil {
 		if errors.Is(err, domain.ErrUserNotFound) {
 			c.JSON(http.StatusNotFound, err)
 			return
 		}
+		if errors.Is(err, domain.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, domain.ErrInternalServer)
+		return
+	}
+
+	logr.Info("Posts listed successfully", zap.String("userId", query.UserID), zap.Int("count", len(paginatedPosts.Posts)))
+
+	resp := APIResponse{
+		Status:     successStatus,
+		Message:    "Posts listed successfully",
+		Pagination: &paginatedPosts.Pagination,
+		Data:       paginatedPosts.Posts,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// newPostQuery turns a validated listPostsRequest into a post query, filling in the defaults.
+// Listings are sorted newest first unless asked otherwise
+func newPostQuery(req listPostsRequest) domain.PostQuery {
+	query := domain.PostQuery{
+		SortBy:         domain.PostSortCreatedAt,
+		SortDesc:       req.Order != sortAsc,
+		IncludeDeleted: req.IncludeDeleted,
+	}
+	if req.SortBy != "" {
+		query.SortBy = domain.PostSortField(req.SortBy)
+		// Titles read naturally in alphabetical order
+		query.SortDesc = req.Order == sortDesc
+	}
+
+	if req.CreatedFrom != "" {
+		query.CreatedFrom, _, _ = parseTimeParam(req.CreatedFrom)
+	}
+	if req.CreatedTo != "" {
+		query.CreatedTo, _ = parseTimeParamEnd(req.CreatedTo)
+	}
+	if req.Tag != "" {
+		query.Tag, _ = domain.NormalizeTag(req.Tag)
+	}
+
+	query.Page = req.toPageRequest()
+	return query
+}
+
+// SearchPosts runs a ranked full-text search over post titles and bodies
+func (h *PostHandler) SearchPosts(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "SearchPosts"))
+
+	var req searchPostsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logr.Error("Error binding query", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	req.Query = strings.TrimSpace(req.Query)
+
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	search := domain.PostSearch{
+		Query: req.Query,
+		Page:  pageRequest{PageNumber: req.PageNumber, PageSize: req.PageSize}.toPageRequest(),
+	}
+
+	results, err := h.service.Search(c.Request.Context(), search)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, err)
+			return
+		}
 
 		c.JSON(http.StatusInternalServerError, domain.ErrInternalServer)
 		return
 	}
 
-	logr.Info("Posts listed successfully", zap.String("userId", userId), zap.Int("count", len(posts)))
+	logr.Info("Posts searched successfully", zap.String("q", req.Query), zap.Int("total", results.Pagination.TotalSize))
+
+	resp := APIResponse{
+		Status:     successStatus,
+		Message:    "Posts searched successfully",
+		Pagination: &results.Pagination,
+		Data:       results.Results,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Feed serves a page of the posts of the users the authenticated caller follows, most recent first
+func (h *PostHandler) Feed(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "Feed"))
+
+	identity, ok := domain.IdentityFromContext(c.Request.Context())
+	if !ok {
+		logr.Error("Unauthenticated request")
+		c.JSON(http.StatusUnauthorized, domain.ErrUnauthenticated)
+		return
+	}
+
+	var req feedRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logr.Error("Error binding query", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	page := domain.PageRequest{Cursor: req.Cursor, PageSize: req.Limit, Keyset: true}
+	if page.PageSize == 0 {
+		page.PageSize = defaultPageSize
+	}
+
+	feed, err := h.service.Feed(c.Request.Context(), identity.UserID, page)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, domain.ErrInternalServer)
+		return
+	}
+
+	logr.Info("Feed read successfully", zap.String("userId", identity.UserID), zap.Int("count", len(feed.Posts)))
+
+	resp := APIResponse{
+		Status:     successStatus,
+		Message:    "Feed retrieved successfully",
+		Pagination: &feed.Pagination,
+		Data:       feed.Posts,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// legacyListPostsByUserID serves the deprecated GET /posts/:userId, pointing clients at GET /users/:id/posts
+func (h *PostHandler) legacyListPostsByUserID(c *gin.Context, userId string) {
+	logr := h.logger.With(zap.String("method", "legacyListPostsByUserID"))
+
+	// The legacy route is not paginated, it returns every post of the user, read a page at a time
+	query := domain.PostQuery{
+		UserID:   userId,
+		SortBy:   domain.PostSortCreatedAt,
+		SortDesc: true,
+		Page:     domain.PageRequest{PageSize: maxPageSize, Keyset: true},
+	}
+	posts := []domain.Post{}
+	for {
+		paginatedPosts, err := h.service.List(c.Request.Context(), query)
+		if err != nil {
+			if errors.Is(err, domain.ErrUserNotFound) {
+				c.JSON(http.StatusNotFound, domain.ErrPostNotFound)
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, domain.ErrInternalServer)
+			return
+		}
+
+		posts = append(posts, paginatedPosts.Posts...)
+		if paginatedPosts.Pagination.NextCursor == "" {
+			break
+		}
+		query.Page.Cursor = paginatedPosts.Pagination.NextCursor
+	}
+
+	logr.Warn("Deprecated route used", zap.String("userId", userId))
+
+	c.Header("Deprecation", "true")
+	c.Header("Link", fmt.Sprintf(`</users/%s/posts>; rel="successor-version"`, userId))
 
 	resp := APIResponse{
 		Status:  successStatus,
@@ -115,6 +425,151 @@ func (h *PostHandler) ListPostsByUserID(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+func (h *PostHandler) UpdatePost(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "UpdatePost"))
+
+	var req updatePostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logr.Error("Error binding JSON", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	if req.Title == nil && req.Body == nil && req.Tags == nil {
+		logr.Error("No fields to update")
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	// Trim whitespace from the request fields
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		req.Title = &title
+	}
+	if req.Body != nil {
+		body := strings.TrimSpace(*req.Body)
+		req.Body = &body
+	}
+
+	// Validate request body
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	id := c.Param("id")
+	post, err := h.service.Update(c.Request.Context(), id, domain.PostUpdate{Title: req.Title, Body: req.Body, Tags: req.Tags})
+	if err != nil {
+		if errors.Is(err, domain.ErrPostNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrUnauthenticated) {
+			c.JSON(http.StatusUnauthorized, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrPostForbidden) {
+			c.JSON(http.StatusForbidden, err)
+			return
+		}
+		if errors.Is(err, domain.ErrPostEditConflict) {
+			c.JSON(http.StatusConflict, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("Post updated successfully", zap.Any("post", post))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "Post updated successfully",
+		Data:    post,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *PostHandler) ListPostRevisions(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "ListPostRevisions"))
+
+	id := c.Param("id")
+	revisions, err := h.service.ListRevisions(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrPostNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("Post revisions listed successfully", zap.String("id", id), zap.Int("count", len(revisions)))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "Post revisions listed successfully",
+		Data:    revisions,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DiffPostRevisions shows what changed between the from and to versions of a post
+func (h *PostHandler) DiffPostRevisions(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "DiffPostRevisions"))
+
+	var req diffPostRevisionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logr.Error("Error binding query", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	id := c.Param("id")
+	postDiff, err := h.service.DiffRevisions(c.Request.Context(), id, req.From, req.To)
+	if err != nil {
+		if errors.Is(err, domain.ErrPostNotFound) || errors.Is(err, domain.ErrPostRevisionNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("Post revisions diffed successfully", zap.String("id", id), zap.Int("from", req.From), zap.Int("to", req.To))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "Post revisions diffed successfully",
+		Data:    postDiff,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 func (h *PostHandler) DeletePost(c *gin.Context) {
 	logr := h.logger.With(zap.String("method", "ListPostsByUserID"))
 
@@ -125,6 +580,16 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, domain.ErrUnauthenticated) {
+			c.JSON(http.StatusUnauthorized, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrPostForbidden) {
+			c.JSON(http.StatusForbidden, err)
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, err)
 		return
 
@@ -133,3 +598,44 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 	logr.Info("Post deleted successfully", zap.String("id", id))
 	c.Status(http.StatusNoContent)
 }
+
+// RestorePost undoes the delete of a post
+func (h *PostHandler) RestorePost(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "RestorePost"))
+
+	id := c.Param("id")
+	post, err := h.service.Restore(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrPostNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrUnauthenticated) {
+			c.JSON(http.StatusUnauthorized, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrPostForbidden) {
+			c.JSON(http.StatusForbidden, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrPostAuthorDeleted) {
+			c.JSON(http.StatusConflict, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("Post restored successfully", zap.String("id", id))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "Post restored successfully",
+		Data:    post,
+	}
+	c.JSON(http.StatusOK, resp)
+}
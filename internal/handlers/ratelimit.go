@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+	"github.com/victor-nach/postr-backend/pkg/ratelimit"
+)
+
+// RateLimit limits how fast each client can call each route, routes without a limit in routeLimits get
+// defaultLimit. Clients are told apart by their API key, else by the user they are signed in as, else by their IP,
+// so it has to run after Authenticate. Every limited response carries the RateLimit headers, and refused ones a
+// Retry-After header
+func RateLimit(limiter *ratelimit.Limiter, defaultLimit ratelimit.Limit, routeLimits map[string]ratelimit.Limit, logger *zap.Logger) gin.HandlerFunc {
+	logger = logger.With(zap.String("package", "handlers"), zap.String("method", "RateLimit"))
+
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		limit, ok := routeLimits[route]
+		if !ok {
+			limit = defaultLimit
+		}
+		if limit.Unlimited() {
+			c.Next()
+			return
+		}
+
+		client := rateLimitClient(c)
+		result := limiter.Allow(route+" "+client, limit)
+
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, seconds(limit.Per)))
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+
+		if !result.Allowed {
+			logger.Info("Rate limit exceeded", zap.String("route", route), zap.String("client", client))
+			c.Header("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, domain.ErrRateLimited)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AuthFailureLimit limits how many requests with invalid credentials each IP can send, so access tokens and API
+// keys cannot be guessed faster than limit. It runs before Authenticate, which RateLimit comes after: every request
+// carrying credentials takes a token out of the IP's bucket up front, and gets it back once Authenticate accepts
+// the credentials
+func AuthFailureLimit(limiter *ratelimit.Limiter, limit ratelimit.Limit, logger *zap.Logger) gin.HandlerFunc {
+	logger = logger.With(zap.String("package", "handlers"), zap.String("method", "AuthFailureLimit"))
+
+	return func(c *gin.Context) {
+		if limit.Unlimited() || c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+
+		client := "ip:" + c.ClientIP()
+		key := "auth-failures " + client
+		result := limiter.Allow(key, limit)
+		if !result.Allowed {
+			logger.Info("Too many invalid credentials", zap.String("client", client))
+			c.Header("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, domain.ErrRateLimited)
+			return
+		}
+
+		c.Next()
+
+		if !c.GetBool(invalidCredentialsKey) {
+			limiter.Refund(key, limit)
+		}
+	}
+}
+
+// rateLimitClient names the client the request counts against
+func rateLimitClient(c *gin.Context) string {
+	if identity, ok := domain.IdentityFromContext(c.Request.Context()); ok {
+		if identity.APIKeyID != "" {
+			return "key:" + identity.APIKeyID
+		}
+		return "user:" + identity.UserID
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// seconds rounds d up to whole seconds, as the rate limit headers count in seconds
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-ozzo/ozzo-validation/v4"
+	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type ReactionHandler struct {
+	service domain.ReactionService
+	logger  *zap.Logger
+}
+
+func NewReactionHandler(service domain.ReactionService, logger *zap.Logger) *ReactionHandler {
+	logger = logger.With(zap.String("package", "handlers"))
+
+	return &ReactionHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// React adds the caller's reaction of the kind path parameter to the post in the id path parameter
+func (h *ReactionHandler) React(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "React"))
+
+	reaction, ok := h.reactionParams(c, logr)
+	if !ok {
+		return
+	}
+
+	reactions, err := h.service.React(c.Request.Context(), reaction)
+	if err != nil {
+		if errors.Is(err, domain.ErrPostNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("Reacted to post successfully", zap.String("postId", reaction.PostID), zap.String("kind", string(reaction.Kind)))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "Reacted to post successfully",
+		Data:    reactions,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Unreact takes the caller's reaction of the kind path parameter off the post in the id path parameter
+func (h *ReactionHandler) Unreact(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "Unreact"))
+
+	reaction, ok := h.reactionParams(c, logr)
+	if !ok {
+		return
+	}
+
+	reactions, err := h.service.Unreact(c.Request.Context(), reaction)
+	if err != nil {
+		if errors.Is(err, domain.ErrPostNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("Reaction removed successfully", zap.String("postId", reaction.PostID), zap.String("kind", string(reaction.Kind)))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "Reaction removed successfully",
+		Data:    reactions,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// reactionParams reads the caller's reaction from the path, responding with an error when there is no caller or
+// the kind is not supported
+func (h *ReactionHandler) reactionParams(c *gin.Context, logr *zap.Logger) (domain.Reaction, bool) {
+	identity, ok := domain.IdentityFromContext(c.Request.Context())
+	if !ok {
+		logr.Error("Unauthenticated request")
+		c.JSON(http.StatusUnauthorized, domain.ErrUnauthenticated)
+		return domain.Reaction{}, false
+	}
+
+	kind := domain.ReactionKind(c.Param("kind"))
+	if !kind.Valid() {
+		logr.Info("Invalid reaction kind", zap.String("kind", string(kind)))
+
+		names := make([]string, len(domain.ReactionKinds))
+		for i, k := range domain.ReactionKinds {
+			names[i] = string(k)
+		}
+
+		verrs := validation.Errors{"kind": fmt.Errorf("must be one of %s", strings.Join(names, ", "))}
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+		return domain.Reaction{}, false
+	}
+
+	return domain.Reaction{PostID: c.Param("id"), UserID: identity.UserID, Kind: kind}, true
+}
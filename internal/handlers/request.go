@@ -2,8 +2,24 @@ package handlers
 
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
 	"github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+const (
+	// bcrypt only hashes the first 72 bytes of a password
+	minPasswordLength = 8
+	maxPasswordLength = 72
+
+	maxAPIKeyNameLength = 100
 )
 
 // Users
@@ -11,10 +27,12 @@ type createUserRequest struct {
 	Firstname string `json:"firstname"`
 	Lastname  string `json:"lastname"`
 	Email     string `json:"email"`
+	Password  string `json:"password"`
 	Street    string `json:"street"`
 	City      string `json:"city"`
 	State     string `json:"state"`
 	Zipcode   string `json:"zipcode"`
+	Handle    string `json:"handle"`
 }
 
 func (r createUserRequest) Validate() error {
@@ -22,25 +40,496 @@ func (r createUserRequest) Validate() error {
 		validation.Field(&r.Firstname, validation.Required, validation.Length(2, 0)),
 		validation.Field(&r.Lastname, validation.Required, validation.Length(2, 0)),
 		validation.Field(&r.Email, validation.Required, is.Email),
+		validation.Field(&r.Password, validation.Required, validation.RuneLength(minPasswordLength, 0), validation.Length(0, maxPasswordLength)),
 		validation.Field(&r.Street, validation.Required),
 		validation.Field(&r.City, validation.Required),
 		validation.Field(&r.State, validation.Required),
 		validation.Field(&r.Zipcode, validation.Required),
+		validation.Field(&r.Handle, handleRules...),
 	)
 }
 
+// handleRules validate a user handle, an empty handle is no handle
+var handleRules = []validation.Rule{
+	validation.RuneLength(domain.MinHandleLength, domain.MaxHandleLength),
+	validation.Match(domain.HandlePattern).Error("must only hold letters, digits or underscores"),
+}
+
+// replaceUserRequest is a complete user, the password is not part of it. Leaving the handle out removes it
+type replaceUserRequest struct {
+	Firstname string `json:"firstname"`
+	Lastname  string `json:"lastname"`
+	Email     string `json:"email"`
+	Street    string `json:"street"`
+	City      string `json:"city"`
+	State     string `json:"state"`
+	Zipcode   string `json:"zipcode"`
+	Handle    string `json:"handle"`
+}
+
+func (r replaceUserRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Firstname, validation.Required, validation.Length(2, 0)),
+		validation.Field(&r.Lastname, validation.Required, validation.Length(2, 0)),
+		validation.Field(&r.Email, validation.Required, is.Email),
+		validation.Field(&r.Street, validation.Required),
+		validation.Field(&r.City, validation.Required),
+		validation.Field(&r.State, validation.Required),
+		validation.Field(&r.Zipcode, validation.Required),
+		validation.Field(&r.Handle, handleRules...),
+	)
+}
+
+// updateUserRequest applies replaceUserRequest's rules to the fields that are present, an empty handle removes it
+type updateUserRequest struct {
+	Firstname *string `json:"firstname"`
+	Lastname  *string `json:"lastname"`
+	Email     *string `json:"email"`
+	Street    *string `json:"street"`
+	City      *string `json:"city"`
+	State     *string `json:"state"`
+	Zipcode   *string `json:"zipcode"`
+	Handle    *string `json:"handle"`
+}
+
+func (r updateUserRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Firstname, validation.NilOrNotEmpty, validation.Length(2, 0)),
+		validation.Field(&r.Lastname, validation.NilOrNotEmpty, validation.Length(2, 0)),
+		validation.Field(&r.Email, validation.NilOrNotEmpty, is.Email),
+		validation.Field(&r.Street, validation.NilOrNotEmpty),
+		validation.Field(&r.City, validation.NilOrNotEmpty),
+		validation.Field(&r.State, validation.NilOrNotEmpty),
+		validation.Field(&r.Zipcode, validation.NilOrNotEmpty),
+		validation.Field(&r.Handle, handleRules...),
+	)
+}
+
+func (r updateUserRequest) isEmpty() bool {
+	return r.Firstname == nil && r.Lastname == nil && r.Email == nil &&
+		r.Street == nil && r.City == nil && r.State == nil && r.Zipcode == nil && r.Handle == nil
+}
+
+type deleteUserRequest struct {
+	Policy string `json:"policy"`
+}
+
+func (r deleteUserRequest) Validate() error {
+	policies := make([]any, 0, len(domain.UserDeletePolicies))
+	for _, policy := range domain.UserDeletePolicies {
+		policies = append(policies, string(policy))
+	}
+
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Policy, validation.Required, validation.In(policies...)),
+	)
+}
+
+// listUsersRequest holds the pagination and sort parameters of the user listing, filters are read by newUserQuery
+type listUsersRequest struct {
+	pageRequest
+	SortBy string `form:"sortBy" json:"sortBy"`
+	Order  string `form:"order" json:"order"`
+	// IncludeDeleted lists soft deleted users too
+	IncludeDeleted bool `form:"includeDeleted" json:"includeDeleted"`
+}
+
+func (r listUsersRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.pageRequest),
+		validation.Field(&r.SortBy, validation.In(string(domain.UserSortCreatedAt), string(domain.UserSortLastname))),
+		validation.Field(&r.Order, validation.In(sortAsc, sortDesc)),
+	)
+}
+
+// listUsersParams are the query parameters of the user listing besides its filters
+var listUsersParams = []string{"pageNumber", "pageSize", "cursor", "limit", "sortBy", "order", "includeDeleted"}
+
+// userFilterOps lists the operators of each user filter. Filters are given as field[op]=value,
+// or as field=value for filters with a single operator or an eq operator
+var userFilterOps = map[string][]string{
+	"city":        {filterEq},
+	"state":       {filterEq},
+	"zipcode":     {filterEq, filterPrefix},
+	"emailDomain": {filterEq},
+	"name":        {filterPrefix},
+	"createdAt":   {filterGte, filterLte},
+}
+
 // Posts
+// createPostRequest is a new post, its author is the authenticated caller. Tags are added to the #hashtags of the
+// body
 type createPostRequest struct {
-	UserID string `json:"userId"`
-	Title  string `json:"title"`
-	Body   string `json:"body"`
+	Title string   `json:"title"`
+	Body  string   `json:"body"`
+	Tags  []string `json:"tags"`
 }
 
 func (r createPostRequest) Validate() error {
 	return validation.ValidateStruct(&r,
-		validation.Field(&r.UserID, validation.Required),
 		validation.Field(&r.Title, validation.Required),
+		validation.Field(&r.Body, validation.Required, validation.RuneLength(0, maxPostBodyLength)),
+		validation.Field(&r.Tags, tagsRules...),
+	)
+}
+
+// updatePostRequest applies createPostRequest's rules to the fields that are present
+type updatePostRequest struct {
+	Title *string   `json:"title"`
+	Body  *string   `json:"body"`
+	Tags  *[]string `json:"tags"`
+}
+
+func (r updatePostRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Title, validation.NilOrNotEmpty),
+		validation.Field(&r.Body, validation.NilOrNotEmpty, validation.RuneLength(0, maxPostBodyLength)),
+		// Each only ranges over slices, not pointers to them
+		validation.Field(&r.Tags, validation.By(func(interface{}) error {
+			if r.Tags == nil {
+				return nil
+			}
+			return validation.Validate(*r.Tags, tagsRules...)
+		})),
+	)
+}
+
+// tagsRules are the rules of the tags given with a post
+var tagsRules = []validation.Rule{validation.Length(0, domain.MaxPostTags), validation.Each(validation.By(validateTag))}
+
+// errInvalidTag describes the tag names domain.NormalizeTag accepts
+var errInvalidTag = fmt.Errorf("must be at most %d letters, digits or underscores, not only digits", domain.MaxTagLength)
+
+// validateTag accepts a tag name, with or without its leading #
+func validateTag(value interface{}) error {
+	s, _ := value.(string)
+	if s == "" {
+		return nil
+	}
+	if _, ok := domain.NormalizeTag(s); !ok {
+		return errInvalidTag
+	}
+	return nil
+}
+
+type diffPostRevisionsRequest struct {
+	From int `form:"from" json:"from"`
+	To   int `form:"to" json:"to"`
+}
+
+func (r diffPostRevisionsRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.From, validation.Required, validation.Min(1)),
+		validation.Field(&r.To, validation.Required, validation.Min(1)),
+	)
+}
+
+const (
+	defaultPageNumber = 1
+	defaultPageSize   = 10
+	maxPageSize       = 100
+
+	maxSearchLength = 256
+
+	// maxPostBodyLength bounds post bodies, in characters, and with them the lines revisions are diffed on
+	maxPostBodyLength = 10000
+
+	sortAsc  = "asc"
+	sortDesc = "desc"
+
+	filterEq     = "eq"
+	filterPrefix = "prefix"
+	filterGte    = "gte"
+	filterLte    = "lte"
+)
+
+// pageRequest holds the paging of a listing, pages are selected either by pageNumber and pageSize, or by cursor
+// and limit
+type pageRequest struct {
+	PageNumber int    `form:"pageNumber" json:"pageNumber"`
+	PageSize   int    `form:"pageSize" json:"pageSize"`
+	Cursor     string `form:"cursor" json:"cursor"`
+	Limit      int    `form:"limit" json:"limit"`
+}
+
+func (r pageRequest) Validate() error {
+	keyset := r.Cursor != "" || r.Limit != 0
+	notWithKeyset := validation.When(keyset, validation.Empty.Error("cannot be combined with cursor or limit"))
+
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.PageNumber, validation.Min(1), notWithKeyset),
+		validation.Field(&r.PageSize, validation.Min(1), validation.Max(maxPageSize), notWithKeyset),
+		validation.Field(&r.Limit, validation.Min(1), validation.Max(maxPageSize)),
+	)
+}
+
+// toPageRequest returns the page asked for, the first page of defaultPageSize items by default
+func (r pageRequest) toPageRequest() domain.PageRequest {
+	if r.Cursor != "" || r.Limit != 0 {
+		return domain.PageRequest{Cursor: r.Cursor, PageSize: cmp.Or(r.Limit, defaultPageSize), Keyset: true}
+	}
+	return domain.PageRequest{PageNumber: cmp.Or(r.PageNumber, defaultPageNumber), PageSize: cmp.Or(r.PageSize, defaultPageSize)}
+}
+
+// listPostsRequest holds the query parameters of post listings
+type listPostsRequest struct {
+	pageRequest
+	SortBy      string `form:"sortBy" json:"sortBy"`
+	Order       string `form:"order" json:"order"`
+	CreatedFrom string `form:"createdFrom" json:"createdFrom"`
+	CreatedTo   string `form:"createdTo" json:"createdTo"`
+	Tag         string `form:"tag" json:"tag"`
+	// IncludeDeleted lists soft deleted posts too
+	IncludeDeleted bool `form:"includeDeleted" json:"includeDeleted"`
+}
+
+func (r listPostsRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.pageRequest),
+		validation.Field(&r.SortBy, validation.In(string(domain.PostSortCreatedAt), string(domain.PostSortTitle))),
+		validation.Field(&r.Order, validation.In(sortAsc, sortDesc)),
+		validation.Field(&r.CreatedFrom, validation.By(validateTimeParam)),
+		validation.Field(&r.CreatedTo, validation.By(validateTimeParam)),
+		validation.Field(&r.Tag, validation.By(validateTag)),
+	)
+}
+
+// validateTimeParam accepts an RFC 3339 timestamp or a plain date
+func validateTimeParam(value interface{}) error {
+	s, _ := value.(string)
+	if s == "" {
+		return nil
+	}
+	if _, _, err := parseTimeParam(s); err != nil {
+		return errors.New("must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	}
+	return nil
+}
+
+// parseTimeParamEnd parses an upper time bound, a plain date includes the whole of that day
+func parseTimeParamEnd(s string) (time.Time, error) {
+	t, dateOnly, err := parseTimeParam(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if dateOnly {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+// parseTimeParam parses an RFC 3339 timestamp or a plain date, reporting which one it was. Plain dates are UTC
+// days, whatever the time zone of the server
+func parseTimeParam(s string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+
+	t, err := time.ParseInLocation(time.DateOnly, s, time.UTC)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return t, true, nil
+}
+
+type searchPostsRequest struct {
+	Query      string `form:"q" json:"q"`
+	PageNumber int    `form:"pageNumber" json:"pageNumber"`
+	PageSize   int    `form:"pageSize" json:"pageSize"`
+}
+
+func (r searchPostsRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Query, validation.Required, validation.RuneLength(1, maxSearchLength)),
+		validation.Field(&r.PageNumber, validation.Min(1)),
+		validation.Field(&r.PageSize, validation.Min(1), validation.Max(maxPageSize)),
+	)
+}
+
+// feedRequest holds the paging of the caller's feed, which is only paged by cursor
+type feedRequest struct {
+	Cursor string `form:"cursor" json:"cursor"`
+	Limit  int    `form:"limit" json:"limit"`
+}
+
+func (r feedRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Limit, validation.Min(1), validation.Max(maxPageSize)),
+	)
+}
+
+// Auth
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (r loginRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Email, validation.Required),
+		validation.Field(&r.Password, validation.Required),
+	)
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+func (r refreshRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.RefreshToken, validation.Required),
+	)
+}
+
+// logoutRequest ends the caller's session, or every session of theirs when All is set
+type logoutRequest struct {
+	All bool `json:"all"`
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+func (r forgotPasswordRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Email, validation.Required, is.Email),
+	)
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (r resetPasswordRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Token, validation.Required),
+		validation.Field(&r.Password, validation.Required, validation.RuneLength(minPasswordLength, 0), validation.Length(0, maxPasswordLength)),
+	)
+}
+
+// verifyEmailRequest carries the token of the link emailed to the user
+type verifyEmailRequest struct {
+	Token string `form:"token" json:"token"`
+}
+
+func (r verifyEmailRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Token, validation.Required),
+	)
+}
+
+// API keys
+type createAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// UserID is the user the key acts as, the admin minting it when left out
+	UserID string `json:"userId"`
+}
+
+func (r createAPIKeyRequest) Validate() error {
+	scopes := make([]interface{}, 0, len(domain.ScopePermissions))
+	for scope := range domain.ScopePermissions {
+		scopes = append(scopes, string(scope))
+	}
+
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Name, validation.Required, validation.RuneLength(1, maxAPIKeyNameLength)),
+		validation.Field(&r.Scopes, validation.Required, validation.Each(validation.In(scopes...)), validation.By(validateReadOnlyScope)),
+	)
+}
+
+// validateReadOnlyScope refuses read-only alongside scopes that allow writes
+func validateReadOnlyScope(value interface{}) error {
+	scopes, _ := value.([]string)
+	for _, scope := range scopes {
+		if scope == string(domain.ScopeReadOnly) && len(scopes) > 1 {
+			return errors.New("read-only cannot be combined with other scopes")
+		}
+	}
+	return nil
+}
+
+// Comments
+// createCommentRequest is a new comment, its author is the authenticated caller and its post is in the path
+type createCommentRequest struct {
+	Body string `json:"body"`
+}
+
+func (r createCommentRequest) Validate() error {
+	return validation.ValidateStruct(&r,
 		validation.Field(&r.Body, validation.Required),
 	)
 }
 
+// listCommentsRequest holds the paging and order of the comments of a post
+type listCommentsRequest struct {
+	pageRequest
+	Order string `form:"order" json:"order"`
+}
+
+func (r listCommentsRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.pageRequest),
+		validation.Field(&r.Order, validation.In(sortAsc, sortDesc)),
+	)
+}
+
+// Tags
+// tagPrefixPattern matches the start of a tag name
+var tagPrefixPattern = regexp.MustCompile(`^[\pL\pN_]*$`)
+
+// listTagsRequest holds the paging of the tags in use, and the prefix they are narrowed down to
+type listTagsRequest struct {
+	Prefix     string `form:"prefix" json:"prefix"`
+	PageNumber int    `form:"pageNumber" json:"pageNumber"`
+	PageSize   int    `form:"pageSize" json:"pageSize"`
+}
+
+func (r listTagsRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Prefix, validation.RuneLength(0, domain.MaxTagLength), validation.Match(tagPrefixPattern).Error("must only hold letters, digits or underscores")),
+		validation.Field(&r.PageNumber, validation.Min(1)),
+		validation.Field(&r.PageSize, validation.Min(1), validation.Max(maxPageSize)),
+	)
+}
+
+// Follows
+// listFollowsRequest holds the paging of the followers of a user or of the users they follow
+type listFollowsRequest struct {
+	pageRequest
+}
+
+// Audit log
+// listAuditEventsRequest holds the filters and paging of the audit log
+type listAuditEventsRequest struct {
+	pageRequest
+	Order       string `form:"order" json:"order"`
+	ActorID     string `form:"actorId" json:"actorId"`
+	Action      string `form:"action" json:"action"`
+	TargetType  string `form:"targetType" json:"targetType"`
+	TargetID    string `form:"targetId" json:"targetId"`
+	RequestID   string `form:"requestId" json:"requestId"`
+	CreatedFrom string `form:"createdFrom" json:"createdFrom"`
+	CreatedTo   string `form:"createdTo" json:"createdTo"`
+}
+
+func (r listAuditEventsRequest) Validate() error {
+	actions := make([]interface{}, 0, len(domain.AuditActions))
+	for _, action := range domain.AuditActions {
+		actions = append(actions, string(action))
+	}
+	targetTypes := make([]interface{}, 0, len(domain.AuditTargetTypes))
+	for _, targetType := range domain.AuditTargetTypes {
+		targetTypes = append(targetTypes, targetType)
+	}
+
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.pageRequest),
+		validation.Field(&r.Order, validation.In(sortAsc, sortDesc)),
+		validation.Field(&r.Action, validation.In(actions...)),
+		validation.Field(&r.TargetType, validation.In(targetTypes...)),
+		validation.Field(&r.CreatedFrom, validation.By(validateTimeParam)),
+		validation.Field(&r.CreatedTo, validation.By(validateTimeParam)),
+	)
+}
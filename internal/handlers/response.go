@@ -1,6 +1,11 @@
 package handlers
 
-import "github.com/victor-nach/postr-backend/internal/domain"
+import (
+	"errors"
+	"net/http"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
 
 const (
 	successStatus = "success"
@@ -17,3 +22,22 @@ type APIResponse struct {
 type Count struct {
 	Count int `json:"count"`
 }
+
+// CreatedAPIKey is a newly minted API key along with the key itself, which is never shown again
+type CreatedAPIKey struct {
+	domain.APIKey
+	Key string `json:"key"`
+}
+
+// constraintStatus returns the status code for an error raised by a violated database constraint, a clash
+// with existing data is a conflict while a missing value or a reference to missing data cannot be processed
+func constraintStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, domain.ErrEmailAlreadyRegistered), errors.Is(err, domain.ErrHandleTaken),
+		errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict, true
+	case errors.Is(err, domain.ErrInvalidReference), errors.Is(err, domain.ErrMissingValue):
+		return http.StatusUnprocessableEntity, true
+	}
+	return 0, false
+}
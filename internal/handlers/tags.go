@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-ozzo/ozzo-validation/v4"
+	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type TagHandler struct {
+	service domain.TagService
+	logger  *zap.Logger
+}
+
+func NewTagHandler(service domain.TagService, logger *zap.Logger) *TagHandler {
+	logger = logger.With(zap.String("package", "handlers"))
+
+	return &TagHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListTags serves a page of the tags in use with the number of posts carrying them, most used first
+func (h *TagHandler) ListTags(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "ListTags"))
+
+	var req listTagsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logr.Error("Error binding query", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	// Tag names are stored in lower case, without their #
+	req.Prefix = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(req.Prefix), "#"))
+
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	query := domain.TagQuery{
+		Prefix: req.Prefix,
+		Page:   pageRequest{PageNumber: req.PageNumber, PageSize: req.PageSize}.toPageRequest(),
+	}
+
+	tags, err := h.service.List(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrInternalServer)
+		return
+	}
+
+	logr.Info("Tags listed successfully", zap.String("prefix", query.Prefix), zap.Int("count", len(tags.Tags)))
+
+	resp := APIResponse{
+		Status:     successStatus,
+		Message:    "Tags listed successfully",
+		Pagination: &tags.Pagination,
+		Data:       tags.Tags,
+	}
+	c.JSON(http.StatusOK, resp)
+}
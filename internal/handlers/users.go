@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
-	"strconv"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,16 +18,19 @@ import (
 )
 
 type UserHandler struct {
-	service domain.UserService
-	logger  *zap.Logger
+	service      domain.UserService
+	deletePolicy domain.UserDeletePolicy
+	logger       *zap.Logger
 }
 
-func NewUserHandler(service domain.UserService, logger *zap.Logger) *UserHandler {
+// NewUserHandler creates a UserHandler, deletePolicy is used when a delete request does not specify one
+func NewUserHandler(service domain.UserService, deletePolicy domain.UserDeletePolicy, logger *zap.Logger) *UserHandler {
 	logger = logger.With(zap.String("package", "handlers"))
 
 	return &UserHandler{
-		service: service,
-		logger:  logger,
+		service:      service,
+		deletePolicy: deletePolicy,
+		logger:       logger,
 	}
 }
 
@@ -62,8 +68,14 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		Zipcode:   req.Zipcode,
 		CreatedAt: time.Now(),
 	}
+	domain.UserUpdate{Handle: &req.Handle}.Apply(user)
+
+	if err := h.service.Create(c.Request.Context(), user, req.Password); err != nil {
+		if status, ok := constraintStatus(err); ok {
+			c.JSON(status, err)
+			return
+		}
 
-	if err := h.service.Create(c.Request.Context(), user); err != nil {
 		c.JSON(http.StatusInternalServerError, err)
 		return
 	}
@@ -81,17 +93,54 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 func (h *UserHandler) ListUsers(c *gin.Context) {
 	logr := h.logger.With(zap.String("method", "ListUsers"))
 
-	pageNumber, err := strconv.Atoi(c.Query("pageNumber"))
-	if err != nil {
-		pageNumber = 1 // default
+	var req listUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logr.Error("Error binding query", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
 	}
-	pageSize, err := strconv.Atoi(c.Query("pageSize"))
+
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	query, err := newUserQuery(c.Request.URL.Query())
 	if err != nil {
-		pageSize = 10 // default
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Invalid filters", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Invalid filters", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	query.SortBy = domain.UserSortCreatedAt
+	if req.SortBy != "" {
+		query.SortBy = domain.UserSortField(req.SortBy)
 	}
+	query.SortDesc = req.Order == sortDesc
+	query.IncludeDeleted = req.IncludeDeleted
+
+	query.Page = req.toPageRequest()
 
-	paginatedUsers, err := h.service.List(c.Request.Context(), pageNumber, pageSize)
+	paginatedUsers, err := h.service.List(c.Request.Context(), query)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, err)
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, err)
 		return
 	}
@@ -107,6 +156,95 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// newUserQuery reads the filters of the user listing from the query parameters. Parameters that are
+// neither listing parameters nor known filters with a supported operator are reported as field errors
+func newUserQuery(params url.Values) (domain.UserQuery, error) {
+	var query domain.UserQuery
+	verrs := validation.Errors{}
+
+	for param, values := range params {
+		if slices.Contains(listUsersParams, param) {
+			continue
+		}
+
+		field, op, ok := parseFilterParam(param)
+		if !ok {
+			verrs[param] = errors.New("is not a valid filter, use field[op]=value")
+			continue
+		}
+
+		ops, known := userFilterOps[field]
+		if !known {
+			verrs[param] = errors.New("is not a known filter")
+			continue
+		}
+
+		if op == "" {
+			if len(ops) > 1 && !slices.Contains(ops, filterEq) {
+				verrs[param] = fmt.Errorf("needs an operator, one of %s", strings.Join(ops, ", "))
+				continue
+			}
+			op = ops[0]
+		}
+		if !slices.Contains(ops, op) {
+			verrs[param] = fmt.Errorf("does not support the %s operator, use one of %s", op, strings.Join(ops, ", "))
+			continue
+		}
+
+		value := strings.TrimSpace(values[0])
+		if value == "" {
+			verrs[param] = errors.New("cannot be blank")
+			continue
+		}
+
+		var err error
+		switch field {
+		case "city":
+			query.City = value
+		case "state":
+			query.State = value
+		case "zipcode":
+			if op == filterPrefix {
+				query.ZipcodePrefix = value
+			} else {
+				query.Zipcode = value
+			}
+		case "emailDomain":
+			query.EmailDomain = strings.TrimPrefix(value, "@")
+		case "name":
+			query.NamePrefix = value
+		case "createdAt":
+			if op == filterGte {
+				query.CreatedFrom, _, err = parseTimeParam(value)
+			} else {
+				query.CreatedTo, err = parseTimeParamEnd(value)
+			}
+		}
+		if err != nil {
+			verrs[param] = errors.New("must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+		}
+	}
+
+	if len(verrs) > 0 {
+		return domain.UserQuery{}, verrs
+	}
+	return query, nil
+}
+
+// parseFilterParam splits a filter parameter of the form field[op] or field
+func parseFilterParam(param string) (string, string, bool) {
+	field, rest, found := strings.Cut(param, "[")
+	if !found {
+		return param, "", true
+	}
+
+	op, ok := strings.CutSuffix(rest, "]")
+	if !ok || op == "" || strings.ContainsAny(op, "[]") {
+		return "", "", false
+	}
+	return field, op, true
+}
+
 func (h *UserHandler) GetUserByID(c *gin.Context) {
 	logr := h.logger.With(zap.String("method", "GetUserByID"))
 
@@ -133,6 +271,182 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// UpdateUser applies a partial update, only the fields present in the body are changed
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "UpdateUser"))
+
+	var req updateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logr.Error("Error binding JSON", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	if req.isEmpty() {
+		logr.Error("No fields to update")
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	// Validate request body
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	update := domain.UserUpdate{
+		Firstname: req.Firstname,
+		Lastname:  req.Lastname,
+		Email:     req.Email,
+		Street:    req.Street,
+		City:      req.City,
+		State:     req.State,
+		Zipcode:   req.Zipcode,
+		Handle:    req.Handle,
+	}
+
+	h.update(c, logr, update)
+}
+
+// ReplaceUser replaces every editable field, so the body must be a complete user
+func (h *UserHandler) ReplaceUser(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "ReplaceUser"))
+
+	var req replaceUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logr.Error("Error binding JSON", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	// Validate request body
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	update := domain.UserUpdate{
+		Firstname: &req.Firstname,
+		Lastname:  &req.Lastname,
+		Email:     &req.Email,
+		Street:    &req.Street,
+		City:      &req.City,
+		State:     &req.State,
+		Zipcode:   &req.Zipcode,
+		Handle:    &req.Handle,
+	}
+
+	h.update(c, logr, update)
+}
+
+func (h *UserHandler) update(c *gin.Context, logr *zap.Logger, update domain.UserUpdate) {
+	id := c.Param("id")
+	user, err := h.service.Update(c.Request.Context(), id, update)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		if status, ok := constraintStatus(err); ok {
+			c.JSON(status, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("User updated successfully", zap.Any("user", user))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "User updated successfully",
+		Data:    user,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteUser deletes a user, the optional policy query parameter decides what happens to their posts
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "DeleteUser"))
+
+	req := deleteUserRequest{
+		Policy: c.DefaultQuery("policy", string(h.deletePolicy)),
+	}
+
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	id := c.Param("id")
+	if err := h.service.Delete(c.Request.Context(), id, domain.UserDeletePolicy(req.Policy)); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrUserHasPosts) {
+			c.JSON(http.StatusConflict, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("User deleted successfully", zap.String("id", id), zap.String("policy", req.Policy))
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreUser undoes the delete of a user, along with the posts deleted with them
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "RestoreUser"))
+
+	id := c.Param("id")
+	user, err := h.service.Restore(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("User restored successfully", zap.String("id", id))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "User restored successfully",
+		Data:    user,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 func (h *UserHandler) CountUsers(c *gin.Context) {
 	logr := h.logger.With(zap.String("method", "CountUsers"))
 
@@ -153,3 +467,113 @@ func (h *UserHandler) CountUsers(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, resp)
 }
+
+// ListUserRoles returns the roles granted to the user
+func (h *UserHandler) ListUserRoles(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "ListUserRoles"))
+
+	id := c.Param("id")
+	roles, err := h.service.ListRoles(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("User roles listed successfully", zap.String("id", id))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "User roles listed successfully",
+		Data:    roles,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GrantUserRole grants the role named in the path to the user, granting a role they already have is a no-op
+func (h *UserHandler) GrantUserRole(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "GrantUserRole"))
+
+	id := c.Param("id")
+	role, ok := roleParam(c)
+	if !ok {
+		logr.Info("Invalid role", zap.String("role", c.Param("role")))
+		return
+	}
+
+	roles, err := h.service.GrantRole(c.Request.Context(), id, role)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("User role granted successfully", zap.String("id", id), zap.String("role", string(role)))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "User role granted successfully",
+		Data:    roles,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RevokeUserRole takes the role named in the path away from the user
+func (h *UserHandler) RevokeUserRole(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "RevokeUserRole"))
+
+	id := c.Param("id")
+	role, ok := roleParam(c)
+	if !ok {
+		logr.Info("Invalid role", zap.String("role", c.Param("role")))
+		return
+	}
+
+	roles, err := h.service.RevokeRole(c.Request.Context(), id, role)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+		if errors.Is(err, domain.ErrLastAdmin) {
+			c.JSON(http.StatusConflict, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("User role revoked successfully", zap.String("id", id), zap.String("role", string(role)))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "User role revoked successfully",
+		Data:    roles,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// roleParam reads the role path parameter, responding with a bad request when it is not a supported role
+func roleParam(c *gin.Context) (domain.Role, bool) {
+	role := domain.Role(c.Param("role"))
+	if !role.Valid() {
+		names := make([]string, len(domain.Roles))
+		for i, r := range domain.Roles {
+			names[i] = string(r)
+		}
+
+		verrs := validation.Errors{"role": fmt.Errorf("must be one of %s", strings.Join(names, ", "))}
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+		return "", false
+	}
+	return role, true
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-ozzo/ozzo-validation/v4"
+	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type VerificationHandler struct {
+	service domain.VerificationService
+	logger  *zap.Logger
+}
+
+func NewVerificationHandler(service domain.VerificationService, logger *zap.Logger) *VerificationHandler {
+	logger = logger.With(zap.String("package", "handlers"))
+
+	return &VerificationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// VerifyEmail verifies the email the token in the emailed link was sent to
+func (h *VerificationHandler) VerifyEmail(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "VerifyEmail"))
+
+	var req verifyEmailRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logr.Error("Error binding query", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		if verrs, ok := err.(validation.Errors); ok {
+			logr.Error("Validation errors", zap.Any("errors", verrs))
+			c.JSON(http.StatusBadRequest, domain.ErrInvalidInput.WithFieldErrors(verrs))
+			return
+		}
+
+		logr.Error("Validation error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, domain.ErrInvalidInput)
+		return
+	}
+
+	user, err := h.service.VerifyEmail(c.Request.Context(), req.Token)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidVerificationToken) {
+			c.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("Email verified successfully", zap.String("user_id", user.ID))
+
+	resp := APIResponse{
+		Status:  successStatus,
+		Message: "Email verified successfully",
+		Data:    user,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ResendVerification emails the caller a new verification link
+func (h *VerificationHandler) ResendVerification(c *gin.Context) {
+	logr := h.logger.With(zap.String("method", "ResendVerification"))
+
+	identity, ok := domain.IdentityFromContext(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, domain.ErrUnauthenticated)
+		return
+	}
+
+	if err := h.service.SendVerification(c.Request.Context(), identity.UserID); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, err)
+			return
+		}
+
+		if errors.Is(err, domain.ErrEmailAlreadyVerified) {
+			c.JSON(http.StatusConflict, err)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+
+	logr.Info("Verification email resent successfully", zap.String("user_id", identity.UserID))
+
+	c.Status(http.StatusNoContent)
+}
@@ -15,7 +15,7 @@ import (
 // New initialzes the sqlite db and applies the latest migrations
 func New() (*gorm.DB, *sql.DB, error) {
 	dbFile := filepath.Join(".", "data", "app.db")
-	dsn := fmt.Sprintf("file:%s?mode=rwc&cache=shared", dbFile)
+	dsn := fmt.Sprintf("file:%s?mode=rwc&cache=shared&_pragma=foreign_keys(1)", dbFile)
 
 	sqlDB, err := sql.Open("sqlite", dsn)
 	if err != nil {
@@ -26,7 +26,7 @@ func New() (*gorm.DB, *sql.DB, error) {
 		return nil, nil, fmt.Errorf("failed to ping db: %w", err)
 	}
 
-	gormDB, err := gorm.Open(sqlite.Dialector{Conn: sqlDB}, &gorm.Config{})
+	gormDB, err := gorm.Open(sqlite.Dialector{Conn: UTC(sqlDB)}, &gorm.Config{})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to open gorm db: %w", err)
 	}
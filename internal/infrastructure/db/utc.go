@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// utcPool hands every time given to a statement to the driver in UTC. The sqlite driver stores times as
+// text with their own offset, so times from different zones, or from both sides of a daylight saving
+// change, would not compare or sort in time order
+type utcPool struct {
+	gorm.ConnPool
+}
+
+// UTC wraps pool so every time it stores or compares with is in UTC
+func UTC(pool gorm.ConnPool) gorm.ConnPool {
+	return utcPool{ConnPool: pool}
+}
+
+func (p utcPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return p.ConnPool.ExecContext(ctx, query, utcArgs(args)...)
+}
+
+func (p utcPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return p.ConnPool.QueryContext(ctx, query, utcArgs(args)...)
+}
+
+func (p utcPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return p.ConnPool.QueryRowContext(ctx, query, utcArgs(args)...)
+}
+
+// BeginTx starts a transaction whose statements are wrapped as well
+func (p utcPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	var (
+		tx  gorm.ConnPool
+		err error
+	)
+	switch beginner := p.ConnPool.(type) {
+	case gorm.TxBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	case gorm.ConnPoolBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	default:
+		return nil, gorm.ErrInvalidTransaction
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	committer, ok := tx.(gorm.TxCommitter)
+	if !ok {
+		return nil, gorm.ErrInvalidTransaction
+	}
+	return &utcTx{utcPool: utcPool{ConnPool: tx}, committer: committer}, nil
+}
+
+// GetDBConn returns the wrapped *sql.DB, for gorm's DB()
+func (p utcPool) GetDBConn() (*sql.DB, error) {
+	switch pool := p.ConnPool.(type) {
+	case *sql.DB:
+		return pool, nil
+	case gorm.GetDBConnector:
+		return pool.GetDBConn()
+	}
+	return nil, gorm.ErrInvalidDB
+}
+
+type utcTx struct {
+	utcPool
+	committer gorm.TxCommitter
+}
+
+func (t *utcTx) Commit() error {
+	return t.committer.Commit()
+}
+
+func (t *utcTx) Rollback() error {
+	return t.committer.Rollback()
+}
+
+// utcArgs returns args with the times among them, including those of valuers such as gorm.DeletedAt, in UTC
+func utcArgs(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		converted[i] = arg
+
+		switch v := arg.(type) {
+		case time.Time:
+			converted[i] = v.UTC()
+		case *time.Time:
+			if v != nil {
+				converted[i] = v.UTC()
+			}
+		case driver.Valuer:
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+				continue
+			}
+			if value, err := v.Value(); err == nil {
+				if t, ok := value.(time.Time); ok {
+					converted[i] = t.UTC()
+				}
+			}
+		}
+	}
+	return converted
+}
@@ -0,0 +1,104 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+// outbox "sends" emails by writing each one as an .eml file to a directory, so the app works offline and
+// emails can be read with any mail client
+type outbox struct {
+	dir  string
+	from *mail.Address
+	now  func() time.Time
+}
+
+// NewOutbox creates a mailer writing to dir, which is created if missing, from is the sender of every email
+func NewOutbox(dir string, from string) (*outbox, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender %q: %w", from, err)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create outbox %q: %w", dir, err)
+	}
+
+	return &outbox{dir: dir, from: sender, now: time.Now}, nil
+}
+
+// Send writes the email to the outbox. The file is written under a temporary name and renamed once complete,
+// so whatever picks emails up from the outbox never reads half an email
+func (o *outbox) Send(ctx context.Context, email domain.Email) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	message, err := o.message(email)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(o.dir, ".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(message); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", o.now().UTC().Format("20060102T150405.000000000Z"), uuid.NewString())
+	return os.Rename(tmp.Name(), filepath.Join(o.dir, name))
+}
+
+// message formats the email as a plain text RFC 5322 message
+func (o *outbox) message(email domain.Email) ([]byte, error) {
+	to, err := mail.ParseAddress(email.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient %q: %w", email.To, err)
+	}
+
+	// Line breaks in the subject would let it add headers of its own
+	if strings.ContainsAny(email.Subject, "\r\n") {
+		return nil, fmt.Errorf("invalid subject %q", email.Subject)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", o.from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", o.now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: <%s@%s>\r\n", uuid.NewString(), domainOf(o.from.Address))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+
+	body := strings.ReplaceAll(email.Body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	return b.Bytes(), nil
+}
+
+// domainOf returns the domain part of an email address
+func domainOf(address string) string {
+	at := strings.LastIndex(address, "@")
+	return address[at+1:]
+}
@@ -0,0 +1,71 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+func TestOutbox_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	outbox, err := NewOutbox(dir, "Postr <no-reply@postr.local>")
+	require.NoError(t, err)
+	outbox.now = func() time.Time { return time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC) }
+
+	err = outbox.Send(context.Background(), domain.Email{
+		To:      "alice@example.com",
+		Subject: "Vérifiez your email",
+		Body:    "Hi Alice,\n\nhttp://localhost:8080/auth/verify?token=abc\n",
+	})
+	require.NoError(t, err)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1, "only the finished email should be left in the outbox")
+	assert.Regexp(t, `^20250210T120000\.000000000Z-.+\.eml$`, files[0].Name())
+
+	f, err := os.Open(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	defer f.Close()
+
+	message, err := mail.ReadMessage(f)
+	require.NoError(t, err)
+	assert.Equal(t, `"Postr" <no-reply@postr.local>`, message.Header.Get("From"))
+	assert.Equal(t, "<alice@example.com>", message.Header.Get("To"))
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Vérifiez your email", subject)
+
+	body, err := io.ReadAll(message.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "Hi Alice,\r\n\r\nhttp://localhost:8080/auth/verify?token=abc\r\n", string(body))
+}
+
+func TestOutbox_Send_Invalid(t *testing.T) {
+	_, err := NewOutbox(t.TempDir(), "not an address")
+	require.Error(t, err)
+
+	outbox, err := NewOutbox(t.TempDir(), "no-reply@postr.local")
+	require.NoError(t, err)
+
+	err = outbox.Send(context.Background(), domain.Email{To: "not an address", Subject: "Hi"})
+	require.Error(t, err)
+
+	err = outbox.Send(context.Background(), domain.Email{To: "alice@example.com", Subject: "Hi\r\nBcc: eve@example.com"})
+	require.Error(t, err)
+
+	files, err := os.ReadDir(outbox.dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
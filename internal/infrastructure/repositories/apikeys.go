@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *apiKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// Create inserts the key, constraint violations are returned as domain errors
+func (r *apiKeyRepository) Create(ctx context.Context, apiKey *domain.APIKey) error {
+	return translateError(r.db.WithContext(ctx).Create(apiKey).Error)
+}
+
+// List returns every key, revoked ones included, newest first
+func (r *apiKeyRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+	var apiKeys []domain.APIKey
+	if err := r.db.WithContext(ctx).Order("created_at DESC, id DESC").Find(&apiKeys).Error; err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+// GetByHash returns the key with the hash, gorm.ErrRecordNotFound if there is none
+func (r *apiKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	var apiKey domain.APIKey
+	if err := r.db.WithContext(ctx).First(&apiKey, "key_hash = ?", keyHash).Error; err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// Revoke marks the key revoked at the given time, a revoked key keeps the time it was first revoked.
+// It returns gorm.ErrRecordNotFound if the key does not exist
+func (r *apiKeyRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&domain.APIKey{}).
+		Where("id = ?", id).
+		Update("revoked_at", gorm.Expr("COALESCE(revoked_at, ?)", at))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Touch records that the key was used at the given time
+func (r *apiKeyRepository) Touch(ctx context.Context, id string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
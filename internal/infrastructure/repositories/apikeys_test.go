@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	cleanUsers(t)
+	require.NoError(t, db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&domain.APIKey{}).Error)
+
+	user := domain.User{ID: uuid.NewString(), Firstname: "Key", Lastname: "Owner", Email: "keys@example.com", CreatedAt: time.Now()}
+	require.NoError(t, usersrepo.Create(testCtx, &user))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	older := domain.APIKey{ID: uuid.NewString(), Name: "export", UserID: user.ID, Prefix: "postr_aaaaaa", KeyHash: "hash-1", Scopes: domain.Scopes{domain.ScopeReadOnly}, CreatedAt: now.Add(-time.Hour)}
+	newer := domain.APIKey{ID: uuid.NewString(), Name: "import", UserID: user.ID, Prefix: "postr_bbbbbb", KeyHash: "hash-2", Scopes: domain.Scopes{domain.ScopeUsersRead, domain.ScopePostsWrite}, CreatedAt: now}
+	require.NoError(t, apikeysrepo.Create(testCtx, &older))
+	require.NoError(t, apikeysrepo.Create(testCtx, &newer))
+
+	apiKeys, err := apikeysrepo.List(testCtx)
+	require.NoError(t, err)
+	require.Len(t, apiKeys, 2)
+	assert.Equal(t, newer.ID, apiKeys[0].ID)
+	assert.Equal(t, older.ID, apiKeys[1].ID)
+
+	found, err := apikeysrepo.GetByHash(testCtx, "hash-2")
+	require.NoError(t, err)
+	assert.Equal(t, newer.ID, found.ID)
+	assert.Equal(t, domain.Scopes{domain.ScopeUsersRead, domain.ScopePostsWrite}, found.Scopes)
+	assert.Nil(t, found.LastUsedAt)
+	assert.Nil(t, found.RevokedAt)
+
+	_, err = apikeysrepo.GetByHash(testCtx, "hash-3")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	require.NoError(t, apikeysrepo.Touch(testCtx, newer.ID, now))
+	found, err = apikeysrepo.GetByHash(testCtx, "hash-2")
+	require.NoError(t, err)
+	require.NotNil(t, found.LastUsedAt)
+	assert.True(t, now.Equal(*found.LastUsedAt))
+
+	// Revoking again keeps the first revocation time
+	require.NoError(t, apikeysrepo.Revoke(testCtx, newer.ID, now))
+	require.NoError(t, apikeysrepo.Revoke(testCtx, newer.ID, now.Add(time.Hour)))
+	found, err = apikeysrepo.GetByHash(testCtx, "hash-2")
+	require.NoError(t, err)
+	require.NotNil(t, found.RevokedAt)
+	assert.True(t, now.Equal(*found.RevokedAt))
+
+	err = apikeysrepo.Revoke(testCtx, "non-existent-id", now)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *auditRepository {
+	return &auditRepository{db: db}
+}
+
+// Create appends the event to the audit log, in the transaction ctx is running in if there is one
+func (r *auditRepository) Create(ctx context.Context, event *domain.AuditEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+	return conn(ctx, r.db).Create(event).Error
+}
+
+// List pages through the events matching the query, by page number or by a cursor on (created_at, id)
+func (r *auditRepository) List(ctx context.Context, query domain.AuditQuery) (domain.PaginatedAuditEvents, error) {
+	db := conn(ctx, r.db).Model(&domain.AuditEvent{})
+
+	if query.ActorID != "" {
+		db = db.Where("actor_id = ?", query.ActorID)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if query.TargetType != "" {
+		db = db.Where("target_type = ?", query.TargetType)
+	}
+	if query.TargetID != "" {
+		db = db.Where("target_id = ?", query.TargetID)
+	}
+	if query.RequestID != "" {
+		db = db.Where("request_id = ?", query.RequestID)
+	}
+	if !query.CreatedFrom.IsZero() {
+		db = db.Where("created_at >= ?", query.CreatedFrom.UTC())
+	}
+	if !query.CreatedTo.IsZero() {
+		db = db.Where("created_at <= ?", query.CreatedTo.UTC())
+	}
+
+	key := sortKey{table: "audit_events", column: "created_at", desc: query.SortDesc}
+
+	events, pagination, err := paginate(db, query.Page, key, func(event domain.AuditEvent) (string, string) {
+		return timeKey(event.CreatedAt), event.ID
+	})
+	if err != nil {
+		return domain.PaginatedAuditEvents{}, err
+	}
+
+	return domain.PaginatedAuditEvents{Pagination: pagination, Events: events}, nil
+}
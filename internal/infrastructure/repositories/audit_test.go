@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+func TestAuditRepository_CreateAndList(t *testing.T) {
+	targetID := uuid.NewString()
+	base := time.Now().Add(-time.Hour)
+
+	for i, action := range []domain.AuditAction{domain.AuditPostCreate, domain.AuditPostUpdate, domain.AuditPostDelete} {
+		event := domain.AuditEvent{
+			ActorID:    "actor-1",
+			Action:     action,
+			TargetType: action.TargetType(),
+			TargetID:   targetID,
+			Before:     domain.Snapshot(`{"title":"Before"}`),
+			RequestID:  "req-1",
+			IPAddress:  "203.0.113.7",
+			CreatedAt:  base.Add(time.Duration(i) * time.Minute),
+		}
+		if action == domain.AuditPostCreate {
+			event.Before = nil
+		}
+		require.NoError(t, auditrepo.Create(testCtx, &event))
+		require.NotEmpty(t, event.ID, "the id should be set by the repository")
+	}
+
+	listed, err := auditrepo.List(testCtx, domain.AuditQuery{TargetID: targetID, SortDesc: true, Page: domain.PageRequest{PageNumber: 1, PageSize: 2}})
+	require.NoError(t, err)
+	require.Equal(t, 3, listed.Pagination.TotalSize)
+	require.Equal(t, 2, listed.Pagination.TotalPages)
+	require.Len(t, listed.Events, 2)
+	assert.Equal(t, domain.AuditPostDelete, listed.Events[0].Action, "the most recent event should come first")
+	assert.JSONEq(t, `{"title":"Before"}`, string(listed.Events[0].Before))
+	assert.Nil(t, listed.Events[0].After)
+	assert.Equal(t, "req-1", listed.Events[0].RequestID)
+	assert.Equal(t, "203.0.113.7", listed.Events[0].IPAddress)
+
+	listed, err = auditrepo.List(testCtx, domain.AuditQuery{TargetID: targetID, Action: domain.AuditPostCreate, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	require.Len(t, listed.Events, 1)
+	assert.Nil(t, listed.Events[0].Before)
+
+	listed, err = auditrepo.List(testCtx, domain.AuditQuery{TargetID: targetID, CreatedFrom: base.Add(30 * time.Second), Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	assert.Len(t, listed.Events, 2)
+
+	// Keyset pages walk the log in order
+	page, err := auditrepo.List(testCtx, domain.AuditQuery{TargetID: targetID, Page: domain.PageRequest{PageSize: 2, Keyset: true}})
+	require.NoError(t, err)
+	require.Len(t, page.Events, 2)
+	require.NotEmpty(t, page.Pagination.NextCursor)
+
+	page, err = auditrepo.List(testCtx, domain.AuditQuery{TargetID: targetID, Page: domain.PageRequest{PageSize: 2, Cursor: page.Pagination.NextCursor}})
+	require.NoError(t, err)
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, domain.AuditPostDelete, page.Events[0].Action)
+}
+
+func TestAuditRepository_AppendOnly(t *testing.T) {
+	event := domain.AuditEvent{Action: domain.AuditUserCreate, TargetType: "user", TargetID: uuid.NewString(), CreatedAt: time.Now()}
+	require.NoError(t, auditrepo.Create(testCtx, &event))
+
+	err := db.Model(&domain.AuditEvent{}).Where("id = ?", event.ID).Update("actor_id", "someone-else").Error
+	require.ErrorContains(t, err, "audit events cannot be changed")
+
+	err = db.Where("id = ?", event.ID).Delete(&domain.AuditEvent{}).Error
+	require.ErrorContains(t, err, "audit events cannot be deleted")
+}
+
+func TestTransactor_Transaction(t *testing.T) {
+	cleanUsers(t)
+
+	user := domain.User{ID: uuid.NewString(), Firstname: "Tx", Lastname: "User", Email: "tx@example.com", CreatedAt: time.Now()}
+	event := domain.AuditEvent{Action: domain.AuditUserCreate, TargetType: "user", TargetID: user.ID, CreatedAt: time.Now()}
+
+	// When the function fails, neither the change nor its event are kept
+	failed := errors.New("failed after writing")
+	err := transactions.Transaction(testCtx, func(ctx context.Context) error {
+		require.NoError(t, usersrepo.Create(ctx, &user))
+		require.NoError(t, auditrepo.Create(ctx, &event))
+		return failed
+	})
+	require.ErrorIs(t, err, failed)
+
+	_, err = usersrepo.Get(testCtx, user.ID)
+	require.Error(t, err, "the user should have been rolled back")
+	listed, err := auditrepo.List(testCtx, domain.AuditQuery{TargetID: user.ID, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	require.Empty(t, listed.Events, "the event should have been rolled back")
+
+	// Otherwise both are
+	event.ID = ""
+	err = transactions.Transaction(testCtx, func(ctx context.Context) error {
+		if err := usersrepo.Create(ctx, &user); err != nil {
+			return err
+		}
+		return auditrepo.Create(ctx, &event)
+	})
+	require.NoError(t, err)
+
+	_, err = usersrepo.Get(testCtx, user.ID)
+	require.NoError(t, err)
+	listed, err = auditrepo.List(testCtx, domain.AuditQuery{TargetID: user.ID, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	require.Len(t, listed.Events, 1)
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) *commentRepository {
+	return &commentRepository{db: db}
+}
+
+// Create inserts the comment, constraint violations are returned as domain errors
+func (r *commentRepository) Create(ctx context.Context, comment *domain.Comment) error {
+	return translateError(conn(ctx, r.db).Create(comment).Error)
+}
+
+// Get returns the comment, gorm.ErrRecordNotFound if there is none
+func (r *commentRepository) Get(ctx context.Context, id string) (*domain.Comment, error) {
+	var comment domain.Comment
+	if err := conn(ctx, r.db).First(&comment, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// List pages through the comments of a post, by page number or by a cursor on (created_at, id)
+func (r *commentRepository) List(ctx context.Context, query domain.CommentQuery) (domain.PaginatedComments, error) {
+	db := conn(ctx, r.db).Model(&domain.Comment{}).Where("post_id = ?", query.PostID)
+
+	key := sortKey{table: "comments", column: "created_at", desc: query.SortDesc}
+
+	comments, pagination, err := paginate(db, query.Page, key, func(comment domain.Comment) (string, string) {
+		return timeKey(comment.CreatedAt), comment.ID
+	})
+	if err != nil {
+		return domain.PaginatedComments{}, err
+	}
+
+	return domain.PaginatedComments{Pagination: pagination, Comments: comments}, nil
+}
+
+// Delete permanently deletes the comment, returning gorm.ErrRecordNotFound if there is no such comment
+func (r *commentRepository) Delete(ctx context.Context, id string) error {
+	result := conn(ctx, r.db).Delete(&domain.Comment{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+func TestCommentRepository(t *testing.T) {
+	cleanUsers(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	author := domain.User{ID: uuid.NewString(), Firstname: "Comment", Lastname: "Author", Email: "comments@example.com", CreatedAt: now}
+	require.NoError(t, usersrepo.Create(testCtx, &author))
+
+	post := domain.Post{ID: uuid.NewString(), UserID: author.ID, Title: "Discussed", Body: "Say something", CreatedAt: now}
+	require.NoError(t, postsrepo.Create(testCtx, &post))
+
+	var comments []domain.Comment
+	for i := range 3 {
+		comment := domain.Comment{ID: uuid.NewString(), PostID: post.ID, UserID: author.ID, Body: "Comment", CreatedAt: now.Add(time.Duration(i) * time.Minute)}
+		require.NoError(t, commentsrepo.Create(testCtx, &comment))
+		comments = append(comments, comment)
+	}
+
+	// The post counts its comments
+	found, err := postsrepo.Get(testCtx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, found.CommentCount)
+
+	listed, err := postsrepo.List(testCtx, domain.PostQuery{UserID: author.ID, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	require.Len(t, listed.Posts, 1)
+	assert.Equal(t, 3, listed.Posts[0].CommentCount)
+
+	// Comments are paged oldest first, or most recent first
+	page, err := commentsrepo.List(testCtx, domain.CommentQuery{PostID: post.ID, Page: domain.PageRequest{PageNumber: 1, PageSize: 2}})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Pagination.TotalSize)
+	require.Len(t, page.Comments, 2)
+	assert.Equal(t, comments[0].ID, page.Comments[0].ID)
+	assert.Equal(t, comments[1].ID, page.Comments[1].ID)
+
+	page, err = commentsrepo.List(testCtx, domain.CommentQuery{PostID: post.ID, SortDesc: true, Page: domain.PageRequest{PageSize: 2, Keyset: true}})
+	require.NoError(t, err)
+	require.Len(t, page.Comments, 2)
+	assert.Equal(t, comments[2].ID, page.Comments[0].ID)
+	require.NotEmpty(t, page.Pagination.NextCursor)
+
+	page, err = commentsrepo.List(testCtx, domain.CommentQuery{PostID: post.ID, SortDesc: true, Page: domain.PageRequest{PageSize: 2, Cursor: page.Pagination.NextCursor}})
+	require.NoError(t, err)
+	require.Len(t, page.Comments, 1)
+	assert.Equal(t, comments[0].ID, page.Comments[0].ID)
+
+	// Deleting a comment takes it off the count
+	require.NoError(t, commentsrepo.Delete(testCtx, comments[1].ID))
+	assert.ErrorIs(t, commentsrepo.Delete(testCtx, comments[1].ID), gorm.ErrRecordNotFound)
+
+	_, err = commentsrepo.Get(testCtx, comments[1].ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	found, err = postsrepo.Get(testCtx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, found.CommentCount)
+
+	// Updating the post leaves the count alone
+	found.Title = "Still discussed"
+	require.NoError(t, postsrepo.Update(testCtx, found))
+	found, err = postsrepo.Get(testCtx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, found.CommentCount)
+
+	// Comments go when their post is purged
+	require.NoError(t, postsrepo.Delete(testCtx, post.ID))
+	_, err = postsrepo.Purge(testCtx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	page, err = commentsrepo.List(testCtx, domain.CommentQuery{PostID: post.ID, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	assert.Empty(t, page.Comments)
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/go-ozzo/ozzo-validation/v4"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+// uniqueErrors maps the unique columns, as table.column, that have a dedicated domain error
+var uniqueErrors = map[string]domain.DomainError{
+	"users.email":  domain.ErrEmailAlreadyRegistered,
+	"users.handle": domain.ErrHandleTaken,
+}
+
+// constraintColumn finds the table.column named in a sqlite constraint error message
+var constraintColumn = regexp.MustCompile(`(\w+)\.(\w+)`)
+
+// translateError turns the constraint violations reported by sqlite into domain errors, the
+// offending column, when sqlite names it, is reported as a field error. Other errors are returned as is
+func translateError(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	table, column := "", ""
+	if match := constraintColumn.FindStringSubmatch(sqliteErr.Error()); match != nil {
+		table, column = match[1], match[2]
+	}
+
+	var derr domain.DomainError
+	var reason string
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		if e, ok := uniqueErrors[table+"."+column]; ok {
+			derr = e
+		} else {
+			derr = domain.ErrConflict
+		}
+		reason = "is already taken"
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		derr = domain.ErrInvalidReference
+		reason = "does not exist"
+	case sqlite3.SQLITE_CONSTRAINT_NOTNULL:
+		derr = domain.ErrMissingValue
+		reason = "is required"
+	default:
+		return err
+	}
+
+	if column == "" {
+		return derr
+	}
+	return derr.WithFieldErrors(validation.Errors{fieldName(column): errors.New(reason)})
+}
+
+// lockConflict reports whether sqlite refused the statement of err because another connection holds a lock
+func lockConflict(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+
+	switch sqliteErr.Code() & 0xff {
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+		return true
+	}
+	return false
+}
+
+// fieldName turns a snake_case column name into the camelCase name of its json field
+func fieldName(column string) string {
+	parts := strings.Split(column, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+func TestTranslateError(t *testing.T) {
+	// Foreign keys are enforced per connection, so every statement has to run on the same one
+	err := db.Connection(func(tx *gorm.DB) error {
+		for _, stmt := range []string{
+			"PRAGMA foreign_keys = ON",
+			"CREATE TABLE constraint_parents (id TEXT PRIMARY KEY, slug TEXT UNIQUE)",
+			"CREATE TABLE constraint_children (id TEXT PRIMARY KEY, parent_id TEXT NOT NULL REFERENCES constraint_parents(id))",
+			"INSERT INTO constraint_parents (id, slug) VALUES ('p1', 'first')",
+		} {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		defer tx.Exec("DROP TABLE constraint_children")
+		defer tx.Exec("DROP TABLE constraint_parents")
+		defer tx.Exec("PRAGMA foreign_keys = OFF")
+
+		tests := []struct {
+			name        string
+			stmt        string
+			want        domain.DomainError
+			fieldErrors map[string]string
+		}{
+			{
+				name:        "duplicate primary key",
+				stmt:        "INSERT INTO constraint_parents (id) VALUES ('p1')",
+				want:        domain.ErrConflict,
+				fieldErrors: map[string]string{"id": "is already taken"},
+			},
+			{
+				name:        "duplicate unique column",
+				stmt:        "INSERT INTO constraint_parents (id, slug) VALUES ('p2', 'first')",
+				want:        domain.ErrConflict,
+				fieldErrors: map[string]string{"slug": "is already taken"},
+			},
+			{
+				name:        "missing value",
+				stmt:        "INSERT INTO constraint_children (id) VALUES ('c1')",
+				want:        domain.ErrMissingValue,
+				fieldErrors: map[string]string{"parentId": "is required"},
+			},
+			{
+				name: "missing reference",
+				stmt: "INSERT INTO constraint_children (id, parent_id) VALUES ('c1', 'p9')",
+				want: domain.ErrInvalidReference,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := translateError(tx.Session(&gorm.Session{}).Exec(tt.stmt).Error)
+				require.ErrorIs(t, err, tt.want)
+
+				var derr domain.DomainError
+				require.ErrorAs(t, err, &derr)
+				assert.Equal(t, tt.fieldErrors, derr.FieldErrors)
+			})
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Errors that are not constraint violations are left alone
+	assert.Equal(t, gorm.ErrRecordNotFound, translateError(gorm.ErrRecordNotFound))
+	other := errors.New("disk on fire")
+	assert.Equal(t, other, translateError(other))
+	assert.Nil(t, translateError(nil))
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type followRepository struct {
+	db *gorm.DB
+}
+
+func NewFollowRepository(db *gorm.DB) *followRepository {
+	return &followRepository{db: db}
+}
+
+// Create inserts the follow unless the follower already follows the followee, reporting whether it did.
+// Constraint violations are returned as domain errors
+func (r *followRepository) Create(ctx context.Context, follow *domain.Follow) (bool, error) {
+	result := conn(ctx, r.db).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "follower_id"}, {Name: "followee_id"}}, DoNothing: true}).
+		Create(follow)
+	if result.Error != nil {
+		return false, translateError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Get returns the follow of followeeID by followerID, gorm.ErrRecordNotFound if there is none
+func (r *followRepository) Get(ctx context.Context, followerID string, followeeID string) (*domain.Follow, error) {
+	var follow domain.Follow
+	if err := conn(ctx, r.db).First(&follow, "follower_id = ? AND followee_id = ?", followerID, followeeID).Error; err != nil {
+		return nil, err
+	}
+	return &follow, nil
+}
+
+// Delete removes the follow of followeeID by followerID, reporting whether there was one
+func (r *followRepository) Delete(ctx context.Context, followerID string, followeeID string) (bool, error) {
+	result := conn(ctx, r.db).Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&domain.Follow{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// List pages through the followers of a user, or the users they follow, most recent follow first. Deleted users
+// are left out
+func (r *followRepository) List(ctx context.Context, query domain.FollowQuery) (domain.PaginatedFollowUsers, error) {
+	// The listed users are on the other end of the follows of query.UserID
+	userColumn, otherColumn := "followee_id", "follower_id"
+	if query.Following {
+		userColumn, otherColumn = "follower_id", "followee_id"
+	}
+
+	db := conn(ctx, r.db).
+		Table("follows").
+		Select("users.id, users.firstname, users.lastname, follows.created_at AS followed_at, follows.id AS follow_id").
+		Joins("JOIN users ON users.id = follows."+otherColumn+" AND users.deleted_at IS NULL").
+		Where("follows."+userColumn+" = ?", query.UserID)
+
+	key := sortKey{table: "follows", column: "created_at", desc: true}
+
+	users, pagination, err := paginate(db, query.Page, key, func(user domain.FollowUser) (string, string) {
+		return timeKey(user.FollowedAt), user.FollowID
+	})
+	if err != nil {
+		return domain.PaginatedFollowUsers{}, err
+	}
+
+	return domain.PaginatedFollowUsers{Pagination: pagination, Users: users}, nil
+}
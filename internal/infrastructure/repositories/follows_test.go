@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+func TestFollowRepository(t *testing.T) {
+	cleanUsers(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	var users []domain.User
+	for i := range 3 {
+		user := domain.User{ID: uuid.NewString(), Firstname: "Follow", Lastname: fmt.Sprint("User", i), Email: fmt.Sprintf("follow%d@example.com", i), CreatedAt: now}
+		require.NoError(t, usersrepo.Create(testCtx, &user))
+		users = append(users, user)
+	}
+
+	// users[1] and users[2] follow users[0], users[1] a minute later
+	for i, follower := range []domain.User{users[2], users[1]} {
+		follow := domain.Follow{ID: uuid.NewString(), FollowerID: follower.ID, FolloweeID: users[0].ID, CreatedAt: now.Add(time.Duration(i) * time.Minute)}
+		created, err := followsrepo.Create(testCtx, &follow)
+		require.NoError(t, err)
+		assert.True(t, created)
+	}
+
+	// Following again keeps the first follow
+	created, err := followsrepo.Create(testCtx, &domain.Follow{ID: uuid.NewString(), FollowerID: users[1].ID, FolloweeID: users[0].ID, CreatedAt: now})
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	follow, err := followsrepo.Get(testCtx, users[1].ID, users[0].ID)
+	require.NoError(t, err)
+	assert.True(t, follow.CreatedAt.Equal(now.Add(time.Minute)))
+
+	// Followers are listed most recent first, by page number or by cursor
+	followers, err := followsrepo.List(testCtx, domain.FollowQuery{UserID: users[0].ID, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, followers.Pagination.TotalSize)
+	require.Len(t, followers.Users, 2)
+	assert.Equal(t, users[1].ID, followers.Users[0].ID)
+	assert.Equal(t, "User1", followers.Users[0].Lastname)
+	assert.Equal(t, users[2].ID, followers.Users[1].ID)
+
+	followers, err = followsrepo.List(testCtx, domain.FollowQuery{UserID: users[0].ID, Page: domain.PageRequest{PageSize: 1, Keyset: true}})
+	require.NoError(t, err)
+	require.Len(t, followers.Users, 1)
+	require.NotEmpty(t, followers.Pagination.NextCursor)
+
+	followers, err = followsrepo.List(testCtx, domain.FollowQuery{UserID: users[0].ID, Page: domain.PageRequest{PageSize: 1, Cursor: followers.Pagination.NextCursor}})
+	require.NoError(t, err)
+	require.Len(t, followers.Users, 1)
+	assert.Equal(t, users[2].ID, followers.Users[0].ID)
+
+	following, err := followsrepo.List(testCtx, domain.FollowQuery{UserID: users[2].ID, Following: true, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	require.Len(t, following.Users, 1)
+	assert.Equal(t, users[0].ID, following.Users[0].ID)
+
+	// Deleted users are left out
+	require.NoError(t, usersrepo.Delete(testCtx, users[2].ID, domain.UserDeleteRestrict))
+	followers, err = followsrepo.List(testCtx, domain.FollowQuery{UserID: users[0].ID, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	require.Len(t, followers.Users, 1)
+
+	// Unfollowing removes the follow once, and follows go when their users are purged
+	deleted, err := followsrepo.Delete(testCtx, users[1].ID, users[0].ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = followsrepo.Delete(testCtx, users[1].ID, users[0].ID)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	_, err = usersrepo.Purge(testCtx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	_, err = followsrepo.Get(testCtx, users[2].ID, users[0].ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestPostRepository_Feed(t *testing.T) {
+	cleanUsers(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	reader := domain.User{ID: uuid.NewString(), Firstname: "Feed", Lastname: "Reader", Email: "reader@example.com", CreatedAt: now}
+	require.NoError(t, usersrepo.Create(testCtx, &reader))
+
+	// The reader follows every author but the last, whose posts stay out of the feed, and the feed is read once
+	// with few follows and once with enough to walk the most recent posts instead
+	for _, follows := range []int{3, feedScanFollows + 1} {
+		t.Run(fmt.Sprint(follows, " follows"), func(t *testing.T) {
+			require.NoError(t, db.Where("follower_id = ?", reader.ID).Delete(&domain.Follow{}).Error)
+
+			var feed []domain.Post
+			for i := range follows + 1 {
+				author := domain.User{ID: uuid.NewString(), Firstname: "Feed", Lastname: "Author", Email: uuid.NewString() + "@example.com", CreatedAt: now}
+				require.NoError(t, usersrepo.Create(testCtx, &author))
+
+				post := domain.Post{ID: uuid.NewString(), UserID: author.ID, Title: "Fresh", Body: "In the feed", CreatedAt: now.Add(time.Duration(i) * time.Second)}
+				require.NoError(t, postsrepo.Create(testCtx, &post))
+
+				if i == follows {
+					break
+				}
+				_, err := followsrepo.Create(testCtx, &domain.Follow{ID: uuid.NewString(), FollowerID: reader.ID, FolloweeID: author.ID, CreatedAt: now})
+				require.NoError(t, err)
+				feed = append([]domain.Post{post}, feed...)
+			}
+
+			// Deleted posts are left out
+			require.NoError(t, postsrepo.Delete(testCtx, feed[1].ID))
+			feed = append(feed[:1], feed[2:]...)
+
+			var got []string
+			page := domain.PageRequest{PageSize: 2}
+			for {
+				posts, err := postsrepo.Feed(testCtx, reader.ID, page)
+				require.NoError(t, err)
+				for _, post := range posts.Posts {
+					got = append(got, post.ID)
+				}
+				if posts.Pagination.NextCursor == "" {
+					break
+				}
+				page.Cursor = posts.Pagination.NextCursor
+			}
+
+			want := make([]string, len(feed))
+			for i, post := range feed {
+				want[i] = post.ID
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type loginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) *loginThrottleRepository {
+	return &loginThrottleRepository{db: db}
+}
+
+// List returns the throttles of the keys that have one, keys without failed logins are left out
+func (r *loginThrottleRepository) List(ctx context.Context, keys ...string) ([]domain.LoginThrottle, error) {
+	throttles := []domain.LoginThrottle{}
+	if err := conn(ctx, r.db).Where("key IN ?", keys).Find(&throttles).Error; err != nil {
+		return nil, err
+	}
+	return throttles, nil
+}
+
+// RecordFailure counts a failed login of the key at the given time and returns its throttle. Failures before
+// since are forgotten, counting starts over from this one
+func (r *loginThrottleRepository) RecordFailure(ctx context.Context, key string, at time.Time, since time.Time) (*domain.LoginThrottle, error) {
+	throttle := domain.LoginThrottle{Key: key, Failures: 1, LastFailedAt: &at}
+	err := conn(ctx, r.db).Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"failures": gorm.Expr(
+					"CASE WHEN login_throttles.last_failed_at IS NULL OR login_throttles.last_failed_at < ? THEN 1 ELSE login_throttles.failures + 1 END",
+					since,
+				),
+				"last_failed_at": at,
+			}),
+		},
+		clause.Returning{},
+	).Create(&throttle).Error
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// Lock locks the key out until the given time, starting its failures over and counting the lockout.
+// It returns gorm.ErrRecordNotFound if the key has no throttle
+func (r *loginThrottleRepository) Lock(ctx context.Context, key string, until time.Time) (*domain.LoginThrottle, error) {
+	var throttle domain.LoginThrottle
+	result := conn(ctx, r.db).Model(&throttle).Clauses(clause.Returning{}).
+		Where("key = ?", key).
+		Updates(map[string]any{
+			"failures":     0,
+			"locked_until": until,
+			"lockouts":     gorm.Expr("lockouts + 1"),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &throttle, nil
+}
+
+// Reset forgets the failures of the key and lifts its lockout, its lockouts stay counted
+func (r *loginThrottleRepository) Reset(ctx context.Context, key string) error {
+	return conn(ctx, r.db).Model(&domain.LoginThrottle{}).
+		Where("key = ?", key).
+		Updates(map[string]any{
+			"failures":       0,
+			"last_failed_at": nil,
+			"locked_until":   nil,
+		}).Error
+}
+
+// Purge permanently deletes the throttles without a failure or a lockout since the given time
+func (r *loginThrottleRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("(last_failed_at IS NULL OR last_failed_at < ?) AND (locked_until IS NULL OR locked_until < ?)", before, before).
+		Delete(&domain.LoginThrottle{})
+	return result.RowsAffected, result.Error
+}
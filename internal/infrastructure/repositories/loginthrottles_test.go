@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+func TestLoginThrottleRepository(t *testing.T) {
+	require.NoError(t, db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&domain.LoginThrottle{}).Error)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	key := "account:throttled@example.com"
+
+	throttles, err := throttlesrepo.List(testCtx, key, "ip:192.0.2.1")
+	require.NoError(t, err)
+	assert.Empty(t, throttles)
+
+	// Failures count up while they keep coming within the window
+	throttle, err := throttlesrepo.RecordFailure(testCtx, key, now, now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, throttle.Failures)
+
+	throttle, err = throttlesrepo.RecordFailure(testCtx, key, now.Add(time.Second), now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 2, throttle.Failures)
+	require.NotNil(t, throttle.LastFailedAt)
+	assert.True(t, now.Add(time.Second).Equal(*throttle.LastFailedAt))
+
+	// and start over once the last one is older than the window
+	throttle, err = throttlesrepo.RecordFailure(testCtx, key, now.Add(2*time.Hour), now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, throttle.Failures)
+
+	// Locking starts the failures over and counts the lockout
+	until := now.Add(3 * time.Hour)
+	throttle, err = throttlesrepo.Lock(testCtx, key, until)
+	require.NoError(t, err)
+	assert.Equal(t, 0, throttle.Failures)
+	assert.Equal(t, 1, throttle.Lockouts)
+	require.NotNil(t, throttle.LockedUntil)
+	assert.True(t, until.Equal(*throttle.LockedUntil))
+
+	_, err = throttlesrepo.Lock(testCtx, "account:nobody@example.com", until)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	throttles, err = throttlesrepo.List(testCtx, key, "ip:192.0.2.1")
+	require.NoError(t, err)
+	require.Len(t, throttles, 1)
+	assert.Equal(t, key, throttles[0].Key)
+
+	// Resetting lifts the lockout and keeps the count
+	require.NoError(t, throttlesrepo.Reset(testCtx, key))
+	throttles, err = throttlesrepo.List(testCtx, key)
+	require.NoError(t, err)
+	require.Len(t, throttles, 1)
+	assert.Equal(t, 0, throttles[0].Failures)
+	assert.Nil(t, throttles[0].LastFailedAt)
+	assert.Nil(t, throttles[0].LockedUntil)
+	assert.Equal(t, 1, throttles[0].Lockouts)
+
+	// Throttles are purged once nothing happened to them since the cutoff
+	_, err = throttlesrepo.RecordFailure(testCtx, "ip:192.0.2.1", now, now.Add(-time.Hour))
+	require.NoError(t, err)
+
+	purged, err := throttlesrepo.Purge(testCtx, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	purged, err = throttlesrepo.Purge(testCtx, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type mentionRepository struct {
+	db *gorm.DB
+}
+
+func NewMentionRepository(db *gorm.DB) *mentionRepository {
+	return &mentionRepository{db: db}
+}
+
+// Set replaces the users the post mentions with the given users, returning the ids of those it did not mention
+// before. Users who stay mentioned keep their mention as it was
+func (r *mentionRepository) Set(ctx context.Context, postID string, userIDs []string) ([]string, error) {
+	added := []string{}
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var current []string
+		if err := tx.Model(&domain.Mention{}).Where("post_id = ?", postID).Pluck("user_id", &current).Error; err != nil {
+			return err
+		}
+
+		removed := slices.DeleteFunc(slices.Clone(current), func(userID string) bool {
+			return slices.Contains(userIDs, userID)
+		})
+		if len(removed) > 0 {
+			if err := tx.Where("post_id = ? AND user_id IN ?", postID, removed).Delete(&domain.Mention{}).Error; err != nil {
+				return err
+			}
+		}
+
+		mentions := []domain.Mention{}
+		for _, userID := range userIDs {
+			if slices.Contains(current, userID) || slices.Contains(added, userID) {
+				continue
+			}
+			added = append(added, userID)
+			mentions = append(mentions, domain.Mention{PostID: postID, UserID: userID, CreatedAt: tx.NowFunc()})
+		}
+		if len(mentions) == 0 {
+			return nil
+		}
+		return translateError(tx.Create(&mentions).Error)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+func TestMentionRepository(t *testing.T) {
+	cleanUsers(t)
+	require.NoError(t, db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&domain.Mention{}).Error)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	var users []domain.User
+	for i := range 3 {
+		handle := fmt.Sprint("mention_user", i)
+		user := domain.User{ID: uuid.NewString(), Firstname: "Mention", Lastname: fmt.Sprint("User", i), Email: fmt.Sprintf("mention%d@example.com", i), Handle: &handle, CreatedAt: now}
+		require.NoError(t, usersrepo.Create(testCtx, &user))
+		users = append(users, user)
+	}
+	require.NoError(t, usersrepo.Delete(testCtx, users[2].ID, domain.UserDeleteCascade))
+
+	// Handles are unique
+	taken := domain.User{ID: uuid.NewString(), Firstname: "Mention", Lastname: "Taken", Email: "taken@example.com", Handle: users[0].Handle, CreatedAt: now}
+	require.ErrorIs(t, usersrepo.Create(testCtx, &taken), domain.ErrHandleTaken)
+
+	// Mentions resolve by handle or by id, deleted and unknown users are left out
+	mentioned, err := usersrepo.Mentioned(testCtx, domain.Mentions{
+		Handles: []string{"mention_user0", "mention_user2", "nobody"},
+		UserIDs: []string{users[1].ID, uuid.NewString()},
+	})
+	require.NoError(t, err)
+	var ids []string
+	for _, user := range mentioned {
+		ids = append(ids, user.ID)
+	}
+	assert.ElementsMatch(t, []string{users[0].ID, users[1].ID}, ids)
+
+	mentioned, err = usersrepo.Mentioned(testCtx, domain.Mentions{})
+	require.NoError(t, err)
+	assert.Empty(t, mentioned)
+
+	posts := make([]domain.Post, 2)
+	for i := range posts {
+		posts[i] = domain.Post{ID: uuid.NewString(), UserID: users[0].ID, Title: "Mentions", Body: "Body", CreatedAt: now.Add(time.Duration(i) * time.Minute)}
+		require.NoError(t, postsrepo.Create(testCtx, &posts[i]))
+	}
+
+	added, err := mentionsrepo.Set(testCtx, posts[0].ID, []string{users[0].ID, users[1].ID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{users[0].ID, users[1].ID}, added)
+
+	// Setting the mentions again only reports the users newly mentioned
+	added, err = mentionsrepo.Set(testCtx, posts[0].ID, []string{users[1].ID})
+	require.NoError(t, err)
+	assert.Empty(t, added)
+
+	added, err = mentionsrepo.Set(testCtx, posts[1].ID, []string{users[1].ID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{users[1].ID}, added)
+
+	// Post listings filter on the mentioned user
+	list, err := postsrepo.List(testCtx, domain.PostQuery{MentionedUserID: users[1].ID, SortBy: domain.PostSortCreatedAt, SortDesc: true, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	require.Len(t, list.Posts, 2)
+	assert.Equal(t, posts[1].ID, list.Posts[0].ID)
+	assert.Equal(t, posts[0].ID, list.Posts[1].ID)
+
+	list, err = postsrepo.List(testCtx, domain.PostQuery{MentionedUserID: users[0].ID, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	assert.Empty(t, list.Posts)
+}
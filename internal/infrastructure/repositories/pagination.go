@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/go-ozzo/ozzo-validation/v4"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+// timeKeyLayout matches how the sqlite driver writes time.Time values
+const timeKeyLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+var errInvalidCursor = domain.ErrInvalidInput.WithFieldErrors(validation.Errors{
+	"cursor": errors.New("is invalid or does not match the requested sort"),
+})
+
+// sortKey is the column a listing is ordered by. Rows are ordered by the key and then by id,
+// so rows sharing a key value still have a stable position for cursors
+type sortKey struct {
+	table  string
+	column string
+	desc   bool
+}
+
+func (k sortKey) name() string {
+	if k.desc {
+		return k.column + ":desc"
+	}
+	return k.column + ":asc"
+}
+
+func (k sortKey) order(desc bool) string {
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf("%s.%s %s, %s.id %s", k.table, k.column, dir, k.table, dir)
+}
+
+// cursor marks the row a keyset page continues from. The sort key is read back from that row, so it is
+// compared with the exact stored value, and Key is only used when the row no longer exists
+type cursor struct {
+	ID       string `json:"id"`
+	Key      string `json:"k"`
+	Sort     string `json:"s"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string, key sortKey) (cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, errInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" || c.Sort != key.name() {
+		return cursor{}, errInvalidCursor
+	}
+
+	return c, nil
+}
+
+// timeKey formats a time sort key the way the sqlite driver stores it. Times are stored in UTC, see db.UTC
+func timeKey(t time.Time) string {
+	return t.UTC().Format(timeKeyLayout)
+}
+
+// paginate reads the requested page of query. keyOf returns the sort key and id of a row, which cursors point at
+func paginate[T any](query *gorm.DB, page domain.PageRequest, key sortKey, keyOf func(T) (string, string)) ([]T, domain.Pagination, error) {
+	// Allow the query to be reused for counting and fetching
+	query = query.Session(&gorm.Session{})
+
+	if page.Cursor != "" || page.Keyset {
+		return paginateKeyset(query, page, key, keyOf)
+	}
+	return paginateOffset[T](query, page, key)
+}
+
+// pageOffset returns the number of rows before a numbered page. Page numbers and sizes start at 1, a listing
+// is never read whole
+func pageOffset(page domain.PageRequest) (int, error) {
+	errs := validation.Errors{}
+	if page.PageNumber < 1 {
+		errs["pageNumber"] = errors.New("must be no less than 1")
+	}
+	if page.PageSize < 1 {
+		errs["pageSize"] = errors.New("must be no less than 1")
+	}
+	if len(errs) > 0 {
+		return 0, domain.ErrInvalidInput.WithFieldErrors(errs)
+	}
+	return (page.PageNumber - 1) * page.PageSize, nil
+}
+
+func paginateOffset[T any](query *gorm.DB, page domain.PageRequest, key sortKey) ([]T, domain.Pagination, error) {
+	offset, err := pageOffset(page)
+	if err != nil {
+		return nil, domain.Pagination{}, err
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, domain.Pagination{}, err
+	}
+
+	var rows []T
+	if err := query.Order(key.order(key.desc)).Offset(offset).Limit(page.PageSize).Find(&rows).Error; err != nil {
+		return nil, domain.Pagination{}, err
+	}
+
+	pagination := domain.Pagination{
+		CurrentPage: page.PageNumber,
+		TotalPages:  int(math.Ceil(float64(total) / float64(page.PageSize))),
+		TotalSize:   int(total),
+	}
+	return rows, pagination, nil
+}
+
+func paginateKeyset[T any](query *gorm.DB, page domain.PageRequest, key sortKey, keyOf func(T) (string, string)) ([]T, domain.Pagination, error) {
+	var from *cursor
+	if page.Cursor != "" {
+		c, err := decodeCursor(page.Cursor, key)
+		if err != nil {
+			return nil, domain.Pagination{}, err
+		}
+		from = &c
+	}
+
+	// Paging backwards walks the order in reverse, the page is flipped back afterwards
+	backward := from != nil && from.Backward
+	desc := key.desc != backward
+
+	if from != nil {
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		query = query.Where(
+			fmt.Sprintf("(%s.%s, %s.id) %s (COALESCE((SELECT %s FROM %s WHERE id = ?), ?), ?)",
+				key.table, key.column, key.table, op, key.column, key.table),
+			from.ID, from.Key, from.ID,
+		)
+	}
+
+	// Read one extra row to know whether there is more to come
+	var rows []T
+	if err := query.Order(key.order(desc)).Limit(page.PageSize + 1).Find(&rows).Error; err != nil {
+		return nil, domain.Pagination{}, err
+	}
+
+	hasMore := len(rows) > page.PageSize
+	if hasMore {
+		rows = rows[:page.PageSize]
+	}
+	if backward {
+		slices.Reverse(rows)
+	}
+
+	var pagination domain.Pagination
+	if len(rows) > 0 {
+		if hasMore || backward {
+			k, id := keyOf(rows[len(rows)-1])
+			pagination.NextCursor = encodeCursor(cursor{ID: id, Key: k, Sort: key.name()})
+		}
+		if (from != nil && !backward) || (backward && hasMore) {
+			k, id := keyOf(rows[0])
+			pagination.PrevCursor = encodeCursor(cursor{ID: id, Key: k, Sort: key.name(), Backward: true})
+		}
+	}
+
+	return rows, pagination, nil
+}
@@ -2,7 +2,11 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"math"
+	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"github.com/victor-nach/postr-backend/internal/domain"
@@ -16,18 +20,278 @@ func NewPostRepository(db *gorm.DB) *postRepository {
 	return &postRepository{db: db}
 }
 
+// Create inserts the post, constraint violations are returned as domain errors
 func (r *postRepository) Create(ctx context.Context, post *domain.Post) error {
-	return r.db.WithContext(ctx).Create(post).Error
+	return translateError(conn(ctx, r.db).Create(post).Error)
 }
 
-func (r *postRepository) ListByUserID(ctx context.Context, userId string) ([]domain.Post, error) {
-	var posts []domain.Post
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userId).Find(&posts).Error; err != nil {
+func (r *postRepository) Get(ctx context.Context, id string) (*domain.Post, error) {
+	var post domain.Post
+	if err := conn(ctx, r.db).First(&post, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
-	return posts, nil
+	return &post, nil
 }
 
+// GetWithDeleted returns the post whether or not it is soft deleted
+func (r *postRepository) GetWithDeleted(ctx context.Context, id string) (*domain.Post, error) {
+	var post domain.Post
+	if err := conn(ctx, r.db).Unscoped().First(&post, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// Update saves the post's title and body, keeping the version it replaces as a revision unless neither changed.
+// It returns domain.ErrPostEditConflict when another edit of the post got in first
+func (r *postRepository) Update(ctx context.Context, post *domain.Post) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var current domain.Post
+		if err := tx.First(&current, "id = ?", post.ID).Error; err != nil {
+			return err
+		}
+
+		if current.Title != post.Title || current.Body != post.Body {
+			var latest int
+			if err := tx.Model(&domain.PostRevision{}).
+				Where("post_id = ?", post.ID).
+				Select("COALESCE(MAX(version), 0)").
+				Scan(&latest).Error; err != nil {
+				return err
+			}
+
+			revision := domain.PostRevision{
+				ID:        uuid.NewString(),
+				PostID:    current.ID,
+				Version:   latest + 1,
+				Title:     current.Title,
+				Body:      current.Body,
+				CreatedAt: current.UpdatedAt,
+			}
+			if err := tx.Create(&revision).Error; err != nil {
+				return translateError(err)
+			}
+		}
+
+		// The post is only written as it was read, an edit saved in between leaves it untouched
+		result := tx.Model(post).
+			Where("updated_at = ?", current.UpdatedAt).
+			Select("title", "body", "updated_at").
+			Updates(post)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrPostEditConflict
+		}
+		return nil
+	})
+
+	// Racing edits either take the same revision version or are refused a lock by sqlite
+	if errors.Is(err, domain.ErrConflict) || lockConflict(err) {
+		return domain.ErrPostEditConflict
+	}
+	return err
+}
+
+// ListRevisions returns the stored previous versions of a post, oldest first
+func (r *postRepository) ListRevisions(ctx context.Context, postID string) ([]domain.PostRevision, error) {
+	var revisions []domain.PostRevision
+	if err := conn(ctx, r.db).Where("post_id = ?", postID).Order("version ASC").Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// postSortColumns maps the sortable post fields to their columns
+var postSortColumns = map[domain.PostSortField]string{
+	domain.PostSortCreatedAt: "created_at",
+	domain.PostSortTitle:     "title",
+}
+
+func (r *postRepository) List(ctx context.Context, query domain.PostQuery) (domain.PaginatedPosts, error) {
+	db := conn(ctx, r.db)
+	if query.IncludeDeleted {
+		db = db.Unscoped()
+	}
+
+	db = db.Model(&domain.Post{})
+	if query.UserID != "" {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.Tag != "" {
+		db = db.Where("id IN (SELECT post_tags.post_id FROM post_tags JOIN tags ON tags.id = post_tags.tag_id WHERE tags.name = ?)", query.Tag)
+	}
+	if query.MentionedUserID != "" {
+		db = db.Where("id IN (SELECT post_id FROM mentions WHERE user_id = ?)", query.MentionedUserID)
+	}
+	if !query.CreatedFrom.IsZero() {
+		db = db.Where("created_at >= ?", query.CreatedFrom.UTC())
+	}
+	if !query.CreatedTo.IsZero() {
+		db = db.Where("created_at <= ?", query.CreatedTo.UTC())
+	}
+
+	column, ok := postSortColumns[query.SortBy]
+	if !ok {
+		column = postSortColumns[domain.PostSortCreatedAt]
+	}
+	key := sortKey{table: "posts", column: column, desc: query.SortDesc}
+
+	posts, pagination, err := paginate(db, query.Page, key, func(post domain.Post) (string, string) {
+		if column == "title" {
+			return post.Title, post.ID
+		}
+		return timeKey(post.CreatedAt), post.ID
+	})
+	if err != nil {
+		return domain.PaginatedPosts{}, err
+	}
+
+	return domain.PaginatedPosts{Pagination: pagination, Posts: posts}, nil
+}
+
+// feedScanFollows is the number of follows from which the feed walks the most recent posts of everyone, keeping
+// those of followed users, instead of gathering the posts of every followed user and sorting them
+const feedScanFollows = 200
+
+// Feed pages through the posts of the users userID follows by cursor, most recent first. SQLite is told which index
+// to use, as it otherwise picks the deleted_at one and reads every post that is not deleted: with few follows the
+// posts of each followed user are looked up and sorted, with many the most recent posts are walked until the page
+// is full, which only takes a few posts as most of them are from followed users
+func (r *postRepository) Feed(ctx context.Context, userID string, page domain.PageRequest) (domain.PaginatedPosts, error) {
+	var follows int64
+	if err := conn(ctx, r.db).Model(&domain.Follow{}).Where("follower_id = ?", userID).Count(&follows).Error; err != nil {
+		return domain.PaginatedPosts{}, err
+	}
+
+	db := conn(ctx, r.db).Model(&domain.Post{})
+	if follows < feedScanFollows {
+		db = db.Table("posts INDEXED BY idx_posts_user_id_created_at").
+			Where("posts.user_id IN (SELECT followee_id FROM follows WHERE follower_id = ?)", userID)
+	} else {
+		db = db.Table("posts INDEXED BY idx_posts_created_at").
+			Where("EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = ? AND follows.followee_id = posts.user_id)", userID)
+	}
+
+	page.Keyset = true
+	key := sortKey{table: "posts", column: "created_at", desc: true}
+
+	posts, pagination, err := paginate(db, page, key, func(post domain.Post) (string, string) {
+		return timeKey(post.CreatedAt), post.ID
+	})
+	if err != nil {
+		return domain.PaginatedPosts{}, err
+	}
+
+	return domain.PaginatedPosts{Pagination: pagination, Posts: posts}, nil
+}
+
+// Search runs a ranked full-text search over post titles and bodies, paged by page number
+func (r *postRepository) Search(ctx context.Context, search domain.PostSearch) (domain.PaginatedPostSearchResults, error) {
+	match := ftsQuery(search.Query)
+	if match == "" {
+		return domain.PaginatedPostSearchResults{}, errEmptySearch
+	}
+	offset, err := pageOffset(search.Page)
+	if err != nil {
+		return domain.PaginatedPostSearchResults{}, err
+	}
+
+	var total int64
+	if err := conn(ctx, r.db).Table("posts_fts").
+		Joins("JOIN posts ON posts.id = posts_fts.post_id").
+		Where("posts_fts MATCH ? AND posts.deleted_at IS NULL", match).
+		Count(&total).Error; err != nil {
+		return domain.PaginatedPostSearchResults{}, err
+	}
+
+	// Title matches weigh more than body matches, bm25 scores better matches lower
+	results := []domain.PostSearchResult{}
+	err = conn(ctx, r.db).Raw(`
+		SELECT posts.*,
+			highlight(posts_fts, 1, ?, ?) AS title_highlight,
+			snippet(posts_fts, 2, ?, ?, '…', 24) AS snippet,
+			bm25(posts_fts, 0, 10.0, 1.0) AS rank
+		FROM posts_fts
+		JOIN posts ON posts.id = posts_fts.post_id
+		WHERE posts_fts MATCH ? AND posts.deleted_at IS NULL
+		ORDER BY rank, posts.id
+		LIMIT ? OFFSET ?`, highlightStart, highlightEnd, highlightStart, highlightEnd, match, search.Page.PageSize, offset).Scan(&results).Error
+	if err != nil {
+		return domain.PaginatedPostSearchResults{}, err
+	}
+	for i := range results {
+		results[i].TitleHighlight = highlightHTML(results[i].TitleHighlight)
+		results[i].Snippet = highlightHTML(results[i].Snippet)
+	}
+
+	pagination := domain.Pagination{
+		CurrentPage: search.Page.PageNumber,
+		TotalPages:  int(math.Ceil(float64(total) / float64(search.Page.PageSize))),
+		TotalSize:   int(total),
+	}
+	return domain.PaginatedPostSearchResults{Pagination: pagination, Results: results}, nil
+}
+
+// Delete soft deletes the post, returning gorm.ErrRecordNotFound if there is no such post
 func (r *postRepository) Delete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Delete(&domain.Post{}, "id = ?", id).Error
+	result := conn(ctx, r.db).Delete(&domain.Post{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Restore brings back a soft deleted post, as long as its author is not deleted.
+// Restoring a post that is not deleted changes nothing
+func (r *postRepository) Restore(ctx context.Context, id string) (*domain.Post, error) {
+	var post domain.Post
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().First(&post, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if !post.DeletedAt.Valid {
+			return nil
+		}
+
+		var authors int64
+		if err := tx.Model(&domain.User{}).Where("id = ?", post.UserID).Count(&authors).Error; err != nil {
+			return err
+		}
+		if authors == 0 {
+			return domain.ErrPostAuthorDeleted
+		}
+
+		post.DeletedAt = gorm.DeletedAt{}
+		return tx.Unscoped().Model(&post).UpdateColumn("deleted_at", nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// postRows hold the rows belonging to a post, which go along with it when it is purged
+var postRows = []any{&domain.PostRevision{}, &domain.Comment{}, &domain.Reaction{}, &reactionCount{}, &domain.PostTag{}, &domain.Mention{}}
+
+// Purge permanently deletes the posts soft deleted before the given time, along with their revisions, comments,
+// reactions, tag links and mentions
+func (r *postRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, rows := range postRows {
+			if err := tx.Where("post_id IN (SELECT id FROM posts WHERE deleted_at < ?)", before).Delete(rows).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Unscoped().Where("deleted_at < ?", before).Delete(&domain.Post{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
 }
\ No newline at end of file
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/victor-nach/postr-backend/internal/domain"
+	appdb "github.com/victor-nach/postr-backend/internal/infrastructure/db"
 
 	_ "modernc.org/sqlite"
 )
@@ -24,6 +26,16 @@ var (
 	sqlDB   *sql.DB
 	postsrepo    *postRepository
 	usersrepo    *userRepository
+	apikeysrepo  *apiKeyRepository
+	sessionsrepo *sessionRepository
+	auditrepo    *auditRepository
+	throttlesrepo *loginThrottleRepository
+	commentsrepo *commentRepository
+	reactionsrepo *reactionRepository
+	followsrepo  *followRepository
+	tagsrepo     *tagRepository
+	mentionsrepo *mentionRepository
+	transactions *transactor
 	testCtx = context.Background()
 )
 
@@ -35,18 +47,47 @@ func TestMain(m *testing.M) {
 		log.Fatalf("Failed to open SQLite database: %v", err)
 	}
 
-	db, err = gorm.Open(sqlite.Dialector{Conn: sqlDB}, &gorm.Config{})
+	db, err = gorm.Open(sqlite.Dialector{Conn: appdb.UTC(sqlDB)}, &gorm.Config{})
 	if err != nil {
 		log.Fatalf("Failed to initialize GORM: %v", err)
 	}
 
 	// Apply migrations using gorm automigrate
-	if err := db.AutoMigrate(&domain.User{}, &domain.Post{}); err != nil {
+	if err := db.AutoMigrate(&domain.User{}, &domain.Post{}, &domain.PostRevision{}, &domain.UserRole{}, &domain.APIKey{}, &domain.Session{}, &domain.LoginThrottle{}, &domain.Mention{}); err != nil {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
+	// Automigrate knows nothing of the unique email and handle of the users table
+	if err := db.Exec("CREATE UNIQUE INDEX idx_users_email ON users(email)").Error; err != nil {
+		log.Fatalf("Failed to create the users email index: %v", err)
+	}
+	if err := db.Exec("CREATE UNIQUE INDEX idx_users_handle ON users(handle)").Error; err != nil {
+		log.Fatalf("Failed to create the users handle index: %v", err)
+	}
+
+	// Virtual tables, triggers and the indexes queries name are beyond automigrate, apply their migrations as they are
+	for _, migration := range []string{"0004_posts_listing_indexes.up.sql", "0007_posts_fts.up.sql", "0015_audit_events.up.sql", "0017_comments.up.sql", "0018_reactions.up.sql", "0019_follows.up.sql", "0020_tags.up.sql", "0023_posts_fts_rowids.up.sql"} {
+		script, err := os.ReadFile(filepath.Join("..", "..", "..", "migrations", migration))
+		if err != nil {
+			log.Fatalf("Failed to read migration %s: %v", migration, err)
+		}
+		if err := db.Exec(string(script)).Error; err != nil {
+			log.Fatalf("Failed to apply migration %s: %v", migration, err)
+		}
+	}
+
 	postsrepo = NewPostRepository(db)
 	usersrepo = NewUserRepository(db)
+	apikeysrepo = NewAPIKeyRepository(db)
+	sessionsrepo = NewSessionRepository(db)
+	auditrepo = NewAuditRepository(db)
+	throttlesrepo = NewLoginThrottleRepository(db)
+	commentsrepo = NewCommentRepository(db)
+	reactionsrepo = NewReactionRepository(db)
+	followsrepo = NewFollowRepository(db)
+	tagsrepo = NewTagRepository(db)
+	mentionsrepo = NewMentionRepository(db)
+	transactions = NewTransactor(db)
 
 	// Run the tests
 	code := m.Run()
@@ -75,7 +116,7 @@ func TestPostRepository_Create(t *testing.T) {
 	assert.Equal(t, post.Body, found.Body)
 }
 
-func TestPostRepository_ListByUserID(t *testing.T) {
+func TestPostRepository_List(t *testing.T) {
 	posts := []domain.Post{
 		{ID: uuid.NewString(), UserID: uuid.NewString(), Title: "Post 1", Body: "Body 1", CreatedAt: time.Now()},
 		{ID: uuid.NewString(), UserID: uuid.NewString(), Title: "Post 2", Body: "Body 2", CreatedAt: time.Now()},
@@ -83,10 +124,148 @@ func TestPostRepository_ListByUserID(t *testing.T) {
 	require.NoError(t, db.WithContext(testCtx).Create(&posts).Error)
 
 	// List posts for the user
-	result, err := postsrepo.ListByUserID(testCtx, posts[0].UserID)
+	result, err := postsrepo.List(testCtx, domain.PostQuery{UserID: posts[0].UserID, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	require.Len(t, result.Posts, 1)
+	assert.Equal(t, "Post 1", result.Posts[0].Title)
+
+	// List every post
+	result, err = postsrepo.List(testCtx, domain.PostQuery{Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
 	require.NoError(t, err)
-	require.Len(t, result, 1)
-	assert.Equal(t, "Post 1", result[0].Title)
+	assert.GreaterOrEqual(t, len(result.Posts), len(posts))
+}
+
+func TestPostRepository_ListPaginated(t *testing.T) {
+	userID := uuid.NewString()
+	start := time.Date(2025, 2, 10, 9, 0, 0, 0, time.Local)
+
+	// Posts 3 and 4 share a creation time, so they are told apart by id
+	var posts []domain.Post
+	for i, title := range []string{"delta", "alpha", "echo", "bravo", "charlie"} {
+		createdAt := start.Add(time.Duration(i) * time.Hour)
+		if i == 4 {
+			createdAt = posts[3].CreatedAt
+		}
+		posts = append(posts, domain.Post{ID: uuid.NewString(), UserID: userID, Title: title, Body: "Body", CreatedAt: createdAt})
+	}
+	require.NoError(t, db.WithContext(testCtx).Create(&posts).Error)
+
+	titles := func(posts []domain.Post) []string {
+		var titles []string
+		for _, post := range posts {
+			titles = append(titles, post.Title)
+		}
+		return titles
+	}
+
+	t.Run("by page number", func(t *testing.T) {
+		query := domain.PostQuery{
+			UserID: userID,
+			SortBy: domain.PostSortTitle,
+			Page:   domain.PageRequest{PageNumber: 2, PageSize: 2},
+		}
+		result, err := postsrepo.List(testCtx, query)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"charlie", "delta"}, titles(result.Posts))
+		assert.Equal(t, domain.Pagination{CurrentPage: 2, TotalPages: 3, TotalSize: 5}, result.Pagination)
+	})
+
+	t.Run("page below 1", func(t *testing.T) {
+		// A listing is never read whole, nor from a negative offset
+		for _, page := range []domain.PageRequest{{PageNumber: 1}, {PageNumber: 1, PageSize: -1}, {PageNumber: -1, PageSize: 2}} {
+			_, err := postsrepo.List(testCtx, domain.PostQuery{UserID: userID, Page: page})
+			assert.ErrorIs(t, err, domain.ErrInvalidInput)
+		}
+	})
+
+	t.Run("by cursor", func(t *testing.T) {
+		query := domain.PostQuery{
+			UserID:   userID,
+			SortBy:   domain.PostSortCreatedAt,
+			SortDesc: true,
+			Page:     domain.PageRequest{PageSize: 2, Keyset: true},
+		}
+
+		var pages [][]string
+		var last domain.Pagination
+		for {
+			result, err := postsrepo.List(testCtx, query)
+			require.NoError(t, err)
+			pages = append(pages, titles(result.Posts))
+			last = result.Pagination
+
+			if result.Pagination.NextCursor == "" {
+				break
+			}
+			query.Page.Cursor = result.Pagination.NextCursor
+		}
+
+		ids := []string{posts[3].ID, posts[4].ID}
+		tied := []string{"bravo", "charlie"}
+		if ids[0] < ids[1] {
+			tied = []string{"charlie", "bravo"}
+		}
+		assert.Equal(t, [][]string{tied, {"echo", "alpha"}, {"delta"}}, pages)
+
+		// Walk back from the last page
+		query.Page.Cursor = last.PrevCursor
+		result, err := postsrepo.List(testCtx, query)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"echo", "alpha"}, titles(result.Posts))
+		assert.NotEmpty(t, result.Pagination.NextCursor)
+		assert.NotEmpty(t, result.Pagination.PrevCursor)
+	})
+
+	t.Run("cursor for another sort", func(t *testing.T) {
+		query := domain.PostQuery{UserID: userID, SortBy: domain.PostSortTitle, Page: domain.PageRequest{PageSize: 2, Keyset: true}}
+		result, err := postsrepo.List(testCtx, query)
+		require.NoError(t, err)
+
+		query.SortDesc = true
+		query.Page.Cursor = result.Pagination.NextCursor
+		_, err = postsrepo.List(testCtx, query)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		query.Page.Cursor = "not-a-cursor"
+		_, err = postsrepo.List(testCtx, query)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+
+	t.Run("by creation date", func(t *testing.T) {
+		query := domain.PostQuery{
+			UserID:      userID,
+			CreatedFrom: start.Add(time.Hour),
+			CreatedTo:   start.Add(2 * time.Hour),
+			SortBy:      domain.PostSortCreatedAt,
+			Page:        domain.PageRequest{PageNumber: 1, PageSize: 10},
+		}
+		result, err := postsrepo.List(testCtx, query)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alpha", "echo"}, titles(result.Posts))
+	})
+
+	t.Run("across time zones", func(t *testing.T) {
+		// Posts written in different zones are compared and sorted by the time they were made
+		tokyo, newYork := time.FixedZone("JST", 9*60*60), time.FixedZone("EST", -5*60*60)
+		noon := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+		zoned := []domain.Post{
+			{ID: uuid.NewString(), UserID: uuid.NewString(), Title: "earlier", Body: "Body", CreatedAt: noon.Add(-2 * time.Hour).In(tokyo)},
+			{ID: uuid.NewString(), UserID: uuid.NewString(), Title: "later", Body: "Body", CreatedAt: noon.In(newYork)},
+		}
+		zoned[1].UserID = zoned[0].UserID
+		require.NoError(t, db.WithContext(testCtx).Create(&zoned).Error)
+
+		query := domain.PostQuery{UserID: zoned[0].UserID, SortBy: domain.PostSortCreatedAt, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}}
+		result, err := postsrepo.List(testCtx, query)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"earlier", "later"}, titles(result.Posts))
+
+		query.CreatedFrom = noon.Add(-time.Hour).In(time.FixedZone("CET", 60*60))
+		result, err = postsrepo.List(testCtx, query)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"later"}, titles(result.Posts))
+	})
 }
 
 func TestPostRepository_Delete(t *testing.T) {
@@ -109,3 +288,225 @@ func TestPostRepository_Delete(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, gorm.ErrRecordNotFound, err)
 }
+
+func TestPostRepository_Update(t *testing.T) {
+	post := domain.Post{
+		ID:        uuid.NewString(),
+		UserID:    uuid.NewString(),
+		Title:     "Original Title",
+		Body:      "Original body",
+		CreatedAt: time.Now(),
+	}
+	require.NoError(t, postsrepo.Create(testCtx, &post))
+
+	// Edit the post twice
+	post.Title = "Second Title"
+	require.NoError(t, postsrepo.Update(testCtx, &post))
+
+	post.Body = "Third body"
+	require.NoError(t, postsrepo.Update(testCtx, &post))
+
+	found, err := postsrepo.Get(testCtx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Second Title", found.Title)
+	assert.Equal(t, "Third body", found.Body)
+
+	// Both previous versions are kept
+	revisions, err := postsrepo.ListRevisions(testCtx, post.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, 1, revisions[0].Version)
+	assert.Equal(t, "Original Title", revisions[0].Title)
+	assert.Equal(t, "Original body", revisions[0].Body)
+	assert.Equal(t, 2, revisions[1].Version)
+	assert.Equal(t, "Second Title", revisions[1].Title)
+	assert.Equal(t, "Original body", revisions[1].Body)
+
+	// An edit changing neither the title nor the body keeps no revision
+	post.UpdatedAt = time.Now()
+	require.NoError(t, postsrepo.Update(testCtx, &post))
+	revisions, err = postsrepo.ListRevisions(testCtx, post.ID)
+	require.NoError(t, err)
+	assert.Len(t, revisions, 2)
+
+	// An edit saved between reading the post and writing it is not overwritten, the later edit is refused and
+	// rolled back
+	require.NoError(t, db.Callback().Update().Before("gorm:update").Register("test:edit_meanwhile", func(tx *gorm.DB) {
+		if tx.Statement.Table == "posts" {
+			tx.Session(&gorm.Session{NewDB: true}).Exec("UPDATE posts SET title = 'Meanwhile', updated_at = ? WHERE id = ?", time.Now().Add(time.Minute), post.ID)
+		}
+	}))
+	post.Title = "Lost Title"
+	err = postsrepo.Update(testCtx, &post)
+	require.NoError(t, db.Callback().Update().Remove("test:edit_meanwhile"))
+	assert.ErrorIs(t, err, domain.ErrPostEditConflict)
+
+	found, err = postsrepo.Get(testCtx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Second Title", found.Title)
+
+	// Non-existent post
+	err = postsrepo.Update(testCtx, &domain.Post{ID: "non-existent-id"})
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+func TestPostRepository_Search(t *testing.T) {
+	userID := uuid.NewString()
+	posts := []domain.Post{
+		{ID: uuid.NewString(), UserID: userID, Title: "Lighthouse keeping", Body: "Notes from a winter on the rocks", CreatedAt: time.Now()},
+		{ID: uuid.NewString(), UserID: userID, Title: "Coastal walks", Body: "The old lighthouse keeper showed us the lamp", CreatedAt: time.Now()},
+		{ID: uuid.NewString(), UserID: userID, Title: "Keeping bees", Body: "Hives, honey and the occasional sting", CreatedAt: time.Now()},
+	}
+	for i := range posts {
+		require.NoError(t, postsrepo.Create(testCtx, &posts[i]))
+	}
+
+	search := func(t *testing.T, query string) domain.PaginatedPostSearchResults {
+		results, err := postsrepo.Search(testCtx, domain.PostSearch{Query: query, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+		require.NoError(t, err)
+		return results
+	}
+	ids := func(results domain.PaginatedPostSearchResults) []string {
+		var ids []string
+		for _, result := range results.Results {
+			ids = append(ids, result.ID)
+		}
+		return ids
+	}
+
+	t.Run("ranks title matches first", func(t *testing.T) {
+		results := search(t, "lighthouse")
+		assert.Equal(t, []string{posts[0].ID, posts[1].ID}, ids(results))
+		assert.Equal(t, 2, results.Pagination.TotalSize)
+		assert.Equal(t, "<mark>Lighthouse</mark> keeping", results.Results[0].TitleHighlight)
+		assert.Contains(t, results.Results[1].Snippet, "<mark>lighthouse</mark>")
+		assert.Equal(t, posts[1].Body, results.Results[1].Body)
+	})
+
+	t.Run("escapes highlights", func(t *testing.T) {
+		post := domain.Post{ID: uuid.NewString(), UserID: userID, Title: "<script>alert(1)</script> lantern", Body: `A lantern <img src=x onerror="alert(1)"> & more`, CreatedAt: time.Now()}
+		require.NoError(t, postsrepo.Create(testCtx, &post))
+
+		results := search(t, "lantern")
+		require.Len(t, results.Results, 1)
+		assert.Equal(t, "&lt;script&gt;alert(1)&lt;/script&gt; <mark>lantern</mark>", results.Results[0].TitleHighlight)
+		assert.Equal(t, "A <mark>lantern</mark> &lt;img src=x onerror=&#34;alert(1)&#34;&gt; &amp; more", results.Results[0].Snippet)
+		assert.Equal(t, post.Body, results.Results[0].Body)
+
+		require.NoError(t, db.WithContext(testCtx).Unscoped().Delete(&domain.Post{}, "id = ?", post.ID).Error)
+	})
+
+	t.Run("phrase", func(t *testing.T) {
+		assert.Equal(t, []string{posts[1].ID}, ids(search(t, `"lighthouse keeper"`)))
+		assert.Empty(t, ids(search(t, `"keeper lighthouse"`)))
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		assert.Equal(t, []string{posts[2].ID}, ids(search(t, "hon*")))
+	})
+
+	t.Run("follows edits and deletes", func(t *testing.T) {
+		posts[2].Title = "Keeping wasps"
+		posts[2].UpdatedAt = time.Now()
+		require.NoError(t, postsrepo.Update(testCtx, &posts[2]))
+		assert.Equal(t, []string{posts[2].ID}, ids(search(t, "wasps")))
+
+		require.NoError(t, postsrepo.Delete(testCtx, posts[2].ID))
+		assert.Empty(t, ids(search(t, "wasps")))
+
+		// Purged posts leave nothing behind in the index
+		require.NoError(t, db.WithContext(testCtx).Unscoped().Delete(&domain.Post{}, "id = ?", posts[2].ID).Error)
+		for _, table := range []string{"posts_fts", "posts_fts_ids"} {
+			var count int64
+			require.NoError(t, db.WithContext(testCtx).Table(table).Where("post_id = ?", posts[2].ID).Count(&count).Error)
+			assert.Zero(t, count, table)
+		}
+	})
+
+	t.Run("operators are searched as words", func(t *testing.T) {
+		assert.Empty(t, ids(search(t, `lighthouse NOT keeper`)))
+		assert.Equal(t, []string{posts[1].ID}, ids(search(t, `(lighthouse) keeper*`)))
+	})
+
+	t.Run("nothing to search for", func(t *testing.T) {
+		_, err := postsrepo.Search(testCtx, domain.PostSearch{Query: `"" * -`, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestPostRepository_Restore(t *testing.T) {
+	cleanUsers(t)
+
+	user := domain.User{ID: uuid.NewString(), Firstname: "Restore", Lastname: "Test", Email: "post-restore@example.com", CreatedAt: time.Now()}
+	require.NoError(t, usersrepo.Create(testCtx, &user))
+
+	post := domain.Post{ID: uuid.NewString(), UserID: user.ID, Title: "Restore me", Body: "Body", CreatedAt: time.Now()}
+	require.NoError(t, postsrepo.Create(testCtx, &post))
+
+	require.NoError(t, postsrepo.Delete(testCtx, post.ID))
+	assert.Equal(t, gorm.ErrRecordNotFound, postsrepo.Delete(testCtx, post.ID), "deleting twice finds nothing")
+
+	// Deleted posts are hidden from listings and search unless asked for
+	listed, err := postsrepo.List(testCtx, domain.PostQuery{UserID: user.ID, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	assert.Empty(t, listed.Posts)
+
+	listed, err = postsrepo.List(testCtx, domain.PostQuery{UserID: user.ID, IncludeDeleted: true, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	require.Len(t, listed.Posts, 1)
+	assert.True(t, listed.Posts[0].DeletedAt.Valid)
+
+	found, err := postsrepo.Search(testCtx, domain.PostSearch{Query: "restore", Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	assert.Empty(t, found.Results)
+
+	_, err = postsrepo.Get(testCtx, post.ID)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	deleted, err := postsrepo.GetWithDeleted(testCtx, post.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted.DeletedAt.Valid)
+
+	restored, err := postsrepo.Restore(testCtx, post.ID)
+	require.NoError(t, err)
+	assert.False(t, restored.DeletedAt.Valid)
+
+	_, err = postsrepo.Get(testCtx, post.ID)
+	require.NoError(t, err)
+
+	// Posts of deleted users stay deleted
+	require.NoError(t, postsrepo.Delete(testCtx, post.ID))
+	require.NoError(t, usersrepo.Delete(testCtx, user.ID, domain.UserDeleteRestrict))
+
+	_, err = postsrepo.Restore(testCtx, post.ID)
+	assert.Equal(t, domain.ErrPostAuthorDeleted, err)
+
+	_, err = postsrepo.Restore(testCtx, "non-existent-id")
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+func TestPostRepository_Purge(t *testing.T) {
+	now := time.Now()
+	newDeletedPost := func(t *testing.T, deletedAt time.Time) domain.Post {
+		post := domain.Post{ID: uuid.NewString(), UserID: uuid.NewString(), Title: "Purge", Body: "Body", CreatedAt: now}
+		require.NoError(t, postsrepo.Create(testCtx, &post))
+		require.NoError(t, db.Model(&post).UpdateColumn("deleted_at", deletedAt).Error)
+		return post
+	}
+
+	expired := newDeletedPost(t, now.Add(-48*time.Hour))
+	require.NoError(t, db.Create(&domain.PostRevision{ID: uuid.NewString(), PostID: expired.ID, Version: 1, Title: "Old", Body: "Old", CreatedAt: now}).Error)
+	recent := newDeletedPost(t, now.Add(-time.Hour))
+
+	purged, err := postsrepo.Purge(testCtx, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	var count int64
+	require.NoError(t, db.Unscoped().Model(&domain.Post{}).Where("id = ?", expired.ID).Count(&count).Error)
+	assert.Zero(t, count)
+	require.NoError(t, db.Model(&domain.PostRevision{}).Where("post_id = ?", expired.ID).Count(&count).Error)
+	assert.Zero(t, count, "revisions are purged with their post")
+	require.NoError(t, db.Unscoped().Model(&domain.Post{}).Where("id = ?", recent.ID).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
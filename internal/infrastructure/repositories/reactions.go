@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type reactionRepository struct {
+	db *gorm.DB
+}
+
+func NewReactionRepository(db *gorm.DB) *reactionRepository {
+	return &reactionRepository{db: db}
+}
+
+// reactionCount is a row of reaction_counts, which the triggers on reactions keep up to date
+type reactionCount struct {
+	PostID string
+	Kind   domain.ReactionKind
+	Count  int
+}
+
+func (reactionCount) TableName() string {
+	return "reaction_counts"
+}
+
+// Add inserts the reaction unless the user already reacted to the post with its kind, reporting whether it did.
+// Constraint violations are returned as domain errors
+func (r *reactionRepository) Add(ctx context.Context, reaction *domain.Reaction) (bool, error) {
+	result := conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(reaction)
+	if result.Error != nil {
+		return false, translateError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Remove deletes the reaction, reporting whether there was one
+func (r *reactionRepository) Remove(ctx context.Context, reaction domain.Reaction) (bool, error) {
+	result := conn(ctx, r.db).
+		Where("post_id = ? AND user_id = ? AND kind = ?", reaction.PostID, reaction.UserID, reaction.Kind).
+		Delete(&domain.Reaction{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Counts returns the reaction counts of each of the posts, every kind included, marking the kinds userID reacted
+// with. An empty userID reacted with none
+func (r *reactionRepository) Counts(ctx context.Context, userID string, postIDs ...string) (map[string][]domain.ReactionCount, error) {
+	db := conn(ctx, r.db)
+
+	var rows []reactionCount
+	if err := db.Where("post_id IN ? AND count > 0", postIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]map[domain.ReactionKind]int, len(postIDs))
+	for _, row := range rows {
+		if counts[row.PostID] == nil {
+			counts[row.PostID] = map[domain.ReactionKind]int{}
+		}
+		counts[row.PostID][row.Kind] = row.Count
+	}
+
+	reacted := make(map[string]map[domain.ReactionKind]bool)
+	if userID != "" {
+		var reactions []domain.Reaction
+		if err := db.Where("user_id = ? AND post_id IN ?", userID, postIDs).Find(&reactions).Error; err != nil {
+			return nil, err
+		}
+
+		for _, reaction := range reactions {
+			if reacted[reaction.PostID] == nil {
+				reacted[reaction.PostID] = map[domain.ReactionKind]bool{}
+			}
+			reacted[reaction.PostID][reaction.Kind] = true
+		}
+	}
+
+	reactions := make(map[string][]domain.ReactionCount, len(postIDs))
+	for _, postID := range postIDs {
+		reactions[postID] = domain.NewReactionCounts(counts[postID], reacted[postID])
+	}
+	return reactions, nil
+}
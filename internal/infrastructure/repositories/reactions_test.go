@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+	"github.com/victor-nach/postr-backend/pkg/migrator"
+)
+
+func TestReactionRepository(t *testing.T) {
+	cleanUsers(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	author := domain.User{ID: uuid.NewString(), Firstname: "Reaction", Lastname: "Author", Email: "reactions@example.com", CreatedAt: now}
+	fan := domain.User{ID: uuid.NewString(), Firstname: "Reaction", Lastname: "Fan", Email: "fan@example.com", CreatedAt: now}
+	require.NoError(t, usersrepo.Create(testCtx, &author))
+	require.NoError(t, usersrepo.Create(testCtx, &fan))
+
+	post := domain.Post{ID: uuid.NewString(), UserID: author.ID, Title: "Liked", Body: "React to this", CreatedAt: now}
+	other := domain.Post{ID: uuid.NewString(), UserID: author.ID, Title: "Ignored", Body: "Nobody reacts", CreatedAt: now}
+	require.NoError(t, postsrepo.Create(testCtx, &post))
+	require.NoError(t, postsrepo.Create(testCtx, &other))
+
+	// A user reacts at most once with each kind
+	for _, reaction := range []domain.Reaction{
+		{PostID: post.ID, UserID: author.ID, Kind: domain.ReactionLike},
+		{PostID: post.ID, UserID: fan.ID, Kind: domain.ReactionLike},
+		{PostID: post.ID, UserID: fan.ID, Kind: domain.ReactionLove},
+	} {
+		added, err := reactionsrepo.Add(testCtx, &reaction)
+		require.NoError(t, err)
+		assert.True(t, added)
+	}
+
+	added, err := reactionsrepo.Add(testCtx, &domain.Reaction{PostID: post.ID, UserID: fan.ID, Kind: domain.ReactionLike})
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	counts, err := reactionsrepo.Counts(testCtx, fan.ID, post.ID, other.ID)
+	require.NoError(t, err)
+	require.Len(t, counts[post.ID], len(domain.ReactionKinds))
+	assert.Equal(t, domain.ReactionCount{Kind: domain.ReactionLike, Count: 2, Reacted: true}, counts[post.ID][0])
+	assert.Equal(t, domain.ReactionCount{Kind: domain.ReactionLove, Count: 1, Reacted: true}, counts[post.ID][1])
+	assert.Equal(t, domain.ReactionCount{Kind: domain.ReactionLaugh}, counts[post.ID][2])
+	assert.Equal(t, domain.NewReactionCounts(nil, nil), counts[other.ID])
+
+	// Nobody in particular reacted with nothing
+	counts, err = reactionsrepo.Counts(testCtx, "", post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReactionCount{Kind: domain.ReactionLike, Count: 2}, counts[post.ID][0])
+
+	// Removing a reaction takes it off the count, once
+	removed, err := reactionsrepo.Remove(testCtx, domain.Reaction{PostID: post.ID, UserID: fan.ID, Kind: domain.ReactionLike})
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	removed, err = reactionsrepo.Remove(testCtx, domain.Reaction{PostID: post.ID, UserID: fan.ID, Kind: domain.ReactionLike})
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	counts, err = reactionsrepo.Counts(testCtx, fan.ID, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReactionCount{Kind: domain.ReactionLike, Count: 1}, counts[post.ID][0])
+
+	// Reactions and their counts go when their post is purged
+	require.NoError(t, postsrepo.Delete(testCtx, post.ID))
+	_, err = postsrepo.Purge(testCtx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	var left int64
+	require.NoError(t, db.Model(&domain.Reaction{}).Where("post_id = ?", post.ID).Count(&left).Error)
+	assert.Zero(t, left)
+	require.NoError(t, db.Model(&reactionCount{}).Where("post_id = ?", post.ID).Count(&left).Error)
+	assert.Zero(t, left)
+}
+
+func TestReactionRepository_Concurrent(t *testing.T) {
+	// Concurrent requests run on connections of their own, which an in-memory database does not share, so this
+	// runs against a database file set up like the application's
+	dsn := fmt.Sprintf("file:%s?mode=rwc&cache=shared&_pragma=foreign_keys(1)", filepath.Join(t.TempDir(), "app.db"))
+	sqlDB, err := sql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	require.NoError(t, migrator.Migrate(sqlDB, "file://../../../migrations"))
+
+	fileDB, err := gorm.Open(sqlite.Dialector{Conn: sqlDB}, &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+
+	users := NewUserRepository(fileDB)
+	posts := NewPostRepository(fileDB)
+	reactions := NewReactionRepository(fileDB)
+
+	now := time.Now()
+	var fans []string
+	for i := range 20 {
+		user := domain.User{ID: uuid.NewString(), Firstname: "Fan", Lastname: "Number", Email: fmt.Sprintf("fan%d@example.com", i), CreatedAt: now}
+		require.NoError(t, users.Create(testCtx, &user))
+		fans = append(fans, user.ID)
+	}
+
+	post := domain.Post{ID: uuid.NewString(), UserID: fans[0], Title: "Popular", Body: "Everyone reacts", CreatedAt: now}
+	require.NoError(t, posts.Create(testCtx, &post))
+
+	// Every fan likes, unlikes and likes again, all at the same time
+	var wg sync.WaitGroup
+	errs := make(chan error, len(fans))
+	for _, fan := range fans {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reaction := domain.Reaction{PostID: post.ID, UserID: fan, Kind: domain.ReactionLike}
+			for range 3 {
+				if _, err := reactions.Add(testCtx, &reaction); err != nil {
+					errs <- err
+					return
+				}
+				if _, err := reactions.Remove(testCtx, reaction); err != nil {
+					errs <- err
+					return
+				}
+			}
+			if _, err := reactions.Add(testCtx, &reaction); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	counts, err := reactions.Counts(testCtx, fans[0], post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReactionCount{Kind: domain.ReactionLike, Count: len(fans), Reacted: true}, counts[post.ID][0])
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/go-ozzo/ozzo-validation/v4"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+// highlightStart and highlightEnd mark the matches FTS5 finds in post text. HTML escaping leaves them alone, so
+// they are swapped for <mark> tags once the text is escaped
+const (
+	highlightStart = "\x02"
+	highlightEnd   = "\x03"
+)
+
+var highlightTags = strings.NewReplacer(highlightStart, "<mark>", highlightEnd, "</mark>")
+
+var errEmptySearch = domain.ErrInvalidInput.WithFieldErrors(validation.Errors{
+	"q": errors.New("has no words to search for"),
+})
+
+// ftsQuery turns a search string into an FTS5 query matching every term. A term is a word, a
+// "quoted phrase" or either of them followed by * to match the last word as a prefix. Every
+// term is passed to FTS5 as a quoted string of plain words, so user input cannot use FTS5
+// operators or make a malformed query
+func ftsQuery(search string) string {
+	var terms []string
+
+	for {
+		search = strings.TrimLeftFunc(search, unicode.IsSpace)
+		if search == "" {
+			break
+		}
+
+		var text string
+		if search[0] == '"' {
+			end := strings.IndexByte(search[1:], '"')
+			if end < 0 {
+				// An unterminated quote runs to the end of the search
+				text, search = search[1:], ""
+			} else {
+				text, search = search[1:end+1], search[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(search, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+			if end < 0 {
+				end = len(search)
+			}
+			text, search = search[:end], search[end:]
+		}
+
+		prefix := strings.HasPrefix(search, "*")
+		if prefix {
+			search = search[1:]
+		} else {
+			prefix = strings.HasSuffix(text, "*")
+		}
+
+		words := ftsWords(text)
+		if len(words) == 0 {
+			continue
+		}
+
+		term := `"` + strings.Join(words, " ") + `"`
+		if prefix {
+			term += "*"
+		}
+		terms = append(terms, term)
+	}
+
+	return strings.Join(terms, " ")
+}
+
+// ftsWords splits text into the runs of letters and digits the FTS5 tokenizer indexes
+func ftsWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// highlightHTML escapes post text with marked matches as HTML, the matches wrapped in <mark> tags
+func highlightHTML(marked string) string {
+	return highlightTags.Replace(html.EscapeString(marked))
+}
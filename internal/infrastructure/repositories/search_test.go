@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		search   string
+		expected string
+	}{
+		{"beach", `"beach"`},
+		{"  sunny   beach ", `"sunny" "beach"`},
+		{`"day at the" beach`, `"day at the" "beach"`},
+		{"bea*", `"bea"*`},
+		{`"a day at the bea"*`, `"a day at the bea"*`},
+		{"well-known", `"well known"`},
+		{`beach" day`, `"beach" "day"`},
+		{`"unterminated phrase`, `"unterminated phrase"`},
+		{`beach OR sea NOT "x" NEAR(a b)`, `"beach" "OR" "sea" "NOT" "x" "NEAR a" "b"`},
+		{`title:beach ^start`, `"title beach" "start"`},
+		{`"" * -`, ""},
+		{"café über", `"café" "über"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ftsQuery(tt.search))
+		})
+	}
+}
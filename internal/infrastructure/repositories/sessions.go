@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *sessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// Create inserts the session, constraint violations are returned as domain errors
+func (r *sessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	return translateError(r.db.WithContext(ctx).Create(session).Error)
+}
+
+// Get returns the session whether or not it is still active, gorm.ErrRecordNotFound if there is none
+func (r *sessionRepository) Get(ctx context.Context, id string) (*domain.Session, error) {
+	var session domain.Session
+	if err := r.db.WithContext(ctx).First(&session, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Rotate replaces the refresh token hash of the session, provided it is still active and its current hash is
+// oldHash. It returns gorm.ErrRecordNotFound otherwise, which means the token was used by someone else first
+func (r *sessionRepository) Rotate(ctx context.Context, session *domain.Session, oldHash string) error {
+	result := r.db.WithContext(ctx).Model(&domain.Session{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", session.ID, oldHash).
+		Updates(map[string]any{
+			"refresh_token_hash": session.RefreshTokenHash,
+			"user_agent":         session.UserAgent,
+			"ip_address":         session.IPAddress,
+			"last_used_at":       session.LastUsedAt,
+			"expires_at":         session.ExpiresAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListActive returns the sessions of the user that are neither revoked nor expired at now, most recently used first
+func (r *sessionRepository) ListActive(ctx context.Context, userID string, now time.Time) ([]domain.Session, error) {
+	sessions := []domain.Session{}
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_used_at DESC, id DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Revoke revokes the session of the user, revoking a revoked session changes nothing.
+// It returns gorm.ErrRecordNotFound if the user has no such session
+func (r *sessionRepository) Revoke(ctx context.Context, userID string, id string, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&domain.Session{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("revoked_at", gorm.Expr("COALESCE(revoked_at, ?)", at))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RevokeAll revokes every session of the user that is not revoked yet
+func (r *sessionRepository) RevokeAll(ctx context.Context, userID string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
+
+// Purge permanently deletes the sessions that expired or were revoked before the given time
+func (r *sessionRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ? OR revoked_at < ?", before, before).
+		Delete(&domain.Session{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+func TestSessionRepository(t *testing.T) {
+	cleanUsers(t)
+	require.NoError(t, db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&domain.Session{}).Error)
+
+	user := domain.User{ID: uuid.NewString(), Firstname: "Session", Lastname: "Owner", Email: "sessions@example.com", CreatedAt: time.Now()}
+	require.NoError(t, usersrepo.Create(testCtx, &user))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	newSession := func(hash string, lastUsedAt time.Time) domain.Session {
+		return domain.Session{ID: uuid.NewString(), UserID: user.ID, RefreshTokenHash: hash, CreatedAt: lastUsedAt, LastUsedAt: lastUsedAt, ExpiresAt: now.Add(time.Hour)}
+	}
+	older := newSession("hash-1", now.Add(-time.Hour))
+	newer := newSession("hash-2", now)
+	expired := newSession("hash-3", now.Add(-2*time.Hour))
+	expired.ExpiresAt = now.Add(-time.Minute)
+	for _, s := range []*domain.Session{&older, &newer, &expired} {
+		require.NoError(t, sessionsrepo.Create(testCtx, s))
+	}
+
+	sessions, err := sessionsrepo.ListActive(testCtx, user.ID, now)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, newer.ID, sessions[0].ID)
+	assert.Equal(t, older.ID, sessions[1].ID)
+
+	// Rotating needs the current hash
+	rotated := older
+	rotated.RefreshTokenHash = "hash-4"
+	rotated.LastUsedAt = now.Add(time.Minute)
+	require.NoError(t, sessionsrepo.Rotate(testCtx, &rotated, "hash-1"))
+	assert.ErrorIs(t, sessionsrepo.Rotate(testCtx, &rotated, "hash-1"), gorm.ErrRecordNotFound)
+
+	found, err := sessionsrepo.Get(testCtx, older.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "hash-4", found.RefreshTokenHash)
+
+	// Revoked sessions are no longer listed nor rotated
+	require.NoError(t, sessionsrepo.Revoke(testCtx, user.ID, older.ID, now))
+	assert.ErrorIs(t, sessionsrepo.Revoke(testCtx, "someone-else", newer.ID, now), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, sessionsrepo.Rotate(testCtx, &rotated, "hash-4"), gorm.ErrRecordNotFound)
+
+	sessions, err = sessionsrepo.ListActive(testCtx, user.ID, now)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, newer.ID, sessions[0].ID)
+
+	require.NoError(t, sessionsrepo.RevokeAll(testCtx, user.ID, now))
+	sessions, err = sessionsrepo.ListActive(testCtx, user.ID, now)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	// Ended sessions are purged once they ended before the cutoff
+	purged, err := sessionsrepo.Purge(testCtx, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	purged, err = sessionsrepo.Purge(testCtx, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"math"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) *tagRepository {
+	return &tagRepository{db: db}
+}
+
+// Set replaces the tags of the post with the tags of the given names, creating the tags that do not exist yet
+func (r *tagRepository) Set(ctx context.Context, postID string, names []string) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", postID).Delete(&domain.PostTag{}).Error; err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return nil
+		}
+
+		tags := make([]domain.Tag, len(names))
+		for i, name := range names {
+			tags[i] = domain.Tag{ID: uuid.NewString(), Name: name}
+		}
+		if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).
+			Create(&tags).Error; err != nil {
+			return err
+		}
+
+		// Tags that already existed keep their ids, so they are read back
+		var ids []string
+		if err := tx.Model(&domain.Tag{}).Where("name IN ?", names).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		postTags := make([]domain.PostTag, len(ids))
+		for i, id := range ids {
+			postTags[i] = domain.PostTag{PostID: postID, TagID: id}
+		}
+		return translateError(tx.Create(&postTags).Error)
+	})
+}
+
+// ForPosts returns the tag names of each of the posts, sorted by name
+func (r *tagRepository) ForPosts(ctx context.Context, postIDs ...string) (map[string][]string, error) {
+	var rows []struct {
+		PostID string
+		Name   string
+	}
+	if err := conn(ctx, r.db).Table("post_tags").
+		Select("post_tags.post_id, tags.name").
+		Joins("JOIN tags ON tags.id = post_tags.tag_id").
+		Where("post_tags.post_id IN ?", postIDs).
+		Order("tags.name").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string][]string, len(postIDs))
+	for _, postID := range postIDs {
+		tags[postID] = []string{}
+	}
+	for _, row := range rows {
+		tags[row.PostID] = append(tags[row.PostID], row.Name)
+	}
+	return tags, nil
+}
+
+// List pages through the tags carried by at least one post that is not deleted, most used first, paged by page
+// number as the order moves as posts come and go
+func (r *tagRepository) List(ctx context.Context, query domain.TagQuery) (domain.PaginatedTags, error) {
+	offset, err := pageOffset(query.Page)
+	if err != nil {
+		return domain.PaginatedTags{}, err
+	}
+
+	db := conn(ctx, r.db).Table("tags").
+		Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
+		Joins("JOIN posts ON posts.id = post_tags.post_id AND posts.deleted_at IS NULL")
+	if query.Prefix != "" {
+		db = db.Where(`tags.name LIKE ? ESCAPE '\'`, escapeLike(query.Prefix)+"%")
+	}
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Distinct("tags.id").Count(&total).Error; err != nil {
+		return domain.PaginatedTags{}, err
+	}
+
+	tags := []domain.Tag{}
+	if err := db.Select("tags.id, tags.name, COUNT(*) AS posts").
+		Group("tags.id").
+		Order("posts DESC, tags.name").
+		Offset(offset).Limit(query.Page.PageSize).
+		Scan(&tags).Error; err != nil {
+		return domain.PaginatedTags{}, err
+	}
+
+	pagination := domain.Pagination{
+		CurrentPage: query.Page.PageNumber,
+		TotalPages:  int(math.Ceil(float64(total) / float64(query.Page.PageSize))),
+		TotalSize:   int(total),
+	}
+	return domain.PaginatedTags{Pagination: pagination, Tags: tags}, nil
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+func TestTagRepository(t *testing.T) {
+	require.NoError(t, db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&domain.PostTag{}).Error)
+	require.NoError(t, db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&domain.Tag{}).Error)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	userID := uuid.NewString()
+	var posts []domain.Post
+	for i := range 3 {
+		post := domain.Post{ID: uuid.NewString(), UserID: userID, Title: "Tagged", Body: "Body", CreatedAt: now.Add(time.Duration(i) * time.Minute)}
+		require.NoError(t, postsrepo.Create(testCtx, &post))
+		posts = append(posts, post)
+	}
+
+	require.NoError(t, tagsrepo.Set(testCtx, posts[0].ID, []string{"golang", "sqlite"}))
+	require.NoError(t, tagsrepo.Set(testCtx, posts[1].ID, []string{"golang"}))
+	require.NoError(t, tagsrepo.Set(testCtx, posts[2].ID, []string{"golang", "go_tips"}))
+
+	// Setting the tags again replaces them, existing tags are reused
+	require.NoError(t, tagsrepo.Set(testCtx, posts[0].ID, []string{"go_tips", "golang"}))
+
+	var tags int64
+	require.NoError(t, db.Model(&domain.Tag{}).Count(&tags).Error)
+	assert.Equal(t, int64(3), tags)
+
+	postTags, err := tagsrepo.ForPosts(testCtx, posts[0].ID, posts[1].ID, "missing")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		posts[0].ID: {"go_tips", "golang"},
+		posts[1].ID: {"golang"},
+		"missing":   {},
+	}, postTags)
+
+	// Tags are listed most used first, left out once none of their posts are left
+	require.NoError(t, postsrepo.Delete(testCtx, posts[2].ID))
+
+	list, err := tagsrepo.List(testCtx, domain.TagQuery{Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, list.Pagination.TotalSize)
+	assert.Equal(t, []domain.Tag{{Name: "golang", Posts: 2}, {Name: "go_tips", Posts: 1}}, stripTagIDs(list.Tags))
+
+	// The underscore of a prefix is not a wildcard
+	list, err = tagsrepo.List(testCtx, domain.TagQuery{Prefix: "go_", Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Tag{{Name: "go_tips", Posts: 1}}, stripTagIDs(list.Tags))
+
+	list, err = tagsrepo.List(testCtx, domain.TagQuery{Page: domain.PageRequest{PageNumber: 2, PageSize: 1}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, list.Pagination.TotalPages)
+	assert.Equal(t, []domain.Tag{{Name: "go_tips", Posts: 1}}, stripTagIDs(list.Tags))
+
+	// Post listings filter on a tag
+	tagged, err := postsrepo.List(testCtx, domain.PostQuery{UserID: userID, Tag: "go_tips", SortDesc: true, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	require.Len(t, tagged.Posts, 1)
+	assert.Equal(t, posts[0].ID, tagged.Posts[0].ID)
+
+	// Clearing the tags of a post leaves it with none
+	require.NoError(t, tagsrepo.Set(testCtx, posts[1].ID, nil))
+	postTags, err = tagsrepo.ForPosts(testCtx, posts[1].ID)
+	require.NoError(t, err)
+	assert.Empty(t, postTags[posts[1].ID])
+}
+
+func stripTagIDs(tags []domain.Tag) []domain.Tag {
+	for i := range tags {
+		tags[i].ID = ""
+	}
+	return tags
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor creates a transactor, which runs functions in a database transaction that the repositories called
+// with the context it hands them take part in
+func NewTransactor(db *gorm.DB) *transactor {
+	return &transactor{db: db}
+}
+
+// Transaction runs fn in a transaction, committed if fn returns nil and rolled back otherwise. Transactions run
+// within one another are nested as savepoints
+func (t *transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return conn(ctx, t.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn returns the transaction ctx is running in, or db when it is not running in one
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -2,9 +2,12 @@ package repositories
 
 import (
 	"context"
-	"math"
+	"fmt"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/victor-nach/postr-backend/internal/domain"
 )
@@ -17,57 +20,293 @@ func NewUserRepository(db *gorm.DB) *userRepository {
 	return &userRepository{db: db}
 }
 
+// Create inserts the user as a member, constraint violations such as a taken email are returned as domain errors
 func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return translateError(err)
+		}
+
+		return tx.Create(&domain.UserRole{UserID: user.ID, Role: domain.RoleMember, CreatedAt: user.CreatedAt}).Error
+	})
 }
 
 func (r *userRepository) Get(ctx context.Context, id string) (*domain.User, error) {
 	var user domain.User
-	if err := r.db.WithContext(ctx).First(&user, "id = ?", id).Error; err != nil {
+	if err := conn(ctx, r.db).First(&user, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
+// GetWithDeleted returns the user whether or not they are soft deleted
+func (r *userRepository) GetWithDeleted(ctx context.Context, id string) (*domain.User, error) {
+	var user domain.User
+	if err := conn(ctx, r.db).Unscoped().First(&user, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetByEmail returns the user registered with the email, gorm.ErrRecordNotFound if there is none
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	var user domain.User
+	if err := conn(ctx, r.db).First(&user, "email = ?", email).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Update persists the editable fields of the user, returning gorm.ErrRecordNotFound if it does not exist
+// and a domain error for constraint violations
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	result := conn(ctx, r.db).Model(user).
+		Select("firstname", "lastname", "email", "email_verified_at", "street", "city", "state", "zipcode", "handle").
+		Updates(user)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Mentioned returns the users mentioned by the handles or user ids, users that are deleted or unknown are left out
+func (r *userRepository) Mentioned(ctx context.Context, mentions domain.Mentions) ([]domain.User, error) {
+	users := []domain.User{}
+	if mentions.Empty() {
+		return users, nil
+	}
+
+	if err := conn(ctx, r.db).
+		Where("id <> ?", domain.DeletedUserID).
+		Where(conn(ctx, r.db).Where("handle IN ?", mentions.Handles).Or("id IN ?", mentions.UserIDs)).
+		Order("id").
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// MarkEmailVerified records that the user verified the email, as long as it is still theirs. Returns
+// gorm.ErrRecordNotFound if the user does not exist or has changed their email since
+func (r *userRepository) MarkEmailVerified(ctx context.Context, id string, email string, at time.Time) error {
+	result := conn(ctx, r.db).Model(&domain.User{}).
+		Where("id = ? AND email = ?", id, email).
+		Update("email_verified_at", gorm.Expr("COALESCE(email_verified_at, ?)", at))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ResetPassword replaces the password hash of the user, as long as it is still oldHash, and revokes every session
+// of theirs in the same transaction. Returns gorm.ErrRecordNotFound if the user does not exist or their password
+// has changed since
+func (r *userRepository) ResetPassword(ctx context.Context, id string, oldHash string, newHash string, at time.Time) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.User{}).
+			Where("id = ? AND password_hash = ?", id, oldHash).
+			Update("password_hash", newHash)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Model(&domain.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", at).Error
+	})
+}
+
 func (r *userRepository) Count(ctx context.Context) (int, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&domain.User{}).Count(&count).Error; err != nil {
+	if err := conn(ctx, r.db).Model(&domain.User{}).Where("id <> ?", domain.DeletedUserID).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return int(count), nil
 }
 
-func (r *userRepository) List(ctx context.Context, pageNumber int, pageSize int) (domain.PaginatedUsers, error) {
-	var users []domain.User
-	var total int64
+// userSortColumns maps the sortable user fields to their columns
+var userSortColumns = map[domain.UserSortField]string{
+	domain.UserSortCreatedAt: "created_at",
+	domain.UserSortLastname:  "lastname",
+}
 
-	// Get total count of users
-	if err := r.db.WithContext(ctx).Model(&domain.User{}).Count(&total).Error; err != nil {
-		return domain.PaginatedUsers{}, err
+// List pages through the users matching the query, by page number or by a cursor on (sort column, id)
+func (r *userRepository) List(ctx context.Context, query domain.UserQuery) (domain.PaginatedUsers, error) {
+	db := conn(ctx, r.db)
+	if query.IncludeDeleted {
+		db = db.Unscoped()
 	}
 
-	// Get paginated records
-	offset := (pageNumber - 1) * pageSize
-	if err := r.db.WithContext(ctx).Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
+	// The tombstone user is not a real user, so it is left out of listings
+	db = db.Model(&domain.User{}).Where("id <> ?", domain.DeletedUserID)
+
+	if query.City != "" {
+		db = db.Where("city = ? COLLATE NOCASE", query.City)
+	}
+	if query.State != "" {
+		db = db.Where("state = ? COLLATE NOCASE", query.State)
+	}
+	if query.Zipcode != "" {
+		db = db.Where("zipcode = ? COLLATE NOCASE", query.Zipcode)
+	}
+	if query.ZipcodePrefix != "" {
+		db = db.Where(`zipcode LIKE ? ESCAPE '\'`, escapeLike(query.ZipcodePrefix)+"%")
+	}
+	if query.EmailDomain != "" {
+		db = db.Where(`email LIKE ? ESCAPE '\'`, "%@"+escapeLike(query.EmailDomain))
+	}
+	if query.NamePrefix != "" {
+		prefix := escapeLike(query.NamePrefix) + "%"
+		db = db.Where(`(firstname LIKE ? ESCAPE '\' OR lastname LIKE ? ESCAPE '\')`, prefix, prefix)
+	}
+	if !query.CreatedFrom.IsZero() {
+		db = db.Where("created_at >= ?", query.CreatedFrom.UTC())
+	}
+	if !query.CreatedTo.IsZero() {
+		db = db.Where("created_at <= ?", query.CreatedTo.UTC())
+	}
+
+	column, ok := userSortColumns[query.SortBy]
+	if !ok {
+		column = userSortColumns[domain.UserSortCreatedAt]
+	}
+	key := sortKey{table: "users", column: column, desc: query.SortDesc}
+
+	users, pagination, err := paginate(db, query.Page, key, func(user domain.User) (string, string) {
+		if column == "lastname" {
+			return user.Lastname, user.ID
+		}
+		return timeKey(user.CreatedAt), user.ID
+	})
+	if err != nil {
 		return domain.PaginatedUsers{}, err
 	}
 
-	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
-	paginated := domain.PaginatedUsers{
-		Pagination: domain.Pagination{
-			CurrentPage: pageNumber,
-			TotalPages:  totalPages,
-			TotalSize:   int(total),
-		},
-		Users: users,
+	return domain.PaginatedUsers{Pagination: pagination, Users: users}, nil
+}
+
+// Delete soft deletes the user and handles their posts according to the policy, all in one transaction.
+// Cascaded posts are stamped with the user's deletion time, so Restore can tell them apart from posts
+// deleted earlier on
+func (r *userRepository) Delete(ctx context.Context, id string, policy domain.UserDeletePolicy) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var user domain.User
+		if err := tx.First(&user, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		deletedAt := tx.NowFunc()
+
+		switch policy {
+		case domain.UserDeleteRestrict:
+			var count int64
+			if err := tx.Model(&domain.Post{}).Where("user_id = ?", id).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return domain.ErrUserHasPosts
+			}
+
+		case domain.UserDeleteCascade:
+			if err := tx.Model(&domain.Post{}).Where("user_id = ?", id).UpdateColumn("deleted_at", deletedAt).Error; err != nil {
+				return err
+			}
+
+		case domain.UserDeleteReassign:
+			tombstone := deletedUser()
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tombstone).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&domain.Post{}).Where("user_id = ?", id).Update("user_id", domain.DeletedUserID).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&domain.Comment{}).Where("user_id = ?", id).Update("user_id", domain.DeletedUserID).Error; err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("unknown user delete policy %q", policy)
+		}
+
+		return tx.Model(&user).UpdateColumn("deleted_at", deletedAt).Error
+	})
+}
+
+// Restore brings back a soft deleted user along with the posts that were deleted with them.
+// Restoring a user that is not deleted changes nothing
+func (r *userRepository) Restore(ctx context.Context, id string) (*domain.User, error) {
+	var user domain.User
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().First(&user, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if !user.DeletedAt.Valid {
+			return nil
+		}
+
+		// Compare with the stored value, which is exactly what the cascade wrote
+		if err := tx.Unscoped().Model(&domain.Post{}).
+			Where("user_id = ? AND deleted_at = (SELECT deleted_at FROM users WHERE id = ?)", id, id).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		user.DeletedAt = gorm.DeletedAt{}
+		return tx.Unscoped().Model(&user).UpdateColumn("deleted_at", nil).Error
+	})
+	if err != nil {
+		return nil, err
 	}
+	return &user, nil
+}
 
-	return paginated, nil
+// userRows hold the rows belonging to a user, by the column naming the user, which go along with it when it is purged
+var userRows = []struct {
+	model  any
+	column string
+}{
+	{&domain.Comment{}, "user_id"},
+	{&domain.Reaction{}, "user_id"},
+	{&domain.Follow{}, "follower_id"},
+	{&domain.Follow{}, "followee_id"},
+	{&domain.Mention{}, "user_id"},
+}
+
+// Purge permanently deletes the users soft deleted before the given time, along with their comments, reactions,
+// follows and the mentions of them. Users still owning posts, deleted or not, are kept until those posts are purged
+func (r *userRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	const purgeable = "SELECT id FROM users WHERE deleted_at < ? AND NOT EXISTS (SELECT 1 FROM posts WHERE posts.user_id = users.id)"
+
+	var purged int64
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, rows := range userRows {
+			if err := tx.Where(rows.column+" IN ("+purgeable+")", before).Delete(rows.model).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Unscoped().
+			Where("deleted_at < ?", before).
+			Where("NOT EXISTS (SELECT 1 FROM posts WHERE posts.user_id = users.id)").
+			Delete(&domain.User{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
 }
 
 func (r *userRepository) Validate(ctx context.Context, userID string) error {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+	if err := conn(ctx, r.db).Model(&domain.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
 		return err
 	}
 	if count == 0 {
@@ -75,3 +314,80 @@ func (r *userRepository) Validate(ctx context.Context, userID string) error {
 	}
 	return nil
 }
+
+// ListRoles returns the roles granted to the user
+func (r *userRepository) ListRoles(ctx context.Context, userID string) ([]domain.Role, error) {
+	roles := []domain.Role{}
+	if err := conn(ctx, r.db).Model(&domain.UserRole{}).
+		Where("user_id = ?", userID).
+		Order("role").
+		Pluck("role", &roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GrantRole grants the role to the user, granting a role the user already has changes nothing.
+// It returns gorm.ErrRecordNotFound if the user does not exist
+func (r *userRepository) GrantRole(ctx context.Context, userID string, role domain.Role) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var user domain.User
+		if err := tx.Select("id").First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+
+		grant := domain.UserRole{UserID: userID, Role: role, CreatedAt: tx.NowFunc()}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&grant).Error
+	})
+}
+
+// RevokeRole takes the role away from the user, revoking a role the user does not have changes nothing.
+// It returns gorm.ErrRecordNotFound if the user does not exist and domain.ErrLastAdmin rather than
+// leaving no admin behind
+func (r *userRepository) RevokeRole(ctx context.Context, userID string, role domain.Role) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var user domain.User
+		if err := tx.Select("id").First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("user_id = ? AND role = ?", userID, role).Delete(&domain.UserRole{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if role != domain.RoleAdmin || result.RowsAffected == 0 {
+			return nil
+		}
+
+		// Returning an error rolls the revoke back
+		var admins int64
+		if err := tx.Model(&domain.UserRole{}).
+			Joins("JOIN users ON users.id = user_roles.user_id AND users.deleted_at IS NULL").
+			Where("user_roles.role = ?", domain.RoleAdmin).
+			Count(&admins).Error; err != nil {
+			return err
+		}
+		if admins == 0 {
+			return domain.ErrLastAdmin
+		}
+		return nil
+	})
+}
+
+// deletedUser is the tombstone user that owns the posts of reassigned deleted users
+func deletedUser() domain.User {
+	return domain.User{
+		ID:        domain.DeletedUserID,
+		Firstname: "Deleted",
+		Lastname:  "User",
+		Email:     "deleted-user@postr.invalid",
+		CreatedAt: time.Now(),
+	}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes the LIKE wildcards in s, for use with ESCAPE '\'
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
@@ -45,6 +45,32 @@ func TestUserRepository_Create(t *testing.T) {
 	assert.Equal(t, user.Zipcode, found.Zipcode)
 }
 
+func TestUserRepository_Create_DuplicateEmail(t *testing.T) {
+	cleanUsers(t)
+
+	user := domain.User{ID: uuid.NewString(), Firstname: "First", Lastname: "User", Email: "taken@example.com", CreatedAt: time.Now()}
+	require.NoError(t, usersrepo.Create(testCtx, &user))
+
+	// Soft deleted users keep their email until they are purged
+	require.NoError(t, usersrepo.Delete(testCtx, user.ID, domain.UserDeleteRestrict))
+
+	duplicate := domain.User{ID: uuid.NewString(), Firstname: "Second", Lastname: "User", Email: "taken@example.com", CreatedAt: time.Now()}
+	err := usersrepo.Create(testCtx, &duplicate)
+	require.ErrorIs(t, err, domain.ErrEmailAlreadyRegistered)
+
+	var derr domain.DomainError
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, map[string]string{"email": "is already taken"}, derr.FieldErrors)
+
+	// Changing another user's email to a taken one is refused the same way
+	other := domain.User{ID: uuid.NewString(), Firstname: "Third", Lastname: "User", Email: "free@example.com", CreatedAt: time.Now()}
+	require.NoError(t, usersrepo.Create(testCtx, &other))
+
+	other.Email = "taken@example.com"
+	err = usersrepo.Update(testCtx, &other)
+	require.ErrorIs(t, err, domain.ErrEmailAlreadyRegistered)
+}
+
 func TestUserRepository_Get(t *testing.T) {
 	cleanUsers(t)
 
@@ -79,6 +105,134 @@ func TestUserRepository_Get(t *testing.T) {
 	assert.Equal(t, gorm.ErrRecordNotFound, err)
 }
 
+func TestUserRepository_GetByEmail(t *testing.T) {
+	cleanUsers(t)
+
+	user := domain.User{
+		ID:           uuid.NewString(),
+		Firstname:    "Login",
+		Lastname:     "Test",
+		Email:        "login@example.com",
+		PasswordHash: "$2a$10$hash",
+		CreatedAt:    time.Now(),
+	}
+	require.NoError(t, usersrepo.Create(testCtx, &user))
+
+	retrieved, err := usersrepo.GetByEmail(testCtx, "login@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, retrieved.ID)
+	assert.Equal(t, user.PasswordHash, retrieved.PasswordHash)
+
+	// Deleted users cannot be found by their email
+	require.NoError(t, usersrepo.Delete(testCtx, user.ID, domain.UserDeleteRestrict))
+	_, err = usersrepo.GetByEmail(testCtx, "login@example.com")
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+func TestUserRepository_Update(t *testing.T) {
+	cleanUsers(t)
+
+	user := domain.User{
+		ID:        uuid.NewString(),
+		Firstname: "Update",
+		Lastname:  "Test",
+		Email:     "update@example.com",
+		Street:    "1 Old Rd",
+		City:      "Oldtown",
+		State:     "OT",
+		Zipcode:   "11111",
+		CreatedAt: time.Now(),
+	}
+	err := usersrepo.Create(testCtx, &user)
+	require.NoError(t, err)
+
+	handle := "new_handle"
+	user.Street = "2 New Rd"
+	user.City = "Newtown"
+	user.Handle = &handle
+	err = usersrepo.Update(testCtx, &user)
+	require.NoError(t, err)
+
+	var found domain.User
+	err = db.WithContext(testCtx).First(&found, "id = ?", user.ID).Error
+	require.NoError(t, err)
+	assert.Equal(t, "2 New Rd", found.Street)
+	assert.Equal(t, "Newtown", found.City)
+	assert.Equal(t, &handle, found.Handle)
+	assert.Equal(t, user.Email, found.Email)
+
+	// Non-existent user
+	err = usersrepo.Update(testCtx, &domain.User{ID: "non-existent-id"})
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+func TestUserRepository_MarkEmailVerified(t *testing.T) {
+	cleanUsers(t)
+
+	user := domain.User{ID: uuid.NewString(), Firstname: "Verify", Lastname: "Test", Email: "verify@example.com", CreatedAt: time.Now()}
+	require.NoError(t, usersrepo.Create(testCtx, &user))
+
+	found, err := usersrepo.Get(testCtx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, found.EmailVerified())
+
+	// A token sent to another email does not verify the user's
+	err = usersrepo.MarkEmailVerified(testCtx, user.ID, "previous@example.com", time.Now())
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	verifiedAt := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, usersrepo.MarkEmailVerified(testCtx, user.ID, user.Email, verifiedAt))
+
+	// Verifying again keeps the first time
+	require.NoError(t, usersrepo.MarkEmailVerified(testCtx, user.ID, user.Email, verifiedAt.Add(time.Hour)))
+
+	found, err = usersrepo.Get(testCtx, user.ID)
+	require.NoError(t, err)
+	require.True(t, found.EmailVerified())
+	assert.True(t, verifiedAt.Equal(*found.EmailVerifiedAt))
+
+	// Changing the email resets it
+	email := "verify@example.org"
+	domain.UserUpdate{Email: &email}.Apply(found)
+	require.NoError(t, usersrepo.Update(testCtx, found))
+
+	found, err = usersrepo.Get(testCtx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, found.EmailVerified())
+
+	err = usersrepo.MarkEmailVerified(testCtx, "non-existent-id", email, time.Now())
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+func TestUserRepository_ResetPassword(t *testing.T) {
+	cleanUsers(t)
+
+	user := domain.User{ID: uuid.NewString(), Firstname: "Reset", Lastname: "Test", Email: "reset@example.com", PasswordHash: "old-hash", CreatedAt: time.Now()}
+	require.NoError(t, usersrepo.Create(testCtx, &user))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	session := domain.Session{ID: uuid.NewString(), UserID: user.ID, RefreshTokenHash: uuid.NewString(), CreatedAt: now, LastUsedAt: now, ExpiresAt: now.Add(time.Hour)}
+	require.NoError(t, sessionsrepo.Create(testCtx, &session))
+
+	require.NoError(t, usersrepo.ResetPassword(testCtx, user.ID, "old-hash", "new-hash", now))
+
+	found, err := usersrepo.Get(testCtx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "new-hash", found.PasswordHash)
+
+	// Resetting the password logs the user out everywhere
+	sessions, err := sessionsrepo.ListActive(testCtx, user.ID, now)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	// The password has changed since
+	err = usersrepo.ResetPassword(testCtx, user.ID, "old-hash", "other-hash", now)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	err = usersrepo.ResetPassword(testCtx, "non-existent-id", "", "new-hash", now)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
 func TestUserRepository_Count(t *testing.T) {
 	cleanUsers(t)
 
@@ -127,7 +281,7 @@ func TestUserRepository_List(t *testing.T) {
 	require.NoError(t, err)
 
 	// List page 1 with page size 2
-	paginated, err := usersrepo.List(testCtx, 1, 2)
+	paginated, err := usersrepo.List(testCtx, domain.UserQuery{Page: domain.PageRequest{PageNumber: 1, PageSize: 2}})
 	require.NoError(t, err)
 	assert.Equal(t, 1, paginated.Pagination.CurrentPage)
 	assert.Equal(t, 3, paginated.Pagination.TotalPages)
@@ -135,12 +289,282 @@ func TestUserRepository_List(t *testing.T) {
 	assert.Len(t, paginated.Users, 2)
 
 	// List page 2
-	paginated, err = usersrepo.List(testCtx, 2, 2)
+	paginated, err = usersrepo.List(testCtx, domain.UserQuery{Page: domain.PageRequest{PageNumber: 2, PageSize: 2}})
 	require.NoError(t, err)
 	assert.Equal(t, 2, paginated.Pagination.CurrentPage)
 	assert.Len(t, paginated.Users, 2)
 }
 
+func TestUserRepository_ListKeyset(t *testing.T) {
+	cleanUsers(t)
+
+	start := time.Date(2025, 2, 9, 23, 0, 0, 0, time.Local)
+	newUser := func(i int, createdAt time.Time) domain.User {
+		return domain.User{
+			ID:        uuid.NewString(),
+			Firstname: fmt.Sprintf("User%d", i),
+			Lastname:  "Test",
+			Email:     fmt.Sprintf("keyset%d@example.com", i),
+			CreatedAt: createdAt,
+		}
+	}
+
+	var users []domain.User
+	for i := 1; i <= 5; i++ {
+		users = append(users, newUser(i, start.Add(time.Duration(i)*time.Minute)))
+	}
+	require.NoError(t, db.WithContext(testCtx).Create(&users).Error)
+
+	query := domain.UserQuery{Page: domain.PageRequest{PageSize: 2, Keyset: true}}
+	first, err := usersrepo.List(testCtx, query)
+	require.NoError(t, err)
+	require.Len(t, first.Users, 2)
+	assert.Equal(t, users[0].ID, first.Users[0].ID)
+	assert.Empty(t, first.Pagination.PrevCursor)
+	require.NotEmpty(t, first.Pagination.NextCursor)
+
+	// A user added before the cursor mid-scroll does not shift the following pages
+	require.NoError(t, db.WithContext(testCtx).Create(&[]domain.User{newUser(0, start)}).Error)
+
+	var seen []string
+	for _, user := range first.Users {
+		seen = append(seen, user.ID)
+	}
+	query.Page.Cursor = first.Pagination.NextCursor
+	var last domain.PaginatedUsers
+	for query.Page.Cursor != "" {
+		last, err = usersrepo.List(testCtx, query)
+		require.NoError(t, err)
+		for _, user := range last.Users {
+			seen = append(seen, user.ID)
+		}
+		query.Page.Cursor = last.Pagination.NextCursor
+	}
+
+	var expected []string
+	for _, user := range users {
+		expected = append(expected, user.ID)
+	}
+	assert.Equal(t, expected, seen)
+
+	// The previous cursor of the last page leads back to the page before it
+	query.Page.Cursor = last.Pagination.PrevCursor
+	previous, err := usersrepo.List(testCtx, query)
+	require.NoError(t, err)
+	require.Len(t, previous.Users, 2)
+	assert.Equal(t, users[2].ID, previous.Users[0].ID)
+	assert.Equal(t, users[3].ID, previous.Users[1].ID)
+}
+
+func TestUserRepository_ListFiltered(t *testing.T) {
+	cleanUsers(t)
+
+	start := time.Date(2025, 2, 1, 12, 0, 0, 0, time.Local)
+	users := []domain.User{
+		{ID: uuid.NewString(), Firstname: "Michael", Lastname: "Johnson", Email: "michael@acme.corp", City: "Chicago", State: "IL", Zipcode: "60007", CreatedAt: start},
+		{ID: uuid.NewString(), Firstname: "Emily", Lastname: "Williams", Email: "emily@acme.corp", City: "Chicago", State: "IL", Zipcode: "60601", CreatedAt: start.AddDate(0, 0, 1)},
+		{ID: uuid.NewString(), Firstname: "John", Lastname: "Adams", Email: "john@example.com", City: "Boston", State: "MA", Zipcode: "02108", CreatedAt: start.AddDate(0, 0, 2)},
+		{ID: uuid.NewString(), Firstname: "Ann", Lastname: "Jo_nes", Email: "ann@acme_corp.com", City: "Austin", State: "TX", Zipcode: "73301", CreatedAt: start.AddDate(0, 0, 3)},
+	}
+	require.NoError(t, db.WithContext(testCtx).Create(&users).Error)
+
+	firstnames := func(t *testing.T, query domain.UserQuery) []string {
+		query.Page = domain.PageRequest{PageNumber: 1, PageSize: 10}
+		paginated, err := usersrepo.List(testCtx, query)
+		require.NoError(t, err)
+
+		var names []string
+		for _, user := range paginated.Users {
+			names = append(names, user.Firstname)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name     string
+		query    domain.UserQuery
+		expected []string
+	}{
+		{"city ignores case", domain.UserQuery{City: "chicago"}, []string{"Michael", "Emily"}},
+		{"state", domain.UserQuery{State: "MA"}, []string{"John"}},
+		{"zipcode", domain.UserQuery{Zipcode: "60601"}, []string{"Emily"}},
+		{"zipcode prefix", domain.UserQuery{ZipcodePrefix: "60"}, []string{"Michael", "Emily"}},
+		{"email domain", domain.UserQuery{EmailDomain: "acme.corp"}, []string{"Michael", "Emily"}},
+		{"email domain is not a pattern", domain.UserQuery{EmailDomain: "acme_corp.com"}, []string{"Ann"}},
+		{"first or last name prefix", domain.UserQuery{NamePrefix: "jo"}, []string{"Michael", "John", "Ann"}},
+		{"name prefix is not a pattern", domain.UserQuery{NamePrefix: "Jo_"}, []string{"Ann"}},
+		{"created range", domain.UserQuery{CreatedFrom: start.AddDate(0, 0, 1), CreatedTo: start.AddDate(0, 0, 2)}, []string{"Emily", "John"}},
+		{"filters combine", domain.UserQuery{City: "Chicago", NamePrefix: "Em"}, []string{"Emily"}},
+		{"by lastname", domain.UserQuery{SortBy: domain.UserSortLastname}, []string{"John", "Ann", "Michael", "Emily"}},
+		{"newest first", domain.UserQuery{SortDesc: true}, []string{"Ann", "John", "Emily", "Michael"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, firstnames(t, tt.query))
+		})
+	}
+}
+
+func TestUserRepository_Delete(t *testing.T) {
+	newUserWithPosts := func(t *testing.T, email string) (domain.User, []domain.Post) {
+		user := domain.User{
+			ID:        uuid.NewString(),
+			Firstname: "Delete",
+			Lastname:  "Test",
+			Email:     email,
+			CreatedAt: time.Now(),
+		}
+		require.NoError(t, usersrepo.Create(testCtx, &user))
+
+		posts := []domain.Post{
+			{ID: uuid.NewString(), UserID: user.ID, Title: "Post 1", Body: "Body 1", CreatedAt: time.Now()},
+			{ID: uuid.NewString(), UserID: user.ID, Title: "Post 2", Body: "Body 2", CreatedAt: time.Now()},
+		}
+		require.NoError(t, db.WithContext(testCtx).Create(&posts).Error)
+		return user, posts
+	}
+
+	countPosts := func(t *testing.T, userID string) int64 {
+		var count int64
+		require.NoError(t, db.WithContext(testCtx).Model(&domain.Post{}).Where("user_id = ?", userID).Count(&count).Error)
+		return count
+	}
+
+	t.Run("restrict refuses users with posts", func(t *testing.T) {
+		cleanUsers(t)
+		user, _ := newUserWithPosts(t, "restrict@example.com")
+
+		err := usersrepo.Delete(testCtx, user.ID, domain.UserDeleteRestrict)
+		assert.Equal(t, domain.ErrUserHasPosts, err)
+
+		_, err = usersrepo.Get(testCtx, user.ID)
+		assert.NoError(t, err, "user should not be deleted")
+		assert.Equal(t, int64(2), countPosts(t, user.ID))
+	})
+
+	t.Run("cascade deletes posts", func(t *testing.T) {
+		cleanUsers(t)
+		user, _ := newUserWithPosts(t, "cascade@example.com")
+
+		err := usersrepo.Delete(testCtx, user.ID, domain.UserDeleteCascade)
+		require.NoError(t, err)
+
+		_, err = usersrepo.Get(testCtx, user.ID)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+		assert.Equal(t, int64(0), countPosts(t, user.ID))
+	})
+
+	t.Run("reassign moves posts and comments to the tombstone user", func(t *testing.T) {
+		cleanUsers(t)
+		user, posts := newUserWithPosts(t, "reassign@example.com")
+		comment := domain.Comment{ID: uuid.NewString(), PostID: posts[0].ID, UserID: user.ID, Body: "Mine", CreatedAt: time.Now()}
+		require.NoError(t, commentsrepo.Create(testCtx, &comment))
+
+		err := usersrepo.Delete(testCtx, user.ID, domain.UserDeleteReassign)
+		require.NoError(t, err)
+
+		_, err = usersrepo.Get(testCtx, user.ID)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+		var found domain.Post
+		require.NoError(t, db.WithContext(testCtx).First(&found, "id = ?", posts[0].ID).Error)
+		assert.Equal(t, domain.DeletedUserID, found.UserID)
+
+		foundComment, err := commentsrepo.Get(testCtx, comment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.DeletedUserID, foundComment.UserID)
+
+		// The tombstone is not counted as a user
+		count, err := usersrepo.Count(testCtx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		err := usersrepo.Delete(testCtx, "non-existent-id", domain.UserDeleteCascade)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+	})
+}
+
+func TestUserRepository_Restore(t *testing.T) {
+	cleanUsers(t)
+
+	user := domain.User{ID: uuid.NewString(), Firstname: "Restore", Lastname: "Test", Email: "restore@example.com", CreatedAt: time.Now()}
+	require.NoError(t, usersrepo.Create(testCtx, &user))
+
+	posts := []domain.Post{
+		{ID: uuid.NewString(), UserID: user.ID, Title: "Deleted earlier", Body: "Body", CreatedAt: time.Now()},
+		{ID: uuid.NewString(), UserID: user.ID, Title: "Deleted with the user", Body: "Body", CreatedAt: time.Now()},
+	}
+	require.NoError(t, db.WithContext(testCtx).Create(&posts).Error)
+	require.NoError(t, postsrepo.Delete(testCtx, posts[0].ID))
+
+	require.NoError(t, usersrepo.Delete(testCtx, user.ID, domain.UserDeleteCascade))
+
+	// Deleted users only show up when asked for
+	listed, err := usersrepo.List(testCtx, domain.UserQuery{Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	assert.Empty(t, listed.Users)
+
+	listed, err = usersrepo.List(testCtx, domain.UserQuery{IncludeDeleted: true, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}})
+	require.NoError(t, err)
+	require.Len(t, listed.Users, 1)
+	assert.True(t, listed.Users[0].DeletedAt.Valid)
+
+	restored, err := usersrepo.Restore(testCtx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, restored.DeletedAt.Valid)
+
+	_, err = usersrepo.Get(testCtx, user.ID)
+	require.NoError(t, err)
+
+	// Only the posts deleted along with the user come back
+	_, err = postsrepo.Get(testCtx, posts[0].ID)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+	_, err = postsrepo.Get(testCtx, posts[1].ID)
+	assert.NoError(t, err)
+
+	// Restoring a live user changes nothing
+	restored, err = usersrepo.Restore(testCtx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, restored.ID)
+
+	_, err = usersrepo.Restore(testCtx, "non-existent-id")
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+func TestUserRepository_Purge(t *testing.T) {
+	cleanUsers(t)
+
+	newDeletedUser := func(t *testing.T, email string, deletedAt time.Time) domain.User {
+		user := domain.User{ID: uuid.NewString(), Firstname: "Purge", Lastname: "Test", Email: email, CreatedAt: time.Now()}
+		require.NoError(t, usersrepo.Create(testCtx, &user))
+		require.NoError(t, db.Model(&user).UpdateColumn("deleted_at", deletedAt).Error)
+		return user
+	}
+
+	now := time.Now()
+	expired := newDeletedUser(t, "expired@example.com", now.Add(-48*time.Hour))
+	recent := newDeletedUser(t, "recent@example.com", now.Add(-time.Hour))
+	withPosts := newDeletedUser(t, "posts@example.com", now.Add(-48*time.Hour))
+	post := domain.Post{ID: uuid.NewString(), UserID: withPosts.ID, Title: "Post", Body: "Body", CreatedAt: time.Now()}
+	require.NoError(t, db.Create(&post).Error)
+
+	purged, err := usersrepo.Purge(testCtx, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	exists := func(id string) bool {
+		var count int64
+		require.NoError(t, db.Unscoped().Model(&domain.User{}).Where("id = ?", id).Count(&count).Error)
+		return count > 0
+	}
+	assert.False(t, exists(expired.ID))
+	assert.True(t, exists(recent.ID), "users deleted within the retention are kept")
+	assert.True(t, exists(withPosts.ID), "users are kept until their posts are purged")
+}
+
 func TestUserRepository_Validate(t *testing.T) {
 	cleanUsers(t)
 
@@ -164,7 +588,75 @@ func TestUserRepository_Validate(t *testing.T) {
 	assert.Equal(t, domain.ErrUserNotFound, err)
 }
 
+func TestUserRepository_ListRoles(t *testing.T) {
+	cleanUsers(t)
+
+	user := domain.User{ID: uuid.NewString(), Firstname: "Role", Lastname: "Test", Email: "roles@example.com", CreatedAt: time.Now()}
+	require.NoError(t, usersrepo.Create(testCtx, &user))
+
+	// Every user is a member from sign up
+	roles, err := usersrepo.ListRoles(testCtx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Role{domain.RoleMember}, roles)
+
+	require.NoError(t, db.Create(&domain.UserRole{UserID: user.ID, Role: domain.RoleAdmin, CreatedAt: time.Now()}).Error)
+
+	roles, err = usersrepo.ListRoles(testCtx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Role{domain.RoleAdmin, domain.RoleMember}, roles)
+}
+
+func TestUserRepository_GrantRevokeRole(t *testing.T) {
+	cleanUsers(t)
+
+	alice := domain.User{ID: uuid.NewString(), Firstname: "Alice", Lastname: "Admin", Email: "alice@example.com", CreatedAt: time.Now()}
+	bob := domain.User{ID: uuid.NewString(), Firstname: "Bob", Lastname: "Admin", Email: "bob@example.com", CreatedAt: time.Now()}
+	require.NoError(t, usersrepo.Create(testCtx, &alice))
+	require.NoError(t, usersrepo.Create(testCtx, &bob))
+
+	// Granting twice is a no-op
+	require.NoError(t, usersrepo.GrantRole(testCtx, alice.ID, domain.RoleAdmin))
+	require.NoError(t, usersrepo.GrantRole(testCtx, alice.ID, domain.RoleAdmin))
+	require.NoError(t, usersrepo.GrantRole(testCtx, bob.ID, domain.RoleAdmin))
+
+	roles, err := usersrepo.ListRoles(testCtx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Role{domain.RoleAdmin, domain.RoleMember}, roles)
+
+	err = usersrepo.GrantRole(testCtx, "non-existent-id", domain.RoleAdmin)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	require.NoError(t, usersrepo.RevokeRole(testCtx, alice.ID, domain.RoleMember))
+	require.NoError(t, usersrepo.RevokeRole(testCtx, alice.ID, domain.RoleMember))
+	require.NoError(t, usersrepo.RevokeRole(testCtx, alice.ID, domain.RoleAdmin))
+
+	roles, err = usersrepo.ListRoles(testCtx, alice.ID)
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+
+	err = usersrepo.RevokeRole(testCtx, "non-existent-id", domain.RoleMember)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// Bob is the last admin, the revoke is rolled back
+	err = usersrepo.RevokeRole(testCtx, bob.ID, domain.RoleAdmin)
+	assert.Equal(t, domain.ErrLastAdmin, err)
+
+	roles, err = usersrepo.ListRoles(testCtx, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Role{domain.RoleAdmin, domain.RoleMember}, roles)
+
+	// Deleted admins do not count
+	require.NoError(t, usersrepo.GrantRole(testCtx, alice.ID, domain.RoleAdmin))
+	require.NoError(t, db.Delete(&alice).Error)
+
+	err = usersrepo.RevokeRole(testCtx, bob.ID, domain.RoleAdmin)
+	assert.Equal(t, domain.ErrLastAdmin, err)
+}
+
 func cleanUsers(t *testing.T) {
-	err := db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&domain.User{}).Error
+	err := db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&domain.UserRole{}).Error
+	require.NoError(t, err)
+
+	err = db.Unscoped().Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&domain.User{}).Error
 	require.NoError(t, err)
 }
\ No newline at end of file
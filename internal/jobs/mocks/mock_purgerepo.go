@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/victor-nach/postr-backend/internal/jobs (interfaces: purgeRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mocks/mock_purgerepo.go -package=mocks github.com/victor-nach/postr-backend/internal/jobs purgeRepo
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockpurgeRepo is a mock of purgeRepo interface.
+type MockpurgeRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockpurgeRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockpurgeRepoMockRecorder is the mock recorder for MockpurgeRepo.
+type MockpurgeRepoMockRecorder struct {
+	mock *MockpurgeRepo
+}
+
+// NewMockpurgeRepo creates a new mock instance.
+func NewMockpurgeRepo(ctrl *gomock.Controller) *MockpurgeRepo {
+	mock := &MockpurgeRepo{ctrl: ctrl}
+	mock.recorder = &MockpurgeRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockpurgeRepo) EXPECT() *MockpurgeRepoMockRecorder {
+	return m.recorder
+}
+
+// Purge mocks base method.
+func (m *MockpurgeRepo) Purge(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockpurgeRepoMockRecorder) Purge(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockpurgeRepo)(nil).Purge), ctx, before)
+}
@@ -0,0 +1,97 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+//go:generate mockgen -destination=./mocks/mock_purgerepo.go -package=mocks github.com/victor-nach/postr-backend/internal/jobs purgeRepo
+type purgeRepo interface {
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Purger permanently deletes soft deleted posts and users, ended sessions and forgotten login failures, once
+// they are older than the retention period
+type Purger struct {
+	postsRepo     purgeRepo
+	usersRepo     purgeRepo
+	sessionsRepo  purgeRepo
+	throttlesRepo purgeRepo
+	retention     time.Duration
+	interval      time.Duration
+	now           func() time.Time
+	logger        *zap.Logger
+}
+
+func NewPurger(postsRepo purgeRepo, usersRepo purgeRepo, sessionsRepo purgeRepo, throttlesRepo purgeRepo, retention time.Duration, interval time.Duration, logger *zap.Logger) *Purger {
+	logger = logger.With(zap.String("package", "jobs"))
+
+	return &Purger{
+		postsRepo:     postsRepo,
+		usersRepo:     usersRepo,
+		sessionsRepo:  sessionsRepo,
+		throttlesRepo: throttlesRepo,
+		retention:     retention,
+		interval:      interval,
+		now:           time.Now,
+		logger:        logger,
+	}
+}
+
+// Run purges once straight away and then every interval, until the context is done
+func (p *Purger) Run(ctx context.Context) {
+	logr := p.logger.With(zap.String("method", "Run"))
+	logr.Info("Purge job started", zap.Duration("retention", p.retention), zap.Duration("interval", p.interval))
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Purge(ctx); err != nil {
+			logr.Error("Error purging deleted records", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			logr.Info("Purge job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge deletes the posts and then the users that were soft deleted longer than the retention period ago, along
+// with the sessions that ended and the login throttles last touched that long ago. Posts go first, since users
+// are only purged once none of their posts are left
+func (p *Purger) Purge(ctx context.Context) error {
+	logr := p.logger.With(zap.String("method", "Purge"))
+
+	before := p.now().Add(-p.retention)
+
+	posts, err := p.postsRepo.Purge(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	users, err := p.usersRepo.Purge(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := p.sessionsRepo.Purge(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	throttles, err := p.throttlesRepo.Purge(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	if posts > 0 || users > 0 || sessions > 0 || throttles > 0 {
+		logr.Info("Deleted records purged", zap.Time("before", before), zap.Int64("posts", posts), zap.Int64("users", users), zap.Int64("sessions", sessions), zap.Int64("login_throttles", throttles))
+	}
+	return nil
+}
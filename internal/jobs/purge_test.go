@@ -0,0 +1,78 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/jobs/mocks"
+)
+
+func TestPurger_Purge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpurgeRepo(ctrl)
+	mockUsersRepo := mocks.NewMockpurgeRepo(ctrl)
+	mockSessionsRepo := mocks.NewMockpurgeRepo(ctrl)
+	mockThrottlesRepo := mocks.NewMockpurgeRepo(ctrl)
+
+	purger := NewPurger(mockPostsRepo, mockUsersRepo, mockSessionsRepo, mockThrottlesRepo, 24*time.Hour, time.Hour, zap.NewNop())
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	purger.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	before := now.Add(-24 * time.Hour)
+
+	// Posts are purged before users
+	gomock.InOrder(
+		mockPostsRepo.EXPECT().Purge(ctx, before).Return(int64(3), nil),
+		mockUsersRepo.EXPECT().Purge(ctx, before).Return(int64(1), nil),
+		mockSessionsRepo.EXPECT().Purge(ctx, before).Return(int64(5), nil),
+		mockThrottlesRepo.EXPECT().Purge(ctx, before).Return(int64(2), nil),
+	)
+	require.NoError(t, purger.Purge(ctx))
+
+	// Users are left alone when purging posts fails
+	mockPostsRepo.EXPECT().Purge(ctx, before).Return(int64(0), errors.New("database is locked"))
+	require.Error(t, purger.Purge(ctx))
+}
+
+func TestPurger_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpurgeRepo(ctrl)
+	mockUsersRepo := mocks.NewMockpurgeRepo(ctrl)
+	mockSessionsRepo := mocks.NewMockpurgeRepo(ctrl)
+	mockThrottlesRepo := mocks.NewMockpurgeRepo(ctrl)
+
+	purger := NewPurger(mockPostsRepo, mockUsersRepo, mockSessionsRepo, mockThrottlesRepo, time.Hour, time.Hour, zap.NewNop())
+
+	// The first purge runs straight away, and Run returns once the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	mockPostsRepo.EXPECT().Purge(gomock.Any(), gomock.Any()).Return(int64(0), nil)
+	mockUsersRepo.EXPECT().Purge(gomock.Any(), gomock.Any()).Return(int64(0), nil)
+	mockSessionsRepo.EXPECT().Purge(gomock.Any(), gomock.Any()).Return(int64(0), nil)
+	mockThrottlesRepo.EXPECT().Purge(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, time.Time) (int64, error) {
+		cancel()
+		return 0, nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		purger.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the context was cancelled")
+	}
+}
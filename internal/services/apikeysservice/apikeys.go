@@ -0,0 +1,196 @@
+package apikeysservice
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+const (
+	// keyPrefix marks postr API keys, making leaked ones easy to search for
+	keyPrefix = "postr_"
+	// keyBytes of randomness make up each key
+	keyBytes = 32
+	// displayLength is how much of the key is kept in the clear to tell keys apart
+	displayLength = len(keyPrefix) + 6
+)
+
+type service struct {
+	apiKeysRepo apiKeysRepo
+	usersRepo   usersRepo
+	now         func() time.Time
+	logger      *zap.Logger
+}
+
+func New(apiKeysRepo apiKeysRepo, usersRepo usersRepo, logger *zap.Logger, opts ...Option) domain.APIKeyService {
+	logger = logger.With(zap.String("package", "apikeysservice"))
+
+	svc := &service{
+		apiKeysRepo: apiKeysRepo,
+		usersRepo:   usersRepo,
+		now:         time.Now,
+		logger:      logger,
+	}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+// Option changes how the service is set up
+type Option func(*service)
+
+// WithClock makes the service read the time from now instead of the system clock
+func WithClock(now func() time.Time) Option {
+	return func(h *service) {
+		h.now = now
+	}
+}
+
+//go:generate mockgen -destination=./mocks/mock_apikeysrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/apikeysservice apiKeysRepo
+type apiKeysRepo interface {
+	Create(ctx context.Context, apiKey *domain.APIKey) error
+	List(ctx context.Context) ([]domain.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	Revoke(ctx context.Context, id string, at time.Time) error
+	Touch(ctx context.Context, id string, at time.Time) error
+}
+
+//go:generate mockgen -destination=./mocks/mock_usersrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/apikeysservice usersRepo
+type usersRepo interface {
+	Validate(ctx context.Context, userID string) error
+	ListRoles(ctx context.Context, userID string) ([]domain.Role, error)
+}
+
+func (h *service) Create(ctx context.Context, apiKey *domain.APIKey) (string, error) {
+	logr := h.logger.With(zap.String("method", "Create"))
+
+	if err := h.usersRepo.Validate(ctx, apiKey.UserID); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			logr.Info("Owner not found", zap.String("user_id", apiKey.UserID))
+			return "", domain.ErrUserNotFound
+		}
+
+		logr.Error("Error validating owner", zap.Error(err))
+		return "", domain.ErrInternalServer
+	}
+
+	secret := make([]byte, keyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		logr.Error("Error generating key", zap.Error(err))
+		return "", domain.ErrInternalServer
+	}
+	key := keyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey.ID = uuid.NewString()
+	apiKey.Prefix = key[:displayLength]
+	apiKey.KeyHash = hashKey(key)
+	apiKey.CreatedAt = h.now()
+	apiKey.LastUsedAt = nil
+	apiKey.RevokedAt = nil
+
+	if err := h.apiKeysRepo.Create(ctx, apiKey); err != nil {
+		logr.Error("Error creating API key", zap.Error(err))
+		return "", domain.ErrInternalServer
+	}
+
+	logr.Info("API key created successfully", zap.String("id", apiKey.ID), zap.String("user_id", apiKey.UserID), zap.Any("scopes", apiKey.Scopes))
+	return key, nil
+}
+
+func (h *service) List(ctx context.Context) ([]domain.APIKey, error) {
+	logr := h.logger.With(zap.String("method", "List"))
+
+	apiKeys, err := h.apiKeysRepo.List(ctx)
+	if err != nil {
+		logr.Error("Error listing API keys", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	logr.Info("API keys listed successfully", zap.Int("count", len(apiKeys)))
+	return apiKeys, nil
+}
+
+func (h *service) Revoke(ctx context.Context, id string) error {
+	logr := h.logger.With(zap.String("method", "Revoke"))
+
+	if err := h.apiKeysRepo.Revoke(ctx, id, h.now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("API key not found", zap.String("id", id))
+			return domain.ErrAPIKeyNotFound
+		}
+
+		logr.Error("Error revoking API key", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	logr.Info("API key revoked successfully", zap.String("id", id))
+	return nil
+}
+
+func (h *service) Authenticate(ctx context.Context, key string) (domain.Identity, error) {
+	logr := h.logger.With(zap.String("method", "Authenticate"))
+
+	if !strings.HasPrefix(key, keyPrefix) {
+		logr.Info("Malformed API key")
+		return domain.Identity{}, domain.ErrInvalidAPIKey
+	}
+
+	apiKey, err := h.apiKeysRepo.GetByHash(ctx, hashKey(key))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("Unknown API key")
+			return domain.Identity{}, domain.ErrInvalidAPIKey
+		}
+
+		logr.Error("Error retrieving API key", zap.Error(err))
+		return domain.Identity{}, domain.ErrInternalServer
+	}
+
+	if apiKey.RevokedAt != nil {
+		logr.Info("Revoked API key", zap.String("api_key_id", apiKey.ID))
+		return domain.Identity{}, domain.ErrInvalidAPIKey
+	}
+
+	// Keys of owners deleted since they were minted are no longer honoured
+	if err := h.usersRepo.Validate(ctx, apiKey.UserID); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			logr.Info("API key of a deleted user", zap.String("api_key_id", apiKey.ID))
+			return domain.Identity{}, domain.ErrInvalidAPIKey
+		}
+
+		logr.Error("Error validating owner", zap.Error(err))
+		return domain.Identity{}, domain.ErrInternalServer
+	}
+
+	roles, err := h.usersRepo.ListRoles(ctx, apiKey.UserID)
+	if err != nil {
+		logr.Error("Error listing roles", zap.Error(err))
+		return domain.Identity{}, domain.ErrInternalServer
+	}
+
+	// Failing to record the use is not a reason to turn the client away
+	if err := h.apiKeysRepo.Touch(ctx, apiKey.ID, h.now()); err != nil {
+		logr.Error("Error recording API key use", zap.String("api_key_id", apiKey.ID), zap.Error(err))
+	}
+
+	return domain.Identity{UserID: apiKey.UserID, Roles: roles, APIKeyID: apiKey.ID, Scopes: apiKey.Scopes}, nil
+}
+
+// hashKey returns the hex SHA-256 of the key. Keys are random enough that a fast hash does not make
+// them any easier to guess, and it lets keys be looked up by their hash
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,126 @@
+package apikeysservice_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+	"github.com/victor-nach/postr-backend/internal/services/apikeysservice"
+	"github.com/victor-nach/postr-backend/internal/services/apikeysservice/mocks"
+)
+
+func TestService_CreateAndAuthenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeysRepo := mocks.NewMockapiKeysRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	svc := apikeysservice.New(mockAPIKeysRepo, mockUsersRepo, zap.NewNop(), apikeysservice.WithClock(func() time.Time { return now }))
+
+	ctx := context.Background()
+	apiKey := &domain.APIKey{Name: "nightly export", UserID: "u1", Scopes: domain.Scopes{domain.ScopeUsersRead}}
+
+	var stored domain.APIKey
+	mockUsersRepo.EXPECT().Validate(ctx, "u1").Return(nil)
+	mockAPIKeysRepo.EXPECT().Create(ctx, apiKey).DoAndReturn(func(ctx context.Context, k *domain.APIKey) error {
+		stored = *k
+		return nil
+	})
+
+	key, err := svc.Create(ctx, apiKey)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(key, "postr_"))
+	require.NotEmpty(t, stored.ID)
+	require.Equal(t, now, stored.CreatedAt)
+	require.True(t, strings.HasPrefix(key, stored.Prefix))
+	require.NotContains(t, stored.KeyHash, key[len("postr_"):], "only a hash of the key should be stored")
+
+	// The key authenticates its owner, limited to its scopes, and its use is recorded
+	mockAPIKeysRepo.EXPECT().GetByHash(ctx, stored.KeyHash).Return(&stored, nil)
+	mockUsersRepo.EXPECT().Validate(ctx, "u1").Return(nil)
+	mockUsersRepo.EXPECT().ListRoles(ctx, "u1").Return([]domain.Role{domain.RoleAdmin}, nil)
+	mockAPIKeysRepo.EXPECT().Touch(ctx, stored.ID, now).Return(nil)
+
+	identity, err := svc.Authenticate(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, domain.Identity{UserID: "u1", Roles: []domain.Role{domain.RoleAdmin}, APIKeyID: stored.ID, Scopes: domain.Scopes{domain.ScopeUsersRead}}, identity)
+	require.True(t, identity.Can(domain.PermUsersRead))
+	require.False(t, identity.Can(domain.PermUsersWrite))
+}
+
+func TestService_Create_OwnerNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	svc := apikeysservice.New(mocks.NewMockapiKeysRepo(ctrl), mockUsersRepo, zap.NewNop())
+
+	ctx := context.Background()
+	mockUsersRepo.EXPECT().Validate(ctx, "u1").Return(domain.ErrUserNotFound)
+
+	_, err := svc.Create(ctx, &domain.APIKey{Name: "export", UserID: "u1", Scopes: domain.Scopes{domain.ScopeReadOnly}})
+	require.Equal(t, domain.ErrUserNotFound, err)
+}
+
+func TestService_Authenticate_InvalidKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeysRepo := mocks.NewMockapiKeysRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	svc := apikeysservice.New(mockAPIKeysRepo, mockUsersRepo, zap.NewNop())
+
+	ctx := context.Background()
+	revokedAt := time.Now()
+	key := "postr_0123456789"
+	sum := sha256.Sum256([]byte(key))
+	keyHash := hex.EncodeToString(sum[:])
+
+	_, err := svc.Authenticate(ctx, "not-a-key")
+	require.Equal(t, domain.ErrInvalidAPIKey, err)
+
+	mockAPIKeysRepo.EXPECT().GetByHash(ctx, keyHash).Return(nil, gorm.ErrRecordNotFound)
+	_, err = svc.Authenticate(ctx, key)
+	require.Equal(t, domain.ErrInvalidAPIKey, err)
+
+	mockAPIKeysRepo.EXPECT().GetByHash(ctx, keyHash).Return(&domain.APIKey{ID: "k1", UserID: "u1", RevokedAt: &revokedAt}, nil)
+	_, err = svc.Authenticate(ctx, key)
+	require.Equal(t, domain.ErrInvalidAPIKey, err)
+
+	// Keys of deleted users are refused
+	mockAPIKeysRepo.EXPECT().GetByHash(ctx, keyHash).Return(&domain.APIKey{ID: "k1", UserID: "u1"}, nil)
+	mockUsersRepo.EXPECT().Validate(ctx, "u1").Return(domain.ErrUserNotFound)
+	_, err = svc.Authenticate(ctx, key)
+	require.Equal(t, domain.ErrInvalidAPIKey, err)
+
+	mockAPIKeysRepo.EXPECT().GetByHash(ctx, keyHash).Return(nil, errors.New("database is locked"))
+	_, err = svc.Authenticate(ctx, key)
+	require.Equal(t, domain.ErrInternalServer, err)
+}
+
+func TestService_Revoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeysRepo := mocks.NewMockapiKeysRepo(ctrl)
+	now := time.Now()
+	svc := apikeysservice.New(mockAPIKeysRepo, mocks.NewMockusersRepo(ctrl), zap.NewNop(), apikeysservice.WithClock(func() time.Time { return now }))
+
+	ctx := context.Background()
+	mockAPIKeysRepo.EXPECT().Revoke(ctx, "k1", now).Return(nil)
+	require.NoError(t, svc.Revoke(ctx, "k1"))
+
+	mockAPIKeysRepo.EXPECT().Revoke(ctx, "k2", now).Return(gorm.ErrRecordNotFound)
+	require.Equal(t, domain.ErrAPIKeyNotFound, svc.Revoke(ctx, "k2"))
+}
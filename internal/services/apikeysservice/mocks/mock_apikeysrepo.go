@@ -0,0 +1,115 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/victor-nach/postr-backend/internal/services/apikeysservice (interfaces: apiKeysRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mocks/mock_apikeysrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/apikeysservice apiKeysRepo
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/victor-nach/postr-backend/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockapiKeysRepo is a mock of apiKeysRepo interface.
+type MockapiKeysRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockapiKeysRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockapiKeysRepoMockRecorder is the mock recorder for MockapiKeysRepo.
+type MockapiKeysRepoMockRecorder struct {
+	mock *MockapiKeysRepo
+}
+
+// NewMockapiKeysRepo creates a new mock instance.
+func NewMockapiKeysRepo(ctrl *gomock.Controller) *MockapiKeysRepo {
+	mock := &MockapiKeysRepo{ctrl: ctrl}
+	mock.recorder = &MockapiKeysRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockapiKeysRepo) EXPECT() *MockapiKeysRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockapiKeysRepo) Create(ctx context.Context, apiKey *domain.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, apiKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockapiKeysRepoMockRecorder) Create(ctx, apiKey any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockapiKeysRepo)(nil).Create), ctx, apiKey)
+}
+
+// GetByHash mocks base method.
+func (m *MockapiKeysRepo) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", ctx, keyHash)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash.
+func (mr *MockapiKeysRepoMockRecorder) GetByHash(ctx, keyHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockapiKeysRepo)(nil).GetByHash), ctx, keyHash)
+}
+
+// List mocks base method.
+func (m *MockapiKeysRepo) List(ctx context.Context) ([]domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockapiKeysRepoMockRecorder) List(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockapiKeysRepo)(nil).List), ctx)
+}
+
+// Revoke mocks base method.
+func (m *MockapiKeysRepo) Revoke(ctx context.Context, id string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockapiKeysRepoMockRecorder) Revoke(ctx, id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockapiKeysRepo)(nil).Revoke), ctx, id, at)
+}
+
+// Touch mocks base method.
+func (m *MockapiKeysRepo) Touch(ctx context.Context, id string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Touch", ctx, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Touch indicates an expected call of Touch.
+func (mr *MockapiKeysRepoMockRecorder) Touch(ctx, id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockapiKeysRepo)(nil).Touch), ctx, id, at)
+}
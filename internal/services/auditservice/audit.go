@@ -0,0 +1,49 @@
+package auditservice
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type service struct {
+	repo   auditRepo
+	logger *zap.Logger
+}
+
+// New creates the audit service, which reads the audit log. Events are written by the services making the changes
+func New(repo auditRepo, logger *zap.Logger) domain.AuditService {
+	logger = logger.With(zap.String("package", "auditservice"))
+
+	return &service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+//go:generate mockgen -destination=./mocks/mock_auditrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/auditservice auditRepo
+type auditRepo interface {
+	List(ctx context.Context, query domain.AuditQuery) (domain.PaginatedAuditEvents, error)
+}
+
+func (h *service) List(ctx context.Context, query domain.AuditQuery) (domain.PaginatedAuditEvents, error) {
+	logr := h.logger.With(zap.String("method", "List"))
+
+	events, err := h.repo.List(ctx, query)
+	if err != nil {
+		// An unusable cursor is reported back to the caller as is
+		if errors.Is(err, domain.ErrInvalidInput) {
+			logr.Info("Invalid audit query", zap.Error(err))
+			return domain.PaginatedAuditEvents{}, err
+		}
+
+		logr.Error("Error listing audit events", zap.Error(err))
+		return domain.PaginatedAuditEvents{}, domain.ErrInternalServer
+	}
+
+	logr.Info("Audit events listed successfully", zap.Int("count", len(events.Events)))
+	return events, nil
+}
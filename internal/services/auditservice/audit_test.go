@@ -0,0 +1,44 @@
+package auditservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+	"github.com/victor-nach/postr-backend/internal/services/auditservice/mocks"
+)
+
+func TestService_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockauditRepo(ctrl)
+	svc := New(mockRepo, zap.NewNop())
+
+	ctx := context.Background()
+	query := domain.AuditQuery{Action: domain.AuditUserDelete, Page: domain.PageRequest{PageNumber: 1, PageSize: 10}}
+	events := domain.PaginatedAuditEvents{
+		Pagination: domain.Pagination{CurrentPage: 1, TotalPages: 1, TotalSize: 1},
+		Events:     []domain.AuditEvent{{ID: "e1", Action: domain.AuditUserDelete, TargetType: "user", TargetID: "u1"}},
+	}
+
+	mockRepo.EXPECT().List(ctx, query).Return(events, nil)
+	listed, err := svc.List(ctx, query)
+	require.NoError(t, err)
+	require.Equal(t, events, listed)
+
+	// An unusable cursor keeps its field errors
+	cursorErr := domain.ErrInvalidInput.WithFieldErrors(validation.Errors{"cursor": errors.New("is invalid")})
+	mockRepo.EXPECT().List(ctx, query).Return(domain.PaginatedAuditEvents{}, cursorErr)
+	_, err = svc.List(ctx, query)
+	require.Equal(t, cursorErr, err)
+
+	// Anything else is not leaked to the caller
+	mockRepo.EXPECT().List(ctx, query).Return(domain.PaginatedAuditEvents{}, errors.New("no such table: audit_events"))
+	_, err = svc.List(ctx, query)
+	require.Equal(t, domain.ErrInternalServer, err)
+}
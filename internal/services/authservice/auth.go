@@ -0,0 +1,425 @@
+package authservice
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+const (
+	tokenIssuer = "postr-backend"
+	tokenType   = "Bearer"
+
+	// refreshTokenBytes of randomness make up the secret part of each refresh token
+	refreshTokenBytes = 32
+)
+
+// dummyHash is compared against when no user has the email, so unknown emails take as long to reject as
+// wrong passwords and cannot be told apart by timing
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("postr-backend"), bcrypt.DefaultCost)
+
+// accessClaims are the claims of an access token, sid names the session it was issued to
+type accessClaims struct {
+	jwt.RegisteredClaims
+	SessionID string `json:"sid"`
+}
+
+type service struct {
+	usersRepo       usersRepo
+	sessionsRepo    sessionsRepo
+	throttlesRepo   throttlesRepo
+	secret          []byte
+	tokenTTL        time.Duration
+	refreshTokenTTL time.Duration
+	lockout         domain.LoginLockout
+	now             func() time.Time
+	logger          *zap.Logger
+}
+
+// New creates the auth service, access tokens are signed with secret and expire after tokenTTL. Sessions
+// expire refreshTokenTTL after their last refresh. Failed logins back off and lock out further logins as
+// lockout sets
+func New(usersRepo usersRepo, sessionsRepo sessionsRepo, throttlesRepo throttlesRepo, secret []byte, tokenTTL time.Duration, refreshTokenTTL time.Duration, lockout domain.LoginLockout, logger *zap.Logger, opts ...Option) domain.AuthService {
+	logger = logger.With(zap.String("package", "authservice"))
+
+	svc := &service{
+		usersRepo:       usersRepo,
+		sessionsRepo:    sessionsRepo,
+		throttlesRepo:   throttlesRepo,
+		secret:          secret,
+		tokenTTL:        tokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+		lockout:         lockout,
+		now:             time.Now,
+		logger:          logger,
+	}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+// Option changes how the service is set up
+type Option func(*service)
+
+// WithClock makes the service read the time from now instead of the system clock
+func WithClock(now func() time.Time) Option {
+	return func(h *service) {
+		h.now = now
+	}
+}
+
+//go:generate mockgen -destination=./mocks/mock_usersrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/authservice usersRepo
+type usersRepo interface {
+	Get(ctx context.Context, id string) (*domain.User, error)
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	Validate(ctx context.Context, userID string) error
+	ListRoles(ctx context.Context, userID string) ([]domain.Role, error)
+}
+
+//go:generate mockgen -destination=./mocks/mock_sessionsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/authservice sessionsRepo
+type sessionsRepo interface {
+	Create(ctx context.Context, session *domain.Session) error
+	Get(ctx context.Context, id string) (*domain.Session, error)
+	Rotate(ctx context.Context, session *domain.Session, oldHash string) error
+	ListActive(ctx context.Context, userID string, now time.Time) ([]domain.Session, error)
+	Revoke(ctx context.Context, userID string, id string, at time.Time) error
+	RevokeAll(ctx context.Context, userID string, at time.Time) error
+}
+
+// Login is held back while the account or the IP address is locked out or backing off, whether or not the
+// password is right. Unknown emails are throttled just like accounts, so lockouts tell nothing of who is registered
+func (h *service) Login(ctx context.Context, email string, password string, client domain.SessionClient) (domain.AccessToken, error) {
+	logr := h.logger.With(zap.String("method", "Login"))
+
+	keys := h.loginKeys(email, client.IPAddress)
+	if err := h.checkLockout(ctx, keys, h.now()); err != nil {
+		var locked domain.LoginLockedError
+		if errors.As(err, &locked) {
+			logr.Info("Login held back", zap.String("ip_address", client.IPAddress), zap.Duration("retry_after", locked.RetryAfter))
+			return domain.AccessToken{}, err
+		}
+
+		logr.Error("Error checking login lockout", zap.Error(err))
+		return domain.AccessToken{}, domain.ErrInternalServer
+	}
+
+	user, err := h.usersRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+			logr.Info("Login with an unknown email")
+			h.recordFailure(ctx, logr, keys, h.now())
+			return domain.AccessToken{}, domain.ErrInvalidCredentials
+		}
+
+		logr.Error("Error retrieving user", zap.Error(err))
+		return domain.AccessToken{}, domain.ErrInternalServer
+	}
+
+	// Users without a password have not set one yet and cannot sign in
+	if user.PasswordHash == "" {
+		logr.Info("Login of a user without a password", zap.String("user_id", user.ID))
+		h.recordFailure(ctx, logr, keys, h.now())
+		return domain.AccessToken{}, domain.ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		logr.Info("Login with a wrong password", zap.String("user_id", user.ID))
+		h.recordFailure(ctx, logr, keys, h.now())
+		return domain.AccessToken{}, domain.ErrInvalidCredentials
+	}
+
+	// The account starts over, the IP address does not, or signing in to an account of one's own would let
+	// a single address go on guessing the passwords of others
+	if err := h.throttlesRepo.Reset(ctx, accountKey(email)); err != nil {
+		logr.Error("Error resetting failed logins", zap.String("user_id", user.ID), zap.Error(err))
+	}
+
+	now := h.now()
+	session := &domain.Session{
+		ID:         uuid.NewString(),
+		UserID:     user.ID,
+		UserAgent:  client.UserAgent,
+		IPAddress:  client.IPAddress,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(h.refreshTokenTTL),
+	}
+
+	refreshToken, err := newRefreshToken(session)
+	if err != nil {
+		logr.Error("Error generating refresh token", zap.Error(err))
+		return domain.AccessToken{}, domain.ErrInternalServer
+	}
+
+	if err := h.sessionsRepo.Create(ctx, session); err != nil {
+		logr.Error("Error creating session", zap.Error(err))
+		return domain.AccessToken{}, domain.ErrInternalServer
+	}
+
+	token, err := h.issueTokens(session, refreshToken)
+	if err != nil {
+		logr.Error("Error signing access token", zap.Error(err))
+		return domain.AccessToken{}, domain.ErrInternalServer
+	}
+
+	logr.Info("User logged in successfully", zap.String("user_id", user.ID), zap.String("session_id", session.ID))
+	return token, nil
+}
+
+// Refresh rotates the refresh token of the session. A refresh token that is not the current one of its session
+// was either already used or stolen, the session is revoked so that neither the thief nor the user can go on with it
+func (h *service) Refresh(ctx context.Context, refreshToken string, client domain.SessionClient) (domain.AccessToken, error) {
+	logr := h.logger.With(zap.String("method", "Refresh"))
+
+	sessionID, _, ok := strings.Cut(refreshToken, ".")
+	if !ok || sessionID == "" {
+		logr.Info("Malformed refresh token")
+		return domain.AccessToken{}, domain.ErrInvalidRefreshToken
+	}
+
+	session, err := h.sessionsRepo.Get(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("Refresh token of an unknown session")
+			return domain.AccessToken{}, domain.ErrInvalidRefreshToken
+		}
+
+		logr.Error("Error retrieving session", zap.Error(err))
+		return domain.AccessToken{}, domain.ErrInternalServer
+	}
+
+	now := h.now()
+	if session.RevokedAt != nil || !now.Before(session.ExpiresAt) {
+		logr.Info("Refresh token of an inactive session", zap.String("session_id", session.ID))
+		return domain.AccessToken{}, domain.ErrInvalidRefreshToken
+	}
+
+	oldHash := session.RefreshTokenHash
+	if subtle.ConstantTimeCompare([]byte(hashToken(refreshToken)), []byte(oldHash)) != 1 {
+		logr.Warn("Refresh token reused, revoking the session", zap.String("session_id", session.ID), zap.String("user_id", session.UserID))
+		return domain.AccessToken{}, h.revokeReused(ctx, logr, session)
+	}
+
+	// Sessions of users deleted since they logged in end with them
+	if err := h.usersRepo.Validate(ctx, session.UserID); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			logr.Info("Refresh token of a deleted user", zap.String("user_id", session.UserID))
+			return domain.AccessToken{}, domain.ErrInvalidRefreshToken
+		}
+
+		logr.Error("Error validating user", zap.Error(err))
+		return domain.AccessToken{}, domain.ErrInternalServer
+	}
+
+	session.UserAgent = client.UserAgent
+	session.IPAddress = client.IPAddress
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(h.refreshTokenTTL)
+
+	newToken, err := newRefreshToken(session)
+	if err != nil {
+		logr.Error("Error generating refresh token", zap.Error(err))
+		return domain.AccessToken{}, domain.ErrInternalServer
+	}
+
+	if err := h.sessionsRepo.Rotate(ctx, session, oldHash); err != nil {
+		// Another refresh with the same token got there first
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Warn("Refresh token used concurrently, revoking the session", zap.String("session_id", session.ID), zap.String("user_id", session.UserID))
+			return domain.AccessToken{}, h.revokeReused(ctx, logr, session)
+		}
+
+		logr.Error("Error rotating refresh token", zap.Error(err))
+		return domain.AccessToken{}, domain.ErrInternalServer
+	}
+
+	token, err := h.issueTokens(session, newToken)
+	if err != nil {
+		logr.Error("Error signing access token", zap.Error(err))
+		return domain.AccessToken{}, domain.ErrInternalServer
+	}
+
+	logr.Info("Session refreshed successfully", zap.String("user_id", session.UserID), zap.String("session_id", session.ID))
+	return token, nil
+}
+
+// revokeReused revokes the session whose refresh token was reused
+func (h *service) revokeReused(ctx context.Context, logr *zap.Logger, session *domain.Session) error {
+	if err := h.sessionsRepo.Revoke(ctx, session.UserID, session.ID, h.now()); err != nil {
+		logr.Error("Error revoking session", zap.String("session_id", session.ID), zap.Error(err))
+		return domain.ErrInternalServer
+	}
+	return domain.ErrRefreshTokenReused
+}
+
+func (h *service) Authenticate(ctx context.Context, token string) (domain.Identity, error) {
+	logr := h.logger.With(zap.String("method", "Authenticate"))
+
+	claims := &accessClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+		return h.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(h.now),
+	)
+	if err != nil || claims.Subject == "" || claims.SessionID == "" {
+		logr.Info("Invalid access token", zap.Error(err))
+		return domain.Identity{}, domain.ErrInvalidToken
+	}
+
+	// Access tokens die with their session, so logging out takes effect at once
+	session, err := h.sessionsRepo.Get(ctx, claims.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("Access token of an unknown session", zap.String("session_id", claims.SessionID))
+			return domain.Identity{}, domain.ErrInvalidToken
+		}
+
+		logr.Error("Error retrieving session", zap.Error(err))
+		return domain.Identity{}, domain.ErrInternalServer
+	}
+	if session.RevokedAt != nil || session.UserID != claims.Subject {
+		logr.Info("Access token of a revoked session", zap.String("session_id", claims.SessionID))
+		return domain.Identity{}, domain.ErrInvalidToken
+	}
+
+	// Tokens of users deleted since they logged in are no longer honoured
+	if err := h.usersRepo.Validate(ctx, claims.Subject); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			logr.Info("Access token of a deleted user", zap.String("user_id", claims.Subject))
+			return domain.Identity{}, domain.ErrInvalidToken
+		}
+
+		logr.Error("Error validating user", zap.Error(err))
+		return domain.Identity{}, domain.ErrInternalServer
+	}
+
+	// Roles are read on every request rather than kept in the token, so that revoking one takes effect at once
+	roles, err := h.usersRepo.ListRoles(ctx, claims.Subject)
+	if err != nil {
+		logr.Error("Error listing roles", zap.Error(err))
+		return domain.Identity{}, domain.ErrInternalServer
+	}
+
+	return domain.Identity{UserID: claims.Subject, Roles: roles, SessionID: session.ID}, nil
+}
+
+func (h *service) ListSessions(ctx context.Context, userID string) ([]domain.Session, error) {
+	logr := h.logger.With(zap.String("method", "ListSessions"))
+
+	if err := h.usersRepo.Validate(ctx, userID); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			logr.Info("User not found", zap.String("user_id", userID))
+			return nil, domain.ErrUserNotFound
+		}
+
+		logr.Error("Error validating user", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	sessions, err := h.sessionsRepo.ListActive(ctx, userID, h.now())
+	if err != nil {
+		logr.Error("Error listing sessions", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	logr.Info("Sessions listed successfully", zap.String("user_id", userID), zap.Int("count", len(sessions)))
+	return sessions, nil
+}
+
+func (h *service) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	logr := h.logger.With(zap.String("method", "RevokeSession"))
+
+	if err := h.sessionsRepo.Revoke(ctx, userID, sessionID, h.now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("Session not found", zap.String("user_id", userID), zap.String("session_id", sessionID))
+			return domain.ErrSessionNotFound
+		}
+
+		logr.Error("Error revoking session", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	logr.Info("Session revoked successfully", zap.String("user_id", userID), zap.String("session_id", sessionID))
+	return nil
+}
+
+func (h *service) RevokeSessions(ctx context.Context, userID string) error {
+	logr := h.logger.With(zap.String("method", "RevokeSessions"))
+
+	if err := h.sessionsRepo.RevokeAll(ctx, userID, h.now()); err != nil {
+		logr.Error("Error revoking sessions", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	logr.Info("Sessions revoked successfully", zap.String("user_id", userID))
+	return nil
+}
+
+// issueTokens signs an access token for the session and pairs it with the session's refresh token
+func (h *service) issueTokens(session *domain.Session, refreshToken string) (domain.AccessToken, error) {
+	now := h.now()
+	expiresAt := now.Add(h.tokenTTL)
+
+	claims := accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    tokenIssuer,
+			Subject:   session.UserID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		SessionID: session.ID,
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(h.secret)
+	if err != nil {
+		return domain.AccessToken{}, err
+	}
+
+	return domain.AccessToken{
+		AccessToken:      signed,
+		TokenType:        tokenType,
+		ExpiresIn:        int(h.tokenTTL.Seconds()),
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// newRefreshToken generates a refresh token for the session and sets its hash on the session. The token starts
+// with the session id, so that a reused token can be traced back to the session it belongs to
+func newRefreshToken(session *domain.Session) (string, error) {
+	secret := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	token := session.ID + "." + base64.RawURLEncoding.EncodeToString(secret)
+	session.RefreshTokenHash = hashToken(token)
+	return token, nil
+}
+
+// hashToken returns the hex SHA-256 of a refresh token, which are random enough not to need a slow hash
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,327 @@
+package authservice_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+	"github.com/victor-nach/postr-backend/internal/services/authservice"
+	"github.com/victor-nach/postr-backend/internal/services/authservice/mocks"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// testLockout backs off after the first failure and locks accounts out after 3 failures, IP addresses after 5
+var testLockout = domain.LoginLockout{MaxFailures: 3, MaxIPFailures: 5, Backoff: time.Second, MaxBackoff: 4 * time.Second, Duration: 15 * time.Minute}
+
+func TestService_Login(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	mockSessionsRepo := mocks.NewMocksessionsRepo(ctrl)
+	mockThrottlesRepo := mocks.NewMockthrottlesRepo(ctrl)
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	clock := now
+	svc := authservice.New(mockRepo, mockSessionsRepo, mockThrottlesRepo, testSecret, 15*time.Minute, 24*time.Hour, testLockout, zap.NewNop(), authservice.WithClock(func() time.Time { return clock }))
+
+	ctx := context.Background()
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &domain.User{ID: uuid.NewString(), Email: "alice@example.com", PasswordHash: string(hash)}
+	client := domain.SessionClient{UserAgent: "curl/8.5.0", IPAddress: "203.0.113.7"}
+
+	var session domain.Session
+	mockThrottlesRepo.EXPECT().List(ctx, "account:alice@example.com", "ip:203.0.113.7").Return(nil, nil)
+	mockRepo.EXPECT().GetByEmail(ctx, user.Email).Return(user, nil)
+	mockThrottlesRepo.EXPECT().Reset(ctx, "account:alice@example.com").Return(nil)
+	mockSessionsRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Session) error {
+		session = *s
+		return nil
+	})
+
+	token, err := svc.Login(ctx, user.Email, "correct horse", client)
+	require.NoError(t, err)
+	require.Equal(t, "Bearer", token.TokenType)
+	require.Equal(t, 900, token.ExpiresIn)
+	require.Equal(t, now.Add(15*time.Minute), token.ExpiresAt)
+	require.Equal(t, now.Add(24*time.Hour), token.RefreshExpiresAt)
+
+	require.Equal(t, user.ID, session.UserID)
+	require.Equal(t, client.UserAgent, session.UserAgent)
+	require.Equal(t, client.IPAddress, session.IPAddress)
+	require.Equal(t, now.Add(24*time.Hour), session.ExpiresAt)
+	require.True(t, strings.HasPrefix(token.RefreshToken, session.ID+"."))
+	sum := sha256.Sum256([]byte(token.RefreshToken))
+	require.Equal(t, hex.EncodeToString(sum[:]), session.RefreshTokenHash, "only a hash of the refresh token should be stored")
+
+	// The token authenticates the user until it expires
+	mockSessionsRepo.EXPECT().Get(ctx, session.ID).Return(&session, nil)
+	mockRepo.EXPECT().Validate(ctx, user.ID).Return(nil)
+	mockRepo.EXPECT().ListRoles(ctx, user.ID).Return([]domain.Role{domain.RoleAdmin}, nil)
+
+	identity, err := svc.Authenticate(ctx, token.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, domain.Identity{UserID: user.ID, Roles: []domain.Role{domain.RoleAdmin}, SessionID: session.ID}, identity)
+
+	clock = now.Add(16 * time.Minute)
+	_, err = svc.Authenticate(ctx, token.AccessToken)
+	require.Equal(t, domain.ErrInvalidToken, err)
+
+	// It stops working as soon as its session is revoked
+	clock = now
+	revoked := session
+	revoked.RevokedAt = &now
+	mockSessionsRepo.EXPECT().Get(ctx, session.ID).Return(&revoked, nil)
+
+	_, err = svc.Authenticate(ctx, token.AccessToken)
+	require.Equal(t, domain.ErrInvalidToken, err)
+}
+
+func TestService_Login_InvalidCredentials(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	mockThrottlesRepo := mocks.NewMockthrottlesRepo(ctrl)
+	svc := authservice.New(mockRepo, mocks.NewMocksessionsRepo(ctrl), mockThrottlesRepo, testSecret, 15*time.Minute, 24*time.Hour, testLockout, zap.NewNop())
+
+	ctx := context.Background()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		user     *domain.User
+		repoErr  error
+		password string
+		want     error
+	}{
+		{"unknown email", nil, gorm.ErrRecordNotFound, "correct horse", domain.ErrInvalidCredentials},
+		{"wrong password", &domain.User{ID: "u1", PasswordHash: string(hash)}, nil, "battery staple", domain.ErrInvalidCredentials},
+		{"no password set", &domain.User{ID: "u1"}, nil, "", domain.ErrInvalidCredentials},
+		{"repository error", nil, errors.New("database is locked"), "correct horse", domain.ErrInternalServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockThrottlesRepo.EXPECT().List(ctx, "account:alice@example.com").Return(nil, nil)
+			mockRepo.EXPECT().GetByEmail(ctx, "alice@example.com").Return(tt.user, tt.repoErr)
+			// Every failure counts against the account, errors of the service's own do not
+			if errors.Is(tt.want, domain.ErrInvalidCredentials) {
+				mockThrottlesRepo.EXPECT().RecordFailure(gomock.Any(), "account:alice@example.com", gomock.Any(), gomock.Any()).
+					Return(&domain.LoginThrottle{Key: "account:alice@example.com", Failures: 1}, nil)
+			}
+
+			token, err := svc.Login(ctx, "alice@example.com", tt.password, domain.SessionClient{})
+			require.Equal(t, tt.want, err)
+			require.Empty(t, token.AccessToken)
+		})
+	}
+}
+
+func TestService_Authenticate_InvalidToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	mockSessionsRepo := mocks.NewMocksessionsRepo(ctrl)
+	svc := authservice.New(mockRepo, mockSessionsRepo, mocks.NewMockthrottlesRepo(ctrl), testSecret, 15*time.Minute, 24*time.Hour, testLockout, zap.NewNop())
+
+	ctx := context.Background()
+	now := time.Now()
+
+	sign := func(method jwt.SigningMethod, key any, claims jwt.MapClaims) string {
+		signed, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+	expiresAt := jwt.NewNumericDate(now.Add(time.Minute))
+	claims := func(issuer, subject, sessionID string, expiresAt *jwt.NumericDate) jwt.MapClaims {
+		claims := jwt.MapClaims{"iss": issuer, "sub": subject, "sid": sessionID}
+		if expiresAt != nil {
+			claims["exp"] = expiresAt
+		}
+		return claims
+	}
+	valid := claims("postr-backend", "u1", "s1", expiresAt)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"malformed", "not-a-token"},
+		{"other secret", sign(jwt.SigningMethodHS256, []byte("another secret of thirty-two bytes"), valid)},
+		{"unsigned", sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, valid)},
+		{"other issuer", sign(jwt.SigningMethodHS256, testSecret, claims("someone", "u1", "s1", expiresAt))},
+		{"no expiry", sign(jwt.SigningMethodHS256, testSecret, claims("postr-backend", "u1", "s1", nil))},
+		{"no subject", sign(jwt.SigningMethodHS256, testSecret, claims("postr-backend", "", "s1", expiresAt))},
+		{"no session", sign(jwt.SigningMethodHS256, testSecret, claims("postr-backend", "u1", "", expiresAt))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Authenticate(ctx, tt.token)
+			require.Equal(t, domain.ErrInvalidToken, err)
+		})
+	}
+
+	// Tokens of unknown sessions, or of sessions of someone else, are refused
+	mockSessionsRepo.EXPECT().Get(ctx, "s1").Return(nil, gorm.ErrRecordNotFound)
+	_, err := svc.Authenticate(ctx, sign(jwt.SigningMethodHS256, testSecret, valid))
+	require.Equal(t, domain.ErrInvalidToken, err)
+
+	mockSessionsRepo.EXPECT().Get(ctx, "s1").Return(&domain.Session{ID: "s1", UserID: "u2"}, nil)
+	_, err = svc.Authenticate(ctx, sign(jwt.SigningMethodHS256, testSecret, valid))
+	require.Equal(t, domain.ErrInvalidToken, err)
+
+	// Tokens of deleted users are refused
+	mockSessionsRepo.EXPECT().Get(ctx, "s1").Return(&domain.Session{ID: "s1", UserID: "u1"}, nil)
+	mockRepo.EXPECT().Validate(ctx, "u1").Return(domain.ErrUserNotFound)
+
+	_, err = svc.Authenticate(ctx, sign(jwt.SigningMethodHS256, testSecret, valid))
+	require.Equal(t, domain.ErrInvalidToken, err)
+}
+
+func TestService_Refresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	mockSessionsRepo := mocks.NewMocksessionsRepo(ctrl)
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	svc := authservice.New(mockRepo, mockSessionsRepo, mocks.NewMockthrottlesRepo(ctrl), testSecret, 15*time.Minute, 24*time.Hour, testLockout, zap.NewNop(), authservice.WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	session := &domain.Session{ID: uuid.NewString(), UserID: "u1", CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)}
+	oldToken := session.ID + ".b2xkIHNlY3JldA"
+	sum := sha256.Sum256([]byte(oldToken))
+	oldHash := hex.EncodeToString(sum[:])
+	session.RefreshTokenHash = oldHash
+	client := domain.SessionClient{UserAgent: "curl/8.5.0", IPAddress: "203.0.113.7"}
+
+	// The refresh token is rotated and the session extended
+	stored := *session
+	mockSessionsRepo.EXPECT().Get(ctx, session.ID).Return(&stored, nil)
+	mockRepo.EXPECT().Validate(ctx, "u1").Return(nil)
+	var newHash string
+	mockSessionsRepo.EXPECT().Rotate(ctx, gomock.Any(), oldHash).DoAndReturn(func(ctx context.Context, s *domain.Session, oldHash string) error {
+		require.NotEqual(t, oldHash, s.RefreshTokenHash)
+		newHash = s.RefreshTokenHash
+		require.Equal(t, now, s.LastUsedAt)
+		require.Equal(t, now.Add(24*time.Hour), s.ExpiresAt)
+		require.Equal(t, client.IPAddress, s.IPAddress)
+		return nil
+	})
+
+	token, err := svc.Refresh(ctx, oldToken, client)
+	require.NoError(t, err)
+	require.NotEmpty(t, token.AccessToken)
+	require.NotEqual(t, oldToken, token.RefreshToken)
+	require.True(t, strings.HasPrefix(token.RefreshToken, session.ID+"."))
+	require.Equal(t, now.Add(24*time.Hour), token.RefreshExpiresAt)
+
+	// Using the old token again is a reuse, the session is revoked
+	rotated := *session
+	rotated.RefreshTokenHash = newHash
+	mockSessionsRepo.EXPECT().Get(ctx, session.ID).Return(&rotated, nil)
+	mockSessionsRepo.EXPECT().Revoke(ctx, "u1", session.ID, now).Return(nil)
+
+	_, err = svc.Refresh(ctx, oldToken, client)
+	require.Equal(t, domain.ErrRefreshTokenReused, err)
+
+	// So is losing the race to another refresh with the same token
+	stored = *session
+	mockSessionsRepo.EXPECT().Get(ctx, session.ID).Return(&stored, nil)
+	mockRepo.EXPECT().Validate(ctx, "u1").Return(nil)
+	mockSessionsRepo.EXPECT().Rotate(ctx, gomock.Any(), oldHash).Return(gorm.ErrRecordNotFound)
+	mockSessionsRepo.EXPECT().Revoke(ctx, "u1", session.ID, now).Return(nil)
+
+	_, err = svc.Refresh(ctx, oldToken, client)
+	require.Equal(t, domain.ErrRefreshTokenReused, err)
+}
+
+func TestService_Refresh_InvalidToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	mockSessionsRepo := mocks.NewMocksessionsRepo(ctrl)
+	now := time.Now()
+	svc := authservice.New(mockRepo, mockSessionsRepo, mocks.NewMockthrottlesRepo(ctrl), testSecret, 15*time.Minute, 24*time.Hour, testLockout, zap.NewNop(), authservice.WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	refreshToken := "s1.c2VjcmV0"
+	sum := sha256.Sum256([]byte(refreshToken))
+	session := &domain.Session{ID: "s1", UserID: "u1", RefreshTokenHash: hex.EncodeToString(sum[:]), ExpiresAt: now.Add(time.Hour)}
+
+	_, err := svc.Refresh(ctx, "not-a-token", domain.SessionClient{})
+	require.Equal(t, domain.ErrInvalidRefreshToken, err)
+
+	mockSessionsRepo.EXPECT().Get(ctx, "s1").Return(nil, gorm.ErrRecordNotFound)
+	_, err = svc.Refresh(ctx, refreshToken, domain.SessionClient{})
+	require.Equal(t, domain.ErrInvalidRefreshToken, err)
+
+	revoked := *session
+	revoked.RevokedAt = &now
+	mockSessionsRepo.EXPECT().Get(ctx, "s1").Return(&revoked, nil)
+	_, err = svc.Refresh(ctx, refreshToken, domain.SessionClient{})
+	require.Equal(t, domain.ErrInvalidRefreshToken, err)
+
+	expired := *session
+	expired.ExpiresAt = now
+	mockSessionsRepo.EXPECT().Get(ctx, "s1").Return(&expired, nil)
+	_, err = svc.Refresh(ctx, refreshToken, domain.SessionClient{})
+	require.Equal(t, domain.ErrInvalidRefreshToken, err)
+
+	// Sessions of deleted users cannot be refreshed
+	active := *session
+	mockSessionsRepo.EXPECT().Get(ctx, "s1").Return(&active, nil)
+	mockRepo.EXPECT().Validate(ctx, "u1").Return(domain.ErrUserNotFound)
+	_, err = svc.Refresh(ctx, refreshToken, domain.SessionClient{})
+	require.Equal(t, domain.ErrInvalidRefreshToken, err)
+}
+
+func TestService_Sessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	mockSessionsRepo := mocks.NewMocksessionsRepo(ctrl)
+	now := time.Now()
+	svc := authservice.New(mockRepo, mockSessionsRepo, mocks.NewMockthrottlesRepo(ctrl), testSecret, 15*time.Minute, 24*time.Hour, testLockout, zap.NewNop(), authservice.WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	mockRepo.EXPECT().Validate(ctx, "u1").Return(nil)
+	mockSessionsRepo.EXPECT().ListActive(ctx, "u1", now).Return([]domain.Session{{ID: "s1", UserID: "u1"}}, nil)
+	sessions, err := svc.ListSessions(ctx, "u1")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+
+	mockRepo.EXPECT().Validate(ctx, "u2").Return(domain.ErrUserNotFound)
+	_, err = svc.ListSessions(ctx, "u2")
+	require.Equal(t, domain.ErrUserNotFound, err)
+
+	mockSessionsRepo.EXPECT().Revoke(ctx, "u1", "s1", now).Return(nil)
+	require.NoError(t, svc.RevokeSession(ctx, "u1", "s1"))
+
+	mockSessionsRepo.EXPECT().Revoke(ctx, "u1", "s2", now).Return(gorm.ErrRecordNotFound)
+	require.Equal(t, domain.ErrSessionNotFound, svc.RevokeSession(ctx, "u1", "s2"))
+
+	mockSessionsRepo.EXPECT().RevokeAll(ctx, "u1", now).Return(nil)
+	require.NoError(t, svc.RevokeSessions(ctx, "u1"))
+}
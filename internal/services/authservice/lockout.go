@@ -0,0 +1,132 @@
+package authservice
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+//go:generate mockgen -destination=./mocks/mock_throttlesrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/authservice throttlesRepo
+type throttlesRepo interface {
+	List(ctx context.Context, keys ...string) ([]domain.LoginThrottle, error)
+	RecordFailure(ctx context.Context, key string, at time.Time, since time.Time) (*domain.LoginThrottle, error)
+	Lock(ctx context.Context, key string, until time.Time) (*domain.LoginThrottle, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// loginKey names the throttle of an account or an IP address, along with the failures that lock it out
+type loginKey struct {
+	key         string
+	maxFailures int
+}
+
+// loginKeys returns the throttles a login of the email from the IP address counts against
+func (h *service) loginKeys(email string, ipAddress string) []loginKey {
+	keys := []loginKey{{key: accountKey(email), maxFailures: h.lockout.MaxFailures}}
+	if ipAddress != "" {
+		keys = append(keys, loginKey{key: "ip:" + ipAddress, maxFailures: h.lockout.MaxIPFailures})
+	}
+	return keys
+}
+
+// accountKey names the throttle of the account registered with the email, or that would be
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(email)
+}
+
+// checkLockout returns a domain.LoginLockedError while any of the keys is locked out or waiting out its backoff
+func (h *service) checkLockout(ctx context.Context, keys []loginKey, now time.Time) error {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k.key
+	}
+
+	throttles, err := h.throttlesRepo.List(ctx, names...)
+	if err != nil {
+		return err
+	}
+
+	var retryAfter time.Duration
+	for _, throttle := range throttles {
+		retryAfter = max(retryAfter, h.retryAfter(throttle, now))
+	}
+	if retryAfter > 0 {
+		return domain.LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// retryAfter returns how long logins of the throttle are held back from now on
+func (h *service) retryAfter(throttle domain.LoginThrottle, now time.Time) time.Duration {
+	if throttle.LockedUntil != nil && now.Before(*throttle.LockedUntil) {
+		return throttle.LockedUntil.Sub(now)
+	}
+
+	// Failures older than a lockout are forgotten
+	if throttle.LastFailedAt == nil || throttle.LastFailedAt.Before(now.Add(-h.lockout.Duration)) {
+		return 0
+	}
+	return max(throttle.LastFailedAt.Add(h.lockout.Wait(throttle.Failures)).Sub(now), 0)
+}
+
+// recordFailure counts a failed login against every key and locks out those that reach their limit. Errors are
+// only logged, the login failed either way
+func (h *service) recordFailure(ctx context.Context, logr *zap.Logger, keys []loginKey, now time.Time) {
+	// Clients hanging up early must not get their failures forgotten
+	ctx = context.WithoutCancel(ctx)
+
+	for _, k := range keys {
+		throttle, err := h.throttlesRepo.RecordFailure(ctx, k.key, now, now.Add(-h.lockout.Duration))
+		if err != nil {
+			logr.Error("Error recording failed login", zap.String("key", k.key), zap.Error(err))
+			continue
+		}
+
+		if k.maxFailures == 0 || throttle.Failures < k.maxFailures {
+			continue
+		}
+
+		until := now.Add(h.lockout.Duration)
+		throttle, err = h.throttlesRepo.Lock(ctx, k.key, until)
+		if err != nil {
+			logr.Error("Error locking out logins", zap.String("key", k.key), zap.Error(err))
+			continue
+		}
+
+		logr.Warn("Logins locked out after too many failures",
+			zap.String("key", k.key),
+			zap.Int("failures", k.maxFailures),
+			zap.Time("locked_until", until),
+			zap.Int("lockouts", throttle.Lockouts),
+		)
+	}
+}
+
+// Unlock lifts the lockout of the user's account and forgets its failed logins
+func (h *service) Unlock(ctx context.Context, userID string) error {
+	logr := h.logger.With(zap.String("method", "Unlock"))
+
+	user, err := h.usersRepo.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrUserNotFound
+		}
+
+		logr.Error("Error retrieving user", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	if err := h.throttlesRepo.Reset(ctx, accountKey(user.Email)); err != nil {
+		logr.Error("Error resetting failed logins", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	logr.Info("Account unlocked successfully", zap.String("user_id", userID))
+	return nil
+}
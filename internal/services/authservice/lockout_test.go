@@ -0,0 +1,142 @@
+package authservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+	"github.com/victor-nach/postr-backend/internal/services/authservice"
+	"github.com/victor-nach/postr-backend/internal/services/authservice/mocks"
+)
+
+func TestService_Login_HeldBack(t *testing.T) {
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) *time.Time {
+		at := now.Add(-d)
+		return &at
+	}
+	client := domain.SessionClient{IPAddress: "203.0.113.7"}
+
+	tests := []struct {
+		name      string
+		throttles []domain.LoginThrottle
+		// retryAfter is zero for logins let through
+		retryAfter time.Duration
+	}{
+		{"locked out account", []domain.LoginThrottle{{Key: "account:alice@example.com", LockedUntil: ago(-10 * time.Minute)}}, 10 * time.Minute},
+		{"locked out IP address", []domain.LoginThrottle{{Key: "ip:203.0.113.7", LockedUntil: ago(-time.Minute)}}, time.Minute},
+		{"backing off", []domain.LoginThrottle{{Key: "account:alice@example.com", Failures: 2, LastFailedAt: ago(time.Second)}}, time.Second},
+		{"backoff capped", []domain.LoginThrottle{{Key: "ip:203.0.113.7", Failures: 4, LastFailedAt: ago(0)}}, 4 * time.Second},
+		{"longest wait wins", []domain.LoginThrottle{
+			{Key: "account:alice@example.com", Failures: 1, LastFailedAt: ago(0)},
+			{Key: "ip:203.0.113.7", Failures: 3, LastFailedAt: ago(0)},
+		}, 4 * time.Second},
+		{"backoff waited out", []domain.LoginThrottle{{Key: "account:alice@example.com", Failures: 2, LastFailedAt: ago(2 * time.Second)}}, 0},
+		{"lockout over", []domain.LoginThrottle{{Key: "account:alice@example.com", LockedUntil: ago(time.Second)}}, 0},
+		{"failures forgotten", []domain.LoginThrottle{{Key: "account:alice@example.com", Failures: 2, LastFailedAt: ago(time.Hour)}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockusersRepo(ctrl)
+			mockThrottlesRepo := mocks.NewMockthrottlesRepo(ctrl)
+			svc := authservice.New(mockRepo, mocks.NewMocksessionsRepo(ctrl), mockThrottlesRepo, testSecret, 15*time.Minute, 24*time.Hour, testLockout, zap.NewNop(), authservice.WithClock(func() time.Time { return now }))
+			ctx := context.Background()
+
+			mockThrottlesRepo.EXPECT().List(ctx, "account:alice@example.com", "ip:203.0.113.7").Return(tt.throttles, nil)
+			if tt.retryAfter == 0 {
+				mockRepo.EXPECT().GetByEmail(ctx, "Alice@example.com").Return(nil, gorm.ErrRecordNotFound)
+				mockThrottlesRepo.EXPECT().RecordFailure(gomock.Any(), gomock.Any(), now, now.Add(-15*time.Minute)).
+					Return(&domain.LoginThrottle{Failures: 1}, nil).Times(2)
+			}
+
+			_, err := svc.Login(ctx, "Alice@example.com", "correct horse", client)
+			if tt.retryAfter == 0 {
+				require.Equal(t, domain.ErrInvalidCredentials, err)
+				return
+			}
+
+			require.ErrorIs(t, err, domain.ErrLoginLocked)
+			require.Equal(t, domain.LoginLockedError{RetryAfter: tt.retryAfter}, err)
+		})
+	}
+
+	t.Run("repository error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockThrottlesRepo := mocks.NewMockthrottlesRepo(ctrl)
+		svc := authservice.New(mocks.NewMockusersRepo(ctrl), mocks.NewMocksessionsRepo(ctrl), mockThrottlesRepo, testSecret, 15*time.Minute, 24*time.Hour, testLockout, zap.NewNop())
+		ctx := context.Background()
+
+		mockThrottlesRepo.EXPECT().List(ctx, gomock.Any()).Return(nil, errors.New("database is locked"))
+
+		_, err := svc.Login(ctx, "alice@example.com", "correct horse", domain.SessionClient{})
+		require.Equal(t, domain.ErrInternalServer, err)
+	})
+}
+
+func TestService_Login_LocksOut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	mockThrottlesRepo := mocks.NewMockthrottlesRepo(ctrl)
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	svc := authservice.New(mockRepo, mocks.NewMocksessionsRepo(ctrl), mockThrottlesRepo, testSecret, 15*time.Minute, 24*time.Hour, testLockout, zap.NewNop(), authservice.WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	// The third failure locks the account out, the IP address has two more to go
+	mockThrottlesRepo.EXPECT().List(ctx, "account:alice@example.com", "ip:203.0.113.7").Return(nil, nil)
+	mockRepo.EXPECT().GetByEmail(ctx, "alice@example.com").Return(nil, gorm.ErrRecordNotFound)
+	mockThrottlesRepo.EXPECT().RecordFailure(gomock.Any(), "account:alice@example.com", now, now.Add(-15*time.Minute)).
+		Return(&domain.LoginThrottle{Key: "account:alice@example.com", Failures: 3}, nil)
+	mockThrottlesRepo.EXPECT().Lock(gomock.Any(), "account:alice@example.com", now.Add(15*time.Minute)).
+		Return(&domain.LoginThrottle{Key: "account:alice@example.com", Lockouts: 1}, nil)
+	mockThrottlesRepo.EXPECT().RecordFailure(gomock.Any(), "ip:203.0.113.7", now, now.Add(-15*time.Minute)).
+		Return(&domain.LoginThrottle{Key: "ip:203.0.113.7", Failures: 3}, nil)
+
+	_, err := svc.Login(ctx, "alice@example.com", "correct horse", domain.SessionClient{IPAddress: "203.0.113.7"})
+	require.Equal(t, domain.ErrInvalidCredentials, err)
+
+	// Failing to record a failure still fails the login
+	mockThrottlesRepo.EXPECT().List(ctx, "account:alice@example.com").Return(nil, nil)
+	mockRepo.EXPECT().GetByEmail(ctx, "alice@example.com").Return(nil, gorm.ErrRecordNotFound)
+	mockThrottlesRepo.EXPECT().RecordFailure(gomock.Any(), "account:alice@example.com", now, gomock.Any()).
+		Return(nil, errors.New("database is locked"))
+
+	_, err = svc.Login(ctx, "alice@example.com", "correct horse", domain.SessionClient{})
+	require.Equal(t, domain.ErrInvalidCredentials, err)
+}
+
+func TestService_Unlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	mockThrottlesRepo := mocks.NewMockthrottlesRepo(ctrl)
+	svc := authservice.New(mockRepo, mocks.NewMocksessionsRepo(ctrl), mockThrottlesRepo, testSecret, 15*time.Minute, 24*time.Hour, testLockout, zap.NewNop())
+	ctx := context.Background()
+
+	user := &domain.User{ID: "u1", Email: "Alice@example.com"}
+	mockRepo.EXPECT().Get(ctx, user.ID).Return(user, nil)
+	mockThrottlesRepo.EXPECT().Reset(ctx, "account:alice@example.com").Return(nil)
+	require.NoError(t, svc.Unlock(ctx, user.ID))
+
+	mockRepo.EXPECT().Get(ctx, "missing").Return(nil, gorm.ErrRecordNotFound)
+	require.Equal(t, domain.ErrUserNotFound, svc.Unlock(ctx, "missing"))
+
+	mockRepo.EXPECT().Get(ctx, user.ID).Return(user, nil)
+	mockThrottlesRepo.EXPECT().Reset(ctx, "account:alice@example.com").Return(errors.New("database is locked"))
+	require.Equal(t, domain.ErrInternalServer, svc.Unlock(ctx, user.ID))
+}
@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/victor-nach/postr-backend/internal/services/authservice (interfaces: sessionsRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mocks/mock_sessionsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/authservice sessionsRepo
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/victor-nach/postr-backend/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MocksessionsRepo is a mock of sessionsRepo interface.
+type MocksessionsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MocksessionsRepoMockRecorder
+	isgomock struct{}
+}
+
+// MocksessionsRepoMockRecorder is the mock recorder for MocksessionsRepo.
+type MocksessionsRepoMockRecorder struct {
+	mock *MocksessionsRepo
+}
+
+// NewMocksessionsRepo creates a new mock instance.
+func NewMocksessionsRepo(ctrl *gomock.Controller) *MocksessionsRepo {
+	mock := &MocksessionsRepo{ctrl: ctrl}
+	mock.recorder = &MocksessionsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocksessionsRepo) EXPECT() *MocksessionsRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MocksessionsRepo) Create(ctx context.Context, session *domain.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MocksessionsRepoMockRecorder) Create(ctx, session any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MocksessionsRepo)(nil).Create), ctx, session)
+}
+
+// Get mocks base method.
+func (m *MocksessionsRepo) Get(ctx context.Context, id string) (*domain.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*domain.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MocksessionsRepoMockRecorder) Get(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MocksessionsRepo)(nil).Get), ctx, id)
+}
+
+// ListActive mocks base method.
+func (m *MocksessionsRepo) ListActive(ctx context.Context, userID string, now time.Time) ([]domain.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActive", ctx, userID, now)
+	ret0, _ := ret[0].([]domain.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActive indicates an expected call of ListActive.
+func (mr *MocksessionsRepoMockRecorder) ListActive(ctx, userID, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActive", reflect.TypeOf((*MocksessionsRepo)(nil).ListActive), ctx, userID, now)
+}
+
+// Revoke mocks base method.
+func (m *MocksessionsRepo) Revoke(ctx context.Context, userID, id string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, userID, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MocksessionsRepoMockRecorder) Revoke(ctx, userID, id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MocksessionsRepo)(nil).Revoke), ctx, userID, id, at)
+}
+
+// RevokeAll mocks base method.
+func (m *MocksessionsRepo) RevokeAll(ctx context.Context, userID string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAll", ctx, userID, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAll indicates an expected call of RevokeAll.
+func (mr *MocksessionsRepoMockRecorder) RevokeAll(ctx, userID, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAll", reflect.TypeOf((*MocksessionsRepo)(nil).RevokeAll), ctx, userID, at)
+}
+
+// Rotate mocks base method.
+func (m *MocksessionsRepo) Rotate(ctx context.Context, session *domain.Session, oldHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rotate", ctx, session, oldHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rotate indicates an expected call of Rotate.
+func (mr *MocksessionsRepoMockRecorder) Rotate(ctx, session, oldHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rotate", reflect.TypeOf((*MocksessionsRepo)(nil).Rotate), ctx, session, oldHash)
+}
@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/victor-nach/postr-backend/internal/services/authservice (interfaces: throttlesRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mocks/mock_throttlesrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/authservice throttlesRepo
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/victor-nach/postr-backend/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockthrottlesRepo is a mock of throttlesRepo interface.
+type MockthrottlesRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockthrottlesRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockthrottlesRepoMockRecorder is the mock recorder for MockthrottlesRepo.
+type MockthrottlesRepoMockRecorder struct {
+	mock *MockthrottlesRepo
+}
+
+// NewMockthrottlesRepo creates a new mock instance.
+func NewMockthrottlesRepo(ctrl *gomock.Controller) *MockthrottlesRepo {
+	mock := &MockthrottlesRepo{ctrl: ctrl}
+	mock.recorder = &MockthrottlesRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockthrottlesRepo) EXPECT() *MockthrottlesRepoMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockthrottlesRepo) List(ctx context.Context, keys ...string) ([]domain.LoginThrottle, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "List", varargs...)
+	ret0, _ := ret[0].([]domain.LoginThrottle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockthrottlesRepoMockRecorder) List(ctx any, keys ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, keys...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockthrottlesRepo)(nil).List), varargs...)
+}
+
+// Lock mocks base method.
+func (m *MockthrottlesRepo) Lock(ctx context.Context, key string, until time.Time) (*domain.LoginThrottle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, key, until)
+	ret0, _ := ret[0].(*domain.LoginThrottle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockthrottlesRepoMockRecorder) Lock(ctx, key, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockthrottlesRepo)(nil).Lock), ctx, key, until)
+}
+
+// RecordFailure mocks base method.
+func (m *MockthrottlesRepo) RecordFailure(ctx context.Context, key string, at, since time.Time) (*domain.LoginThrottle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailure", ctx, key, at, since)
+	ret0, _ := ret[0].(*domain.LoginThrottle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordFailure indicates an expected call of RecordFailure.
+func (mr *MockthrottlesRepoMockRecorder) RecordFailure(ctx, key, at, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailure", reflect.TypeOf((*MockthrottlesRepo)(nil).RecordFailure), ctx, key, at, since)
+}
+
+// Reset mocks base method.
+func (m *MockthrottlesRepo) Reset(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockthrottlesRepoMockRecorder) Reset(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockthrottlesRepo)(nil).Reset), ctx, key)
+}
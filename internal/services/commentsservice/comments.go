@@ -0,0 +1,208 @@
+package commentsservice
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type service struct {
+	commentsRepo commentsRepo
+	postsRepo    postsRepo
+	usersRepo    usersRepo
+	tx           transactor
+	audit        auditRepo
+	logger       *zap.Logger
+}
+
+// New creates the comments service, every change is recorded in the audit log in the transaction of the change
+func New(commentsRepo commentsRepo, postsRepo postsRepo, usersRepo usersRepo, tx transactor, audit auditRepo, logger *zap.Logger) domain.CommentService {
+	logger = logger.With(zap.String("package", "commentsservice"))
+
+	return &service{
+		commentsRepo: commentsRepo,
+		postsRepo:    postsRepo,
+		usersRepo:    usersRepo,
+		tx:           tx,
+		audit:        audit,
+		logger:       logger,
+	}
+}
+
+//go:generate mockgen -destination=./mocks/mock_commentsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/commentsservice commentsRepo
+type commentsRepo interface {
+	Create(ctx context.Context, comment *domain.Comment) error
+	Get(ctx context.Context, id string) (*domain.Comment, error)
+	List(ctx context.Context, query domain.CommentQuery) (domain.PaginatedComments, error)
+	Delete(ctx context.Context, id string) error
+}
+
+//go:generate mockgen -destination=./mocks/mock_postsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/commentsservice postsRepo
+type postsRepo interface {
+	Get(ctx context.Context, id string) (*domain.Post, error)
+}
+
+//go:generate mockgen -destination=./mocks/mock_usersrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/commentsservice usersRepo
+type usersRepo interface {
+	Get(ctx context.Context, id string) (*domain.User, error)
+}
+
+//go:generate mockgen -destination=./mocks/mock_auditrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/commentsservice auditRepo
+type auditRepo interface {
+	Create(ctx context.Context, event *domain.AuditEvent) error
+}
+
+// transactor runs fn in a database transaction, the repositories called with the context handed to fn take part in it
+type transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Create stores the comment under its post, only authors who verified their email can comment
+func (h *service) Create(ctx context.Context, comment *domain.Comment) error {
+	logr := h.logger.With(zap.String("method", "Create"))
+
+	if err := h.checkPost(ctx, comment.PostID); err != nil {
+		logr.Info("Unable to retrieve post", zap.String("post_id", comment.PostID), zap.Error(err))
+		return err
+	}
+
+	author, err := h.usersRepo.Get(ctx, comment.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("Author not found", zap.String("user_id", comment.UserID))
+			return domain.ErrUserNotFound
+		}
+
+		logr.Error("Error retrieving author", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	if !author.EmailVerified() {
+		logr.Info("Author has not verified their email", zap.String("user_id", author.ID))
+		return domain.ErrEmailNotVerified
+	}
+
+	err = h.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := h.commentsRepo.Create(ctx, comment); err != nil {
+			return err
+		}
+		return h.record(ctx, domain.AuditCommentCreate, comment.ID, nil, comment)
+	})
+	if err != nil {
+		// The post was deleted since it was checked
+		if errors.Is(err, domain.ErrInvalidReference) {
+			logr.Info("Post not found", zap.String("post_id", comment.PostID))
+			return domain.ErrPostNotFound
+		}
+
+		logr.Error("Error creating comment", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	logr.Info("Comment created successfully", zap.String("id", comment.ID), zap.String("post_id", comment.PostID))
+	return nil
+}
+
+func (h *service) List(ctx context.Context, query domain.CommentQuery) (domain.PaginatedComments, error) {
+	logr := h.logger.With(zap.String("method", "List"))
+
+	if err := h.checkPost(ctx, query.PostID); err != nil {
+		logr.Info("Unable to retrieve post", zap.String("post_id", query.PostID), zap.Error(err))
+		return domain.PaginatedComments{}, err
+	}
+
+	comments, err := h.commentsRepo.List(ctx, query)
+	if err != nil {
+		// An unusable cursor is reported back to the caller as is
+		if errors.Is(err, domain.ErrInvalidInput) {
+			logr.Info("Invalid comment query", zap.Error(err))
+			return domain.PaginatedComments{}, err
+		}
+
+		logr.Error("Error listing comments", zap.Error(err))
+		return domain.PaginatedComments{}, domain.ErrInternalServer
+	}
+
+	logr.Info("Comments listed successfully", zap.String("post_id", query.PostID), zap.Int("count", len(comments.Comments)))
+	return comments, nil
+}
+
+// Delete removes the comment, only its author or a moderator can
+func (h *service) Delete(ctx context.Context, id string) error {
+	logr := h.logger.With(zap.String("method", "Delete"))
+
+	comment, err := h.commentsRepo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("Comment not found", zap.String("id", id))
+			return domain.ErrCommentNotFound
+		}
+
+		logr.Error("Error retrieving comment", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	if err := authorize(ctx, comment); err != nil {
+		logr.Info("Caller may not delete the comment", zap.String("id", id), zap.Error(err))
+		return err
+	}
+
+	err = h.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := h.commentsRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return h.record(ctx, domain.AuditCommentDelete, id, comment, nil)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("Comment not found", zap.String("id", id))
+			return domain.ErrCommentNotFound
+		}
+
+		logr.Error("Error deleting comment", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	logr.Info("Comment deleted successfully", zap.String("id", id))
+	return nil
+}
+
+// authorize allows the caller to delete the comment when they wrote it or may moderate posts
+func authorize(ctx context.Context, comment *domain.Comment) error {
+	identity, ok := domain.IdentityFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
+	if identity.UserID == comment.UserID || identity.Can(domain.PermPostsModerate) {
+		return nil
+	}
+	return domain.ErrCommentForbidden
+}
+
+// checkPost makes sure the post exists and is not deleted
+func (h *service) checkPost(ctx context.Context, postID string) error {
+	if _, err := h.postsRepo.Get(ctx, postID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrPostNotFound
+		}
+
+		h.logger.Error("Error retrieving post", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+	return nil
+}
+
+// record appends the change to the audit log, it must be called in the transaction of the change so the event is
+// only kept if the change is
+func (h *service) record(ctx context.Context, action domain.AuditAction, targetID string, before any, after any) error {
+	event, err := domain.NewAuditEvent(ctx, action, targetID, before, after)
+	if err != nil {
+		return err
+	}
+	return h.audit.Create(ctx, &event)
+}
@@ -0,0 +1,207 @@
+package commentsservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+	"github.com/victor-nach/postr-backend/internal/services/commentsservice"
+	"github.com/victor-nach/postr-backend/internal/services/commentsservice/mocks"
+)
+
+// inTx runs the function of a transaction right away, there is no database behind these tests
+type inTx struct{}
+
+func (inTx) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestService_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	comments := mocks.NewMockcommentsRepo(ctrl)
+	posts := mocks.NewMockpostsRepo(ctrl)
+	users := mocks.NewMockusersRepo(ctrl)
+	audit := mocks.NewMockauditRepo(ctrl)
+	svc := commentsservice.New(comments, posts, users, inTx{}, audit, zap.NewNop())
+	ctx := domain.ContextWithIdentity(context.Background(), domain.Identity{UserID: "u1"})
+
+	verifiedAt := time.Now()
+	comment := &domain.Comment{ID: uuid.NewString(), PostID: "p1", UserID: "u1", Body: "Nice post", CreatedAt: time.Now()}
+
+	posts.EXPECT().Get(ctx, "p1").Return(&domain.Post{ID: "p1"}, nil)
+	users.EXPECT().Get(ctx, "u1").Return(&domain.User{ID: "u1", EmailVerifiedAt: &verifiedAt}, nil)
+	comments.EXPECT().Create(ctx, comment).Return(nil)
+	audit.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, event *domain.AuditEvent) error {
+		require.Equal(t, domain.AuditCommentCreate, event.Action)
+		require.Equal(t, "comment", event.TargetType)
+		require.Equal(t, comment.ID, event.TargetID)
+		require.Equal(t, "u1", event.ActorID)
+		require.Empty(t, event.Before)
+		require.NotEmpty(t, event.After)
+		return nil
+	})
+
+	require.NoError(t, svc.Create(ctx, comment))
+}
+
+func TestService_Create_Errors(t *testing.T) {
+	ctx := context.Background()
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		name    string
+		setup   func(comments *mocks.MockcommentsRepo, posts *mocks.MockpostsRepo, users *mocks.MockusersRepo)
+		wantErr error
+	}{
+		{
+			name: "post not found",
+			setup: func(comments *mocks.MockcommentsRepo, posts *mocks.MockpostsRepo, users *mocks.MockusersRepo) {
+				posts.EXPECT().Get(ctx, "p1").Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: domain.ErrPostNotFound,
+		},
+		{
+			name: "unverified author",
+			setup: func(comments *mocks.MockcommentsRepo, posts *mocks.MockpostsRepo, users *mocks.MockusersRepo) {
+				posts.EXPECT().Get(ctx, "p1").Return(&domain.Post{ID: "p1"}, nil)
+				users.EXPECT().Get(ctx, "u1").Return(&domain.User{ID: "u1"}, nil)
+			},
+			wantErr: domain.ErrEmailNotVerified,
+		},
+		{
+			name: "post deleted in the meantime",
+			setup: func(comments *mocks.MockcommentsRepo, posts *mocks.MockpostsRepo, users *mocks.MockusersRepo) {
+				posts.EXPECT().Get(ctx, "p1").Return(&domain.Post{ID: "p1"}, nil)
+				users.EXPECT().Get(ctx, "u1").Return(&domain.User{ID: "u1", EmailVerifiedAt: &verifiedAt}, nil)
+				comments.EXPECT().Create(ctx, gomock.Any()).Return(domain.ErrInvalidReference)
+			},
+			wantErr: domain.ErrPostNotFound,
+		},
+		{
+			name: "repository error",
+			setup: func(comments *mocks.MockcommentsRepo, posts *mocks.MockpostsRepo, users *mocks.MockusersRepo) {
+				posts.EXPECT().Get(ctx, "p1").Return(&domain.Post{ID: "p1"}, nil)
+				users.EXPECT().Get(ctx, "u1").Return(&domain.User{ID: "u1", EmailVerifiedAt: &verifiedAt}, nil)
+				comments.EXPECT().Create(ctx, gomock.Any()).Return(errors.New("database is locked"))
+			},
+			wantErr: domain.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			comments := mocks.NewMockcommentsRepo(ctrl)
+			posts := mocks.NewMockpostsRepo(ctrl)
+			users := mocks.NewMockusersRepo(ctrl)
+			svc := commentsservice.New(comments, posts, users, inTx{}, mocks.NewMockauditRepo(ctrl), zap.NewNop())
+			tt.setup(comments, posts, users)
+
+			err := svc.Create(ctx, &domain.Comment{ID: "c1", PostID: "p1", UserID: "u1", Body: "Nice post"})
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestService_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	comments := mocks.NewMockcommentsRepo(ctrl)
+	posts := mocks.NewMockpostsRepo(ctrl)
+	svc := commentsservice.New(comments, posts, mocks.NewMockusersRepo(ctrl), inTx{}, mocks.NewMockauditRepo(ctrl), zap.NewNop())
+	ctx := context.Background()
+
+	query := domain.CommentQuery{PostID: "p1", Page: domain.PageRequest{PageNumber: 1, PageSize: 10}}
+	want := domain.PaginatedComments{
+		Pagination: domain.Pagination{CurrentPage: 1, TotalPages: 1, TotalSize: 1},
+		Comments:   []domain.Comment{{ID: "c1", PostID: "p1"}},
+	}
+
+	posts.EXPECT().Get(ctx, "p1").Return(&domain.Post{ID: "p1"}, nil)
+	comments.EXPECT().List(ctx, query).Return(want, nil)
+
+	got, err := svc.List(ctx, query)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+
+	// Comments of missing posts cannot be listed
+	posts.EXPECT().Get(ctx, "p2").Return(nil, gorm.ErrRecordNotFound)
+	_, err = svc.List(ctx, domain.CommentQuery{PostID: "p2"})
+	require.Equal(t, domain.ErrPostNotFound, err)
+
+	// Unusable cursors are reported back as they are
+	posts.EXPECT().Get(ctx, "p1").Return(&domain.Post{ID: "p1"}, nil)
+	comments.EXPECT().List(ctx, gomock.Any()).Return(domain.PaginatedComments{}, domain.ErrInvalidInput)
+	_, err = svc.List(ctx, query)
+	require.ErrorIs(t, err, domain.ErrInvalidInput)
+}
+
+func TestService_Delete(t *testing.T) {
+	comment := &domain.Comment{ID: "c1", PostID: "p1", UserID: "author"}
+
+	tests := []struct {
+		name     string
+		identity domain.Identity
+		wantErr  error
+	}{
+		{"author", domain.Identity{UserID: "author", Roles: []domain.Role{domain.RoleMember}}, nil},
+		{"moderator", domain.Identity{UserID: "mod", Roles: []domain.Role{domain.RoleModerator}}, nil},
+		{"someone else", domain.Identity{UserID: "other", Roles: []domain.Role{domain.RoleMember}}, domain.ErrCommentForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			comments := mocks.NewMockcommentsRepo(ctrl)
+			audit := mocks.NewMockauditRepo(ctrl)
+			svc := commentsservice.New(comments, mocks.NewMockpostsRepo(ctrl), mocks.NewMockusersRepo(ctrl), inTx{}, audit, zap.NewNop())
+			ctx := domain.ContextWithIdentity(context.Background(), tt.identity)
+
+			comments.EXPECT().Get(ctx, "c1").Return(comment, nil)
+			if tt.wantErr == nil {
+				comments.EXPECT().Delete(ctx, "c1").Return(nil)
+				audit.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, event *domain.AuditEvent) error {
+					require.Equal(t, domain.AuditCommentDelete, event.Action)
+					require.Equal(t, tt.identity.UserID, event.ActorID)
+					require.NotEmpty(t, event.Before)
+					require.Empty(t, event.After)
+					return nil
+				})
+			}
+
+			err := svc.Delete(ctx, "c1")
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		comments := mocks.NewMockcommentsRepo(ctrl)
+		svc := commentsservice.New(comments, mocks.NewMockpostsRepo(ctrl), mocks.NewMockusersRepo(ctrl), inTx{}, mocks.NewMockauditRepo(ctrl), zap.NewNop())
+		ctx := context.Background()
+
+		comments.EXPECT().Get(ctx, "missing").Return(nil, gorm.ErrRecordNotFound)
+		require.Equal(t, domain.ErrCommentNotFound, svc.Delete(ctx, "missing"))
+	})
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/victor-nach/postr-backend/internal/services/commentsservice (interfaces: auditRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mocks/mock_auditrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/commentsservice auditRepo
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/victor-nach/postr-backend/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockauditRepo is a mock of auditRepo interface.
+type MockauditRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockauditRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockauditRepoMockRecorder is the mock recorder for MockauditRepo.
+type MockauditRepoMockRecorder struct {
+	mock *MockauditRepo
+}
+
+// NewMockauditRepo creates a new mock instance.
+func NewMockauditRepo(ctrl *gomock.Controller) *MockauditRepo {
+	mock := &MockauditRepo{ctrl: ctrl}
+	mock.recorder = &MockauditRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockauditRepo) EXPECT() *MockauditRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockauditRepo) Create(ctx context.Context, event *domain.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockauditRepoMockRecorder) Create(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockauditRepo)(nil).Create), ctx, event)
+}
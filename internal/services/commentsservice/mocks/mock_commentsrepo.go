@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/victor-nach/postr-backend/internal/services/commentsservice (interfaces: commentsRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mocks/mock_commentsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/commentsservice commentsRepo
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/victor-nach/postr-backend/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockcommentsRepo is a mock of commentsRepo interface.
+type MockcommentsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockcommentsRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockcommentsRepoMockRecorder is the mock recorder for MockcommentsRepo.
+type MockcommentsRepoMockRecorder struct {
+	mock *MockcommentsRepo
+}
+
+// NewMockcommentsRepo creates a new mock instance.
+func NewMockcommentsRepo(ctrl *gomock.Controller) *MockcommentsRepo {
+	mock := &MockcommentsRepo{ctrl: ctrl}
+	mock.recorder = &MockcommentsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcommentsRepo) EXPECT() *MockcommentsRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockcommentsRepo) Create(ctx context.Context, comment *domain.Comment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, comment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockcommentsRepoMockRecorder) Create(ctx, comment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockcommentsRepo)(nil).Create), ctx, comment)
+}
+
+// Delete mocks base method.
+func (m *MockcommentsRepo) Delete(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockcommentsRepoMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockcommentsRepo)(nil).Delete), ctx, id)
+}
+
+// Get mocks base method.
+func (m *MockcommentsRepo) Get(ctx context.Context, id string) (*domain.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*domain.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockcommentsRepoMockRecorder) Get(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockcommentsRepo)(nil).Get), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockcommentsRepo) List(ctx context.Context, query domain.CommentQuery) (domain.PaginatedComments, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query)
+	ret0, _ := ret[0].(domain.PaginatedComments)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockcommentsRepoMockRecorder) List(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockcommentsRepo)(nil).List), ctx, query)
+}
@@ -0,0 +1,136 @@
+package followsservice
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type service struct {
+	followsRepo followsRepo
+	usersRepo   usersRepo
+	logger      *zap.Logger
+}
+
+// New creates the follows service
+func New(followsRepo followsRepo, usersRepo usersRepo, logger *zap.Logger) domain.FollowService {
+	logger = logger.With(zap.String("package", "followsservice"))
+
+	return &service{
+		followsRepo: followsRepo,
+		usersRepo:   usersRepo,
+		logger:      logger,
+	}
+}
+
+//go:generate mockgen -destination=./mocks/mock_followsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/followsservice followsRepo
+type followsRepo interface {
+	Create(ctx context.Context, follow *domain.Follow) (bool, error)
+	Get(ctx context.Context, followerID string, followeeID string) (*domain.Follow, error)
+	Delete(ctx context.Context, followerID string, followeeID string) (bool, error)
+	List(ctx context.Context, query domain.FollowQuery) (domain.PaginatedFollowUsers, error)
+}
+
+//go:generate mockgen -destination=./mocks/mock_usersrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/followsservice usersRepo
+type usersRepo interface {
+	Get(ctx context.Context, id string) (*domain.User, error)
+}
+
+// Follow makes the follower follow the followee. Following someone already followed leaves the existing follow
+// in place and hands it back in follow
+func (h *service) Follow(ctx context.Context, follow *domain.Follow) error {
+	logr := h.logger.With(zap.String("method", "Follow"))
+
+	if follow.FollowerID == follow.FolloweeID {
+		logr.Info("User tried to follow themselves", zap.String("user_id", follow.FollowerID))
+		return domain.ErrCannotFollowSelf
+	}
+
+	if err := h.checkUser(ctx, follow.FolloweeID); err != nil {
+		logr.Info("Unable to retrieve followee", zap.String("user_id", follow.FolloweeID), zap.Error(err))
+		return err
+	}
+
+	created, err := h.followsRepo.Create(ctx, follow)
+	if err != nil {
+		// The followee was deleted since they were checked
+		if errors.Is(err, domain.ErrInvalidReference) {
+			logr.Info("Followee not found", zap.String("user_id", follow.FolloweeID))
+			return domain.ErrUserNotFound
+		}
+
+		logr.Error("Error creating follow", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	if !created {
+		existing, err := h.followsRepo.Get(ctx, follow.FollowerID, follow.FolloweeID)
+		if err != nil {
+			logr.Error("Error retrieving existing follow", zap.Error(err))
+			return domain.ErrInternalServer
+		}
+		*follow = *existing
+	}
+
+	logr.Info("User followed successfully",
+		zap.String("follower_id", follow.FollowerID), zap.String("followee_id", follow.FolloweeID), zap.Bool("created", created))
+	return nil
+}
+
+// Unfollow stops the follower following the followee, not following them in the first place is not an error
+func (h *service) Unfollow(ctx context.Context, followerID string, followeeID string) error {
+	logr := h.logger.With(zap.String("method", "Unfollow"))
+
+	removed, err := h.followsRepo.Delete(ctx, followerID, followeeID)
+	if err != nil {
+		logr.Error("Error deleting follow", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	logr.Info("User unfollowed successfully",
+		zap.String("follower_id", followerID), zap.String("followee_id", followeeID), zap.Bool("removed", removed))
+	return nil
+}
+
+// List pages through the followers of the user, or the users they follow
+func (h *service) List(ctx context.Context, query domain.FollowQuery) (domain.PaginatedFollowUsers, error) {
+	logr := h.logger.With(zap.String("method", "List"))
+
+	if err := h.checkUser(ctx, query.UserID); err != nil {
+		logr.Info("Unable to retrieve user", zap.String("user_id", query.UserID), zap.Error(err))
+		return domain.PaginatedFollowUsers{}, err
+	}
+
+	users, err := h.followsRepo.List(ctx, query)
+	if err != nil {
+		// An unusable cursor is reported back to the caller as is
+		if errors.Is(err, domain.ErrInvalidInput) {
+			logr.Info("Invalid follows page", zap.Error(err))
+			return domain.PaginatedFollowUsers{}, err
+		}
+
+		logr.Error("Error listing follows", zap.Error(err))
+		return domain.PaginatedFollowUsers{}, domain.ErrInternalServer
+	}
+
+	logr.Info("Follows listed successfully",
+		zap.String("user_id", query.UserID), zap.Bool("following", query.Following), zap.Int("count", len(users.Users)))
+	return users, nil
+}
+
+// checkUser makes sure the user exists and is not deleted
+func (h *service) checkUser(ctx context.Context, userID string) error {
+	if _, err := h.usersRepo.Get(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrUserNotFound
+		}
+
+		h.logger.Error("Error retrieving user", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+	return nil
+}
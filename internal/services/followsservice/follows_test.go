@@ -0,0 +1,150 @@
+package followsservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+	"github.com/victor-nach/postr-backend/internal/services/followsservice"
+	"github.com/victor-nach/postr-backend/internal/services/followsservice/mocks"
+)
+
+func TestService_Follow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	follows := mocks.NewMockfollowsRepo(ctrl)
+	users := mocks.NewMockusersRepo(ctrl)
+	svc := followsservice.New(follows, users, zap.NewNop())
+	ctx := context.Background()
+
+	follow := &domain.Follow{ID: "f1", FollowerID: "u1", FolloweeID: "u2", CreatedAt: time.Now()}
+	users.EXPECT().Get(ctx, "u2").Return(&domain.User{ID: "u2"}, nil)
+	follows.EXPECT().Create(ctx, follow).Return(true, nil)
+
+	require.NoError(t, svc.Follow(ctx, follow))
+	require.Equal(t, "f1", follow.ID)
+
+	// Following again hands back the follow already in place
+	existing := &domain.Follow{ID: "f0", FollowerID: "u1", FolloweeID: "u2", CreatedAt: time.Now().Add(-time.Hour)}
+	again := &domain.Follow{ID: "f2", FollowerID: "u1", FolloweeID: "u2", CreatedAt: time.Now()}
+	users.EXPECT().Get(ctx, "u2").Return(&domain.User{ID: "u2"}, nil)
+	follows.EXPECT().Create(ctx, again).Return(false, nil)
+	follows.EXPECT().Get(ctx, "u1", "u2").Return(existing, nil)
+
+	require.NoError(t, svc.Follow(ctx, again))
+	require.Equal(t, *existing, *again)
+}
+
+func TestService_Follow_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		follow  domain.Follow
+		setup   func(follows *mocks.MockfollowsRepo, users *mocks.MockusersRepo)
+		wantErr error
+	}{
+		{
+			name:    "self",
+			follow:  domain.Follow{FollowerID: "u1", FolloweeID: "u1"},
+			setup:   func(follows *mocks.MockfollowsRepo, users *mocks.MockusersRepo) {},
+			wantErr: domain.ErrCannotFollowSelf,
+		},
+		{
+			name:   "followee not found",
+			follow: domain.Follow{FollowerID: "u1", FolloweeID: "u2"},
+			setup: func(follows *mocks.MockfollowsRepo, users *mocks.MockusersRepo) {
+				users.EXPECT().Get(ctx, "u2").Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: domain.ErrUserNotFound,
+		},
+		{
+			name:   "followee deleted in the meantime",
+			follow: domain.Follow{FollowerID: "u1", FolloweeID: "u2"},
+			setup: func(follows *mocks.MockfollowsRepo, users *mocks.MockusersRepo) {
+				users.EXPECT().Get(ctx, "u2").Return(&domain.User{ID: "u2"}, nil)
+				follows.EXPECT().Create(ctx, gomock.Any()).Return(false, domain.ErrInvalidReference)
+			},
+			wantErr: domain.ErrUserNotFound,
+		},
+		{
+			name:   "repository error",
+			follow: domain.Follow{FollowerID: "u1", FolloweeID: "u2"},
+			setup: func(follows *mocks.MockfollowsRepo, users *mocks.MockusersRepo) {
+				users.EXPECT().Get(ctx, "u2").Return(&domain.User{ID: "u2"}, nil)
+				follows.EXPECT().Create(ctx, gomock.Any()).Return(false, errors.New("database is locked"))
+			},
+			wantErr: domain.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			follows := mocks.NewMockfollowsRepo(ctrl)
+			users := mocks.NewMockusersRepo(ctrl)
+			svc := followsservice.New(follows, users, zap.NewNop())
+			tt.setup(follows, users)
+
+			err := svc.Follow(ctx, &tt.follow)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestService_Unfollow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	follows := mocks.NewMockfollowsRepo(ctrl)
+	svc := followsservice.New(follows, mocks.NewMockusersRepo(ctrl), zap.NewNop())
+	ctx := context.Background()
+
+	// Unfollowing someone not followed is not an error
+	for _, removed := range []bool{true, false} {
+		follows.EXPECT().Delete(ctx, "u1", "u2").Return(removed, nil)
+		require.NoError(t, svc.Unfollow(ctx, "u1", "u2"))
+	}
+
+	follows.EXPECT().Delete(ctx, "u1", "u2").Return(false, errors.New("database is locked"))
+	require.Equal(t, domain.ErrInternalServer, svc.Unfollow(ctx, "u1", "u2"))
+}
+
+func TestService_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	follows := mocks.NewMockfollowsRepo(ctrl)
+	users := mocks.NewMockusersRepo(ctrl)
+	svc := followsservice.New(follows, users, zap.NewNop())
+	ctx := context.Background()
+
+	query := domain.FollowQuery{UserID: "u1", Following: true, Page: domain.PageRequest{PageSize: 10, Keyset: true}}
+	want := domain.PaginatedFollowUsers{Users: []domain.FollowUser{{ID: "u2", Firstname: "Ada"}}}
+
+	users.EXPECT().Get(ctx, "u1").Return(&domain.User{ID: "u1"}, nil)
+	follows.EXPECT().List(ctx, query).Return(want, nil)
+
+	got, err := svc.List(ctx, query)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+
+	users.EXPECT().Get(ctx, "u1").Return(nil, gorm.ErrRecordNotFound)
+	_, err = svc.List(ctx, query)
+	require.Equal(t, domain.ErrUserNotFound, err)
+
+	users.EXPECT().Get(ctx, "u1").Return(&domain.User{ID: "u1"}, nil)
+	follows.EXPECT().List(ctx, query).Return(domain.PaginatedFollowUsers{}, domain.ErrInvalidInput)
+	_, err = svc.List(ctx, query)
+	require.Equal(t, domain.ErrInvalidInput, err)
+}
@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/victor-nach/postr-backend/internal/services/followsservice (interfaces: followsRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mocks/mock_followsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/followsservice followsRepo
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/victor-nach/postr-backend/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockfollowsRepo is a mock of followsRepo interface.
+type MockfollowsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockfollowsRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockfollowsRepoMockRecorder is the mock recorder for MockfollowsRepo.
+type MockfollowsRepoMockRecorder struct {
+	mock *MockfollowsRepo
+}
+
+// NewMockfollowsRepo creates a new mock instance.
+func NewMockfollowsRepo(ctrl *gomock.Controller) *MockfollowsRepo {
+	mock := &MockfollowsRepo{ctrl: ctrl}
+	mock.recorder = &MockfollowsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockfollowsRepo) EXPECT() *MockfollowsRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockfollowsRepo) Create(ctx context.Context, follow *domain.Follow) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, follow)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockfollowsRepoMockRecorder) Create(ctx, follow any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockfollowsRepo)(nil).Create), ctx, follow)
+}
+
+// Delete mocks base method.
+func (m *MockfollowsRepo) Delete(ctx context.Context, followerID, followeeID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, followerID, followeeID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockfollowsRepoMockRecorder) Delete(ctx, followerID, followeeID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockfollowsRepo)(nil).Delete), ctx, followerID, followeeID)
+}
+
+// Get mocks base method.
+func (m *MockfollowsRepo) Get(ctx context.Context, followerID, followeeID string) (*domain.Follow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, followerID, followeeID)
+	ret0, _ := ret[0].(*domain.Follow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockfollowsRepoMockRecorder) Get(ctx, followerID, followeeID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockfollowsRepo)(nil).Get), ctx, followerID, followeeID)
+}
+
+// List mocks base method.
+func (m *MockfollowsRepo) List(ctx context.Context, query domain.FollowQuery) (domain.PaginatedFollowUsers, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query)
+	ret0, _ := ret[0].(domain.PaginatedFollowUsers)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockfollowsRepoMockRecorder) List(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockfollowsRepo)(nil).List), ctx, query)
+}
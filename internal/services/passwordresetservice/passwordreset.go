@@ -0,0 +1,220 @@
+package passwordresetservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+const (
+	tokenIssuer = "postr-backend"
+	// tokenAudience tells reset tokens apart from the other tokens signed with the same secret
+	tokenAudience = "password-reset"
+
+	// resetPath is the endpoint the emailed token is sent to
+	resetPath = "/auth/password/reset"
+)
+
+// resetClaims are the claims of a reset token. Password is a fingerprint of the password hash the token was
+// issued for, so a token stops working once the password is reset, which makes it single use
+type resetClaims struct {
+	jwt.RegisteredClaims
+	Password string `json:"pwd"`
+}
+
+type service struct {
+	usersRepo usersRepo
+	mailer    domain.Mailer
+	secret    []byte
+	tokenTTL  time.Duration
+	publicURL string
+	now       func() time.Time
+	logger    *zap.Logger
+
+	// sending tracks the reset emails still being signed and sent
+	sending sync.WaitGroup
+}
+
+// New creates the password reset service. Tokens are signed with secret and expire after tokenTTL, the email
+// tells users to send them to the API at publicURL
+func New(usersRepo usersRepo, mailer domain.Mailer, secret []byte, tokenTTL time.Duration, publicURL string, logger *zap.Logger, opts ...Option) domain.PasswordResetService {
+	logger = logger.With(zap.String("package", "passwordresetservice"))
+
+	svc := &service{
+		usersRepo: usersRepo,
+		mailer:    mailer,
+		secret:    secret,
+		tokenTTL:  tokenTTL,
+		publicURL: publicURL,
+		now:       time.Now,
+		logger:    logger,
+	}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+// Option changes how the service is set up
+type Option func(*service)
+
+// WithClock makes the service read the time from now instead of the system clock
+func WithClock(now func() time.Time) Option {
+	return func(h *service) {
+		h.now = now
+	}
+}
+
+//go:generate mockgen -destination=./mocks/mock_usersrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/passwordresetservice usersRepo
+type usersRepo interface {
+	Get(ctx context.Context, id string) (*domain.User, error)
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	ResetPassword(ctx context.Context, id string, oldHash string, newHash string, at time.Time) error
+}
+
+func (h *service) ForgotPassword(ctx context.Context, email string) {
+	logr := h.logger.With(zap.String("method", "ForgotPassword"))
+
+	user, err := h.usersRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("Password reset for an unknown email")
+			return
+		}
+
+		logr.Error("Error retrieving user", zap.Error(err))
+		return
+	}
+
+	// The token is signed and emailed once the caller is answered, so how long they wait does not tell
+	// whether the email is registered
+	ctx = context.WithoutCancel(ctx)
+	h.sending.Add(1)
+	go func() {
+		defer h.sending.Done()
+		h.sendResetEmail(ctx, logr, user)
+	}()
+}
+
+// Wait returns once the reset emails asked for so far are sent
+func (h *service) Wait() {
+	h.sending.Wait()
+}
+
+// sendResetEmail emails the user a reset token. Errors are only logged, nobody is waiting for the outcome
+func (h *service) sendResetEmail(ctx context.Context, logr *zap.Logger, user *domain.User) {
+	now := h.now()
+	expiresAt := now.Add(h.tokenTTL)
+
+	claims := resetClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    tokenIssuer,
+			Audience:  jwt.ClaimStrings{tokenAudience},
+			Subject:   user.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Password: fingerprint(user.PasswordHash),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(h.secret)
+	if err != nil {
+		logr.Error("Error signing reset token", zap.Error(err))
+		return
+	}
+
+	message := domain.Email{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to reset the password of your postr account. To choose a new password, send it along\n"+
+			"with the token below to POST %s:\n\n"+
+			"%s\n\n"+
+			"The token can be used once and expires on %s. Resetting your password logs you out everywhere.\n"+
+			"If you did not ask for this, you can ignore this email, your password stays as it is.\n",
+			user.Firstname, h.publicURL+resetPath, token, expiresAt.UTC().Format(time.RFC1123)),
+	}
+
+	if err := h.mailer.Send(ctx, message); err != nil {
+		logr.Error("Error sending reset email", zap.String("user_id", user.ID), zap.Error(err))
+		return
+	}
+
+	logr.Info("Password reset email sent successfully", zap.String("user_id", user.ID))
+}
+
+// ResetPassword sets the password of the token's user and revokes their sessions, so whoever knew the old
+// password is logged out
+func (h *service) ResetPassword(ctx context.Context, token string, password string) error {
+	logr := h.logger.With(zap.String("method", "ResetPassword"))
+
+	claims := &resetClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+		return h.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithAudience(tokenAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(h.now),
+	)
+	if err != nil || claims.Subject == "" {
+		logr.Info("Invalid reset token", zap.Error(err))
+		return domain.ErrInvalidResetToken
+	}
+
+	user, err := h.usersRepo.Get(ctx, claims.Subject)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("Reset token of a deleted user", zap.String("user_id", claims.Subject))
+			return domain.ErrInvalidResetToken
+		}
+
+		logr.Error("Error retrieving user", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	if fingerprint(user.PasswordHash) != claims.Password {
+		logr.Info("Reset token already used", zap.String("user_id", user.ID))
+		return domain.ErrInvalidResetToken
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		logr.Error("Error hashing password", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	if err := h.usersRepo.ResetPassword(ctx, user.ID, user.PasswordHash, string(hash), h.now()); err != nil {
+		// Another reset with the same token got there first
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("Reset token already used", zap.String("user_id", user.ID))
+			return domain.ErrInvalidResetToken
+		}
+
+		logr.Error("Error resetting password", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	logr.Info("Password reset successfully", zap.String("user_id", user.ID))
+	return nil
+}
+
+// fingerprint identifies a password hash without giving it away
+func fingerprint(passwordHash string) string {
+	sum := sha256.Sum256([]byte(passwordHash))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,149 @@
+package passwordresetservice_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+	domainmocks "github.com/victor-nach/postr-backend/internal/domain/mocks"
+	"github.com/victor-nach/postr-backend/internal/services/passwordresetservice"
+	"github.com/victor-nach/postr-backend/internal/services/passwordresetservice/mocks"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// forgotToken asks for a reset of the user's password and returns the token emailed to them
+func forgotToken(t *testing.T, svc domain.PasswordResetService, mockUsersRepo *mocks.MockusersRepo, mockMailer *domainmocks.MockMailer, user domain.User) string {
+	ctx := context.Background()
+
+	var sent domain.Email
+	mockUsersRepo.EXPECT().GetByEmail(ctx, user.Email).Return(&user, nil)
+	mockMailer.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, email domain.Email) error {
+		sent = email
+		return nil
+	})
+
+	svc.ForgotPassword(ctx, user.Email)
+	svc.Wait()
+	require.Equal(t, user.Email, sent.To)
+	require.Contains(t, sent.Body, "POST http://localhost:8080/auth/password/reset")
+
+	token := regexp.MustCompile(`(?m)^eyJ\S+$`).FindString(sent.Body)
+	require.NotEmpty(t, token, "the email should carry the token")
+	return token
+}
+
+func TestService_ForgotAndReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockMailer := domainmocks.NewMockMailer(ctrl)
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	svc := passwordresetservice.New(mockUsersRepo, mockMailer, testSecret, time.Hour, "http://localhost:8080", zap.NewNop(), passwordresetservice.WithClock(func() time.Time { return now }))
+
+	ctx := context.Background()
+	user := domain.User{ID: "u1", Firstname: "Alice", Email: "alice@example.com", PasswordHash: "old-hash"}
+	token := forgotToken(t, svc, mockUsersRepo, mockMailer, user)
+
+	mockUsersRepo.EXPECT().Get(ctx, "u1").Return(&user, nil)
+	mockUsersRepo.EXPECT().ResetPassword(ctx, "u1", "old-hash", gomock.Any(), now).
+		DoAndReturn(func(ctx context.Context, id string, oldHash string, newHash string, at time.Time) error {
+			require.NoError(t, bcrypt.CompareHashAndPassword([]byte(newHash), []byte("new password")), "only a hash of the password should be stored")
+			user.PasswordHash = newHash
+			return nil
+		})
+
+	require.NoError(t, svc.ResetPassword(ctx, token, "new password"))
+
+	// The password it was issued for is gone, so the token cannot be used again
+	mockUsersRepo.EXPECT().Get(ctx, "u1").Return(&user, nil)
+	require.Equal(t, domain.ErrInvalidResetToken, svc.ResetPassword(ctx, token, "another password"))
+}
+
+func TestService_ForgotPassword_UnknownEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockMailer := domainmocks.NewMockMailer(ctrl)
+	svc := passwordresetservice.New(mockUsersRepo, mockMailer, testSecret, time.Hour, "http://localhost:8080", zap.NewNop())
+	ctx := context.Background()
+
+	// Nothing is sent, and nothing tells the caller so
+	mockUsersRepo.EXPECT().GetByEmail(ctx, "nobody@example.com").Return(nil, gorm.ErrRecordNotFound)
+	svc.ForgotPassword(ctx, "nobody@example.com")
+
+	mockUsersRepo.EXPECT().GetByEmail(ctx, "alice@example.com").Return(&domain.User{ID: "u1", Email: "alice@example.com"}, nil)
+	mockMailer.EXPECT().Send(gomock.Any(), gomock.Any()).Return(errors.New("disk full"))
+	svc.ForgotPassword(ctx, "alice@example.com")
+	svc.Wait()
+}
+
+func TestService_ForgotPassword_AnswersBeforeSending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockMailer := domainmocks.NewMockMailer(ctrl)
+	svc := passwordresetservice.New(mockUsersRepo, mockMailer, testSecret, time.Hour, "http://localhost:8080", zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// The caller is answered while the email is still being sent, and hanging up does not stop it
+	release := make(chan struct{})
+	mockUsersRepo.EXPECT().GetByEmail(ctx, "alice@example.com").Return(&domain.User{ID: "u1", Email: "alice@example.com"}, nil)
+	mockMailer.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, email domain.Email) error {
+		<-release
+		assert.NoError(t, ctx.Err(), "the email should outlive the request")
+		return nil
+	})
+
+	svc.ForgotPassword(ctx, "alice@example.com")
+	cancel()
+	close(release)
+	svc.Wait()
+}
+
+func TestService_ResetPassword_InvalidToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockMailer := domainmocks.NewMockMailer(ctrl)
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	clock := now
+	svc := passwordresetservice.New(mockUsersRepo, mockMailer, testSecret, time.Hour, "http://localhost:8080", zap.NewNop(), passwordresetservice.WithClock(func() time.Time { return clock }))
+
+	ctx := context.Background()
+	user := domain.User{ID: "u1", Firstname: "Alice", Email: "alice@example.com", PasswordHash: "old-hash"}
+	token := forgotToken(t, svc, mockUsersRepo, mockMailer, user)
+
+	require.Equal(t, domain.ErrInvalidResetToken, svc.ResetPassword(ctx, "not-a-token", "new password"))
+
+	// Signed with another secret
+	other := passwordresetservice.New(mockUsersRepo, mockMailer, []byte("another secret that is long enough"), time.Hour, "http://localhost:8080", zap.NewNop())
+	require.Equal(t, domain.ErrInvalidResetToken, other.ResetPassword(ctx, token, "new password"))
+
+	// Of a deleted user
+	mockUsersRepo.EXPECT().Get(ctx, "u1").Return(nil, gorm.ErrRecordNotFound)
+	require.Equal(t, domain.ErrInvalidResetToken, svc.ResetPassword(ctx, token, "new password"))
+
+	// Used concurrently, the other reset got there first
+	mockUsersRepo.EXPECT().Get(ctx, "u1").Return(&user, nil)
+	mockUsersRepo.EXPECT().ResetPassword(ctx, "u1", "old-hash", gomock.Any(), now).Return(gorm.ErrRecordNotFound)
+	require.Equal(t, domain.ErrInvalidResetToken, svc.ResetPassword(ctx, token, "new password"))
+
+	// Expired
+	clock = now.Add(2 * time.Hour)
+	require.Equal(t, domain.ErrInvalidResetToken, svc.ResetPassword(ctx, token, "new password"))
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/victor-nach/postr-backend/internal/services/postsservice (interfaces: mentionsRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mocks/mock_mentionsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/postsservice mentionsRepo
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockmentionsRepo is a mock of mentionsRepo interface.
+type MockmentionsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockmentionsRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockmentionsRepoMockRecorder is the mock recorder for MockmentionsRepo.
+type MockmentionsRepoMockRecorder struct {
+	mock *MockmentionsRepo
+}
+
+// NewMockmentionsRepo creates a new mock instance.
+func NewMockmentionsRepo(ctrl *gomock.Controller) *MockmentionsRepo {
+	mock := &MockmentionsRepo{ctrl: ctrl}
+	mock.recorder = &MockmentionsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockmentionsRepo) EXPECT() *MockmentionsRepoMockRecorder {
+	return m.recorder
+}
+
+// Set mocks base method.
+func (m *MockmentionsRepo) Set(ctx context.Context, postID string, userIDs []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, postID, userIDs)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockmentionsRepoMockRecorder) Set(ctx, postID, userIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockmentionsRepo)(nil).Set), ctx, postID, userIDs)
+}
@@ -69,17 +69,121 @@ func (mr *MockpostsRepoMockRecorder) Delete(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockpostsRepo)(nil).Delete), ctx, id)
 }
 
-// ListByUserID mocks base method.
-func (m *MockpostsRepo) ListByUserID(ctx context.Context, userId string) ([]domain.Post, error) {
+// Feed mocks base method.
+func (m *MockpostsRepo) Feed(ctx context.Context, userID string, page domain.PageRequest) (domain.PaginatedPosts, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListByUserID", ctx, userId)
-	ret0, _ := ret[0].([]domain.Post)
+	ret := m.ctrl.Call(m, "Feed", ctx, userID, page)
+	ret0, _ := ret[0].(domain.PaginatedPosts)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListByUserID indicates an expected call of ListByUserID.
-func (mr *MockpostsRepoMockRecorder) ListByUserID(ctx, userId any) *gomock.Call {
+// Feed indicates an expected call of Feed.
+func (mr *MockpostsRepoMockRecorder) Feed(ctx, userID, page any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUserID", reflect.TypeOf((*MockpostsRepo)(nil).ListByUserID), ctx, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Feed", reflect.TypeOf((*MockpostsRepo)(nil).Feed), ctx, userID, page)
+}
+
+// Get mocks base method.
+func (m *MockpostsRepo) Get(ctx context.Context, id string) (*domain.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*domain.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockpostsRepoMockRecorder) Get(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockpostsRepo)(nil).Get), ctx, id)
+}
+
+// GetWithDeleted mocks base method.
+func (m *MockpostsRepo) GetWithDeleted(ctx context.Context, id string) (*domain.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithDeleted", ctx, id)
+	ret0, _ := ret[0].(*domain.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithDeleted indicates an expected call of GetWithDeleted.
+func (mr *MockpostsRepoMockRecorder) GetWithDeleted(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithDeleted", reflect.TypeOf((*MockpostsRepo)(nil).GetWithDeleted), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockpostsRepo) List(ctx context.Context, query domain.PostQuery) (domain.PaginatedPosts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query)
+	ret0, _ := ret[0].(domain.PaginatedPosts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockpostsRepoMockRecorder) List(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockpostsRepo)(nil).List), ctx, query)
+}
+
+// ListRevisions mocks base method.
+func (m *MockpostsRepo) ListRevisions(ctx context.Context, postID string) ([]domain.PostRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevisions", ctx, postID)
+	ret0, _ := ret[0].([]domain.PostRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRevisions indicates an expected call of ListRevisions.
+func (mr *MockpostsRepoMockRecorder) ListRevisions(ctx, postID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevisions", reflect.TypeOf((*MockpostsRepo)(nil).ListRevisions), ctx, postID)
+}
+
+// Restore mocks base method.
+func (m *MockpostsRepo) Restore(ctx context.Context, id string) (*domain.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(*domain.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockpostsRepoMockRecorder) Restore(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockpostsRepo)(nil).Restore), ctx, id)
+}
+
+// Search mocks base method.
+func (m *MockpostsRepo) Search(ctx context.Context, search domain.PostSearch) (domain.PaginatedPostSearchResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, search)
+	ret0, _ := ret[0].(domain.PaginatedPostSearchResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockpostsRepoMockRecorder) Search(ctx, search any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockpostsRepo)(nil).Search), ctx, search)
+}
+
+// Update mocks base method.
+func (m *MockpostsRepo) Update(ctx context.Context, post *domain.Post) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, post)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockpostsRepoMockRecorder) Update(ctx, post any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockpostsRepo)(nil).Update), ctx, post)
 }
@@ -0,0 +1,75 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/victor-nach/postr-backend/internal/services/postsservice (interfaces: tagsRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mocks/mock_tagsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/postsservice tagsRepo
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MocktagsRepo is a mock of tagsRepo interface.
+type MocktagsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MocktagsRepoMockRecorder
+	isgomock struct{}
+}
+
+// MocktagsRepoMockRecorder is the mock recorder for MocktagsRepo.
+type MocktagsRepoMockRecorder struct {
+	mock *MocktagsRepo
+}
+
+// NewMocktagsRepo creates a new mock instance.
+func NewMocktagsRepo(ctrl *gomock.Controller) *MocktagsRepo {
+	mock := &MocktagsRepo{ctrl: ctrl}
+	mock.recorder = &MocktagsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktagsRepo) EXPECT() *MocktagsRepoMockRecorder {
+	return m.recorder
+}
+
+// ForPosts mocks base method.
+func (m *MocktagsRepo) ForPosts(ctx context.Context, postIDs ...string) (map[string][]string, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range postIDs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ForPosts", varargs...)
+	ret0, _ := ret[0].(map[string][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ForPosts indicates an expected call of ForPosts.
+func (mr *MocktagsRepoMockRecorder) ForPosts(ctx any, postIDs ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, postIDs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForPosts", reflect.TypeOf((*MocktagsRepo)(nil).ForPosts), varargs...)
+}
+
+// Set mocks base method.
+func (m *MocktagsRepo) Set(ctx context.Context, postID string, names []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, postID, names)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MocktagsRepoMockRecorder) Set(ctx, postID, names any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MocktagsRepo)(nil).Set), ctx, postID, names)
+}
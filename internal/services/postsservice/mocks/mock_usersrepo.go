@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	domain "github.com/victor-nach/postr-backend/internal/domain"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,6 +41,36 @@ func (m *MockusersRepo) EXPECT() *MockusersRepoMockRecorder {
 	return m.recorder
 }
 
+// Get mocks base method.
+func (m *MockusersRepo) Get(ctx context.Context, id string) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockusersRepoMockRecorder) Get(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockusersRepo)(nil).Get), ctx, id)
+}
+
+// Mentioned mocks base method.
+func (m *MockusersRepo) Mentioned(ctx context.Context, mentions domain.Mentions) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Mentioned", ctx, mentions)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Mentioned indicates an expected call of Mentioned.
+func (mr *MockusersRepoMockRecorder) Mentioned(ctx, mentions any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Mentioned", reflect.TypeOf((*MockusersRepo)(nil).Mentioned), ctx, mentions)
+}
+
 // Validate mocks base method.
 func (m *MockusersRepo) Validate(ctx context.Context, userID string) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,20 @@
+package postsservice
+
+import (
+	"context"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+// authorize allows the caller to change the post when they wrote it or may moderate posts
+func authorize(ctx context.Context, post *domain.Post) error {
+	identity, ok := domain.IdentityFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
+	if identity.UserID == post.UserID || identity.Can(domain.PermPostsModerate) {
+		return nil
+	}
+	return domain.ErrPostForbidden
+}
@@ -3,89 +3,398 @@ package postsservice
 import (
 	"context"
 	"errors"
-	
+	"fmt"
+	"slices"
+	"time"
+
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
 	"github.com/victor-nach/postr-backend/internal/domain"
+	"github.com/victor-nach/postr-backend/pkg/diff"
 )
 
 type service struct {
-	postsRepo postsRepo
-	usersRepo usersRepo
-	logger    *zap.Logger
+	postsRepo     postsRepo
+	usersRepo     usersRepo
+	reactionsRepo reactionsRepo
+	tagsRepo      tagsRepo
+	mentionsRepo  mentionsRepo
+	tx            transactor
+	audit         auditRepo
+	mailer        domain.Mailer
+	publicURL     string
+	logger        *zap.Logger
 }
 
-func New(postsRepo postsRepo, usersRepo usersRepo, logger *zap.Logger) domain.PostService {
+// New creates the posts service, every change is recorded in the audit log in the transaction of the change.
+// Users mentioned in a post are emailed a link to it on the API at publicURL
+func New(postsRepo postsRepo, usersRepo usersRepo, reactionsRepo reactionsRepo, tagsRepo tagsRepo, mentionsRepo mentionsRepo, tx transactor, audit auditRepo, mailer domain.Mailer, publicURL string, logger *zap.Logger) domain.PostService {
 	logger = logger.With(zap.String("package", "postsservice"))
 
 	return &service{
-		usersRepo: usersRepo,
-		postsRepo: postsRepo,
-		logger:    logger,
+		usersRepo:     usersRepo,
+		postsRepo:     postsRepo,
+		reactionsRepo: reactionsRepo,
+		tagsRepo:      tagsRepo,
+		mentionsRepo:  mentionsRepo,
+		tx:            tx,
+		audit:         audit,
+		mailer:        mailer,
+		publicURL:     publicURL,
+		logger:        logger,
 	}
 }
 
+// postPath is the endpoint the link of a mention email points to
+const postPath = "/posts/"
+
 
 //go:generate mockgen -destination=./mocks/mock_postsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/postsservice postsRepo
 
 type postsRepo interface {
 	Create(ctx context.Context, post *domain.Post) error
-	ListByUserID(ctx context.Context, userId string) ([]domain.Post, error)
+	Get(ctx context.Context, id string) (*domain.Post, error)
+	GetWithDeleted(ctx context.Context, id string) (*domain.Post, error)
+	Update(ctx context.Context, post *domain.Post) error
+	List(ctx context.Context, query domain.PostQuery) (domain.PaginatedPosts, error)
+	Search(ctx context.Context, search domain.PostSearch) (domain.PaginatedPostSearchResults, error)
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) (*domain.Post, error)
+	ListRevisions(ctx context.Context, postID string) ([]domain.PostRevision, error)
+	Feed(ctx context.Context, userID string, page domain.PageRequest) (domain.PaginatedPosts, error)
 }
 
 
 //go:generate mockgen -destination=./mocks/mock_usersrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/postsservice usersRepo
 type usersRepo interface {
+	Get(ctx context.Context, id string) (*domain.User, error)
 	Validate(ctx context.Context, userID string) error
+	Mentioned(ctx context.Context, mentions domain.Mentions) ([]domain.User, error)
+}
+
+//go:generate mockgen -destination=./mocks/mock_reactionsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/postsservice reactionsRepo
+type reactionsRepo interface {
+	Counts(ctx context.Context, userID string, postIDs ...string) (map[string][]domain.ReactionCount, error)
 }
 
+//go:generate mockgen -destination=./mocks/mock_tagsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/postsservice tagsRepo
+type tagsRepo interface {
+	Set(ctx context.Context, postID string, names []string) error
+	ForPosts(ctx context.Context, postIDs ...string) (map[string][]string, error)
+}
+
+//go:generate mockgen -destination=./mocks/mock_mentionsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/postsservice mentionsRepo
+type mentionsRepo interface {
+	Set(ctx context.Context, postID string, userIDs []string) ([]string, error)
+}
+
+//go:generate mockgen -destination=./mocks/mock_auditrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/postsservice auditRepo
+type auditRepo interface {
+	Create(ctx context.Context, event *domain.AuditEvent) error
+}
+
+// transactor runs fn in a database transaction, the repositories called with the context handed to fn take part in it
+type transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Create stores the post, only authors who verified their email can post
 func (h *service) Create(ctx context.Context, post *domain.Post) error {
 	logr := h.logger.With(zap.String("method", "Create"))
 
-	// Validate userID
-	if err := h.validateUserID(ctx, post.UserID); err != nil {
-		logr.Error("Invalid userID", zap.Error(err))
-		return domain.ErrUserNotFound
+	author, err := h.usersRepo.Get(ctx, post.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Error("Invalid userID", zap.Error(err))
+			return domain.ErrUserNotFound
+		}
+
+		logr.Error("Error retrieving author", zap.Error(err))
+		return domain.ErrInternalServer
 	}
 
-	if err := h.postsRepo.Create(ctx, post); err != nil {
+	if !author.EmailVerified() {
+		logr.Info("Author has not verified their email", zap.String("user_id", author.ID))
+		return domain.ErrEmailNotVerified
+	}
+
+	post.Tags = domain.PostTags(post.Tags, post.Body)
+
+	mentioned, err := h.mentioned(ctx, post.Body)
+	if err != nil {
+		logr.Error("Error resolving mentions", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	var added []string
+	err = h.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := h.postsRepo.Create(ctx, post); err != nil {
+			return err
+		}
+		if err := h.tagsRepo.Set(ctx, post.ID, post.Tags); err != nil {
+			return err
+		}
+		if added, err = h.mentionsRepo.Set(ctx, post.ID, userIDs(mentioned)); err != nil {
+			return err
+		}
+		return h.record(ctx, domain.AuditPostCreate, post.ID, nil, post)
+	})
+	if err != nil {
+		// Constraint violations are reported back to the caller as is
+		var derr domain.DomainError
+		if errors.As(err, &derr) {
+			logr.Info("Post violates a constraint", zap.Error(err))
+			return derr
+		}
+
 		logr.Error("Error creating post", zap.Error(err))
 		return domain.ErrInternalServer
 	}
 
 	logr.Info("Post created successfully", zap.Any("post", post))
 
+	h.notifyMentioned(ctx, logr, post, mentioned, added)
+
 	return nil
 }
 
-func (h *service) List(ctx context.Context, userID string) ([]domain.Post, error) {
+// Update edits the post, only its author or a moderator can
+func (h *service) Update(ctx context.Context, id string, update domain.PostUpdate) (*domain.Post, error) {
+	logr := h.logger.With(zap.String("method", "Update"))
+
+	// The post is read and edited in one transaction, so an edit made meanwhile is not overwritten
+	var post *domain.Post
+	var mentioned []domain.User
+	var added []string
+	err := h.tx.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		post, err = h.getPost(ctx, id)
+		if err != nil {
+			logr.Info("Unable to retrieve post", zap.String("id", id), zap.Error(err))
+			return err
+		}
+
+		if err := authorize(ctx, post); err != nil {
+			logr.Info("Caller may not edit the post", zap.String("id", id), zap.Error(err))
+			return err
+		}
+
+		tags, err := h.tagsRepo.ForPosts(ctx, post.ID)
+		if err != nil {
+			return err
+		}
+		post.Tags = tags[post.ID]
+
+		before := *post
+		update.Apply(post)
+		post.UpdatedAt = time.Now()
+
+		// Tags given with the post are the ones that are not hashtags of its body, unless the update replaces them
+		retag := update.Body != nil || update.Tags != nil
+		if retag {
+			given := update.Tags
+			if given == nil {
+				hashtags := domain.ParseHashtags(before.Body)
+				kept := slices.DeleteFunc(slices.Clone(before.Tags), func(tag string) bool {
+					return slices.Contains(hashtags, tag)
+				})
+				given = &kept
+			}
+			post.Tags = domain.PostTags(*given, post.Body)
+		}
+
+		if err := h.postsRepo.Update(ctx, post); err != nil {
+			return err
+		}
+		if retag {
+			if err := h.tagsRepo.Set(ctx, post.ID, post.Tags); err != nil {
+				return err
+			}
+		}
+
+		// The users mentioned are those of the new body, only the ones it newly mentions are notified
+		if update.Body != nil {
+			if mentioned, err = h.mentioned(ctx, post.Body); err != nil {
+				return err
+			}
+			if added, err = h.mentionsRepo.Set(ctx, post.ID, userIDs(mentioned)); err != nil {
+				return err
+			}
+		}
+		return h.record(ctx, domain.AuditPostUpdate, post.ID, before, post)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("Post not found", zap.String("id", id))
+			return nil, domain.ErrPostNotFound
+		}
+
+		// Not finding the post, not being allowed to edit it or losing the race to another edit are reported as is
+		var derr domain.DomainError
+		if errors.As(err, &derr) {
+			logr.Info("Post not updated", zap.String("id", id), zap.Error(err))
+			return nil, derr
+		}
+
+		logr.Error("Error updating post", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	if err := h.withDetails(ctx, post); err != nil {
+		logr.Error("Error filling in post details", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	logr.Info("Post updated successfully", zap.Any("post", post))
+
+	h.notifyMentioned(ctx, logr, post, mentioned, added)
+
+	return post, nil
+}
+
+func (h *service) Get(ctx context.Context, id string) (*domain.Post, error) {
+	logr := h.logger.With(zap.String("method", "Get"))
+
+	post, err := h.getPost(ctx, id)
+	if err != nil {
+		logr.Info("Unable to retrieve post", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	if err := h.withDetails(ctx, post); err != nil {
+		logr.Error("Error filling in post details", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	logr.Info("Post retrieved successfully", zap.String("id", id))
+	return post, nil
+}
+
+func (h *service) List(ctx context.Context, query domain.PostQuery) (domain.PaginatedPosts, error) {
 	logr := h.logger.With(zap.String("method", "List"))
-	
+
 	// Validate userID
-	if err := h.validateUserID(ctx, userID); err != nil {
-		logr.Error("Invalid userID", zap.Error(err))
-		return []domain.Post{}, err
+	if query.UserID != "" {
+		if err := h.validateUserID(ctx, query.UserID); err != nil {
+			logr.Error("Invalid userID", zap.Error(err))
+			return domain.PaginatedPosts{}, err
+		}
+	}
+	if query.MentionedUserID != "" {
+		if err := h.validateUserID(ctx, query.MentionedUserID); err != nil {
+			logr.Info("Invalid mentioned userID", zap.Error(err))
+			return domain.PaginatedPosts{}, err
+		}
 	}
 
-	posts, err := h.postsRepo.ListByUserID(ctx, userID)
+	paginatedPosts, err := h.postsRepo.List(ctx, query)
 	if err != nil {
+		// An unusable cursor is reported back to the caller as is
+		if errors.Is(err, domain.ErrInvalidInput) {
+			logr.Info("Invalid post query", zap.Error(err))
+			return domain.PaginatedPosts{}, err
+		}
+
 		logr.Error("Error listing posts", zap.Error(err))
-		return []domain.Post{}, domain.ErrInternalServer
+		return domain.PaginatedPosts{}, domain.ErrInternalServer
+	}
+
+	posts := make([]*domain.Post, len(paginatedPosts.Posts))
+	for i := range paginatedPosts.Posts {
+		posts[i] = &paginatedPosts.Posts[i]
+	}
+	if err := h.withDetails(ctx, posts...); err != nil {
+		logr.Error("Error filling in post details", zap.Error(err))
+		return domain.PaginatedPosts{}, domain.ErrInternalServer
+	}
+
+	logr.Info("Posts listed successfully", zap.String("user_id", query.UserID), zap.Int("count", len(paginatedPosts.Posts)))
+	return paginatedPosts, nil
+}
+
+// Feed pages through the posts of the users userID follows, most recent first
+func (h *service) Feed(ctx context.Context, userID string, page domain.PageRequest) (domain.PaginatedPosts, error) {
+	logr := h.logger.With(zap.String("method", "Feed"))
+
+	paginatedPosts, err := h.postsRepo.Feed(ctx, userID, page)
+	if err != nil {
+		// An unusable cursor is reported back to the caller as is
+		if errors.Is(err, domain.ErrInvalidInput) {
+			logr.Info("Invalid feed page", zap.Error(err))
+			return domain.PaginatedPosts{}, err
+		}
+
+		logr.Error("Error reading feed", zap.Error(err))
+		return domain.PaginatedPosts{}, domain.ErrInternalServer
+	}
+
+	posts := make([]*domain.Post, len(paginatedPosts.Posts))
+	for i := range paginatedPosts.Posts {
+		posts[i] = &paginatedPosts.Posts[i]
+	}
+	if err := h.withDetails(ctx, posts...); err != nil {
+		logr.Error("Error filling in post details", zap.Error(err))
+		return domain.PaginatedPosts{}, domain.ErrInternalServer
+	}
+
+	logr.Info("Feed read successfully", zap.String("user_id", userID), zap.Int("count", len(paginatedPosts.Posts)))
+	return paginatedPosts, nil
+}
+
+func (h *service) Search(ctx context.Context, search domain.PostSearch) (domain.PaginatedPostSearchResults, error) {
+	logr := h.logger.With(zap.String("method", "Search"))
+
+	results, err := h.postsRepo.Search(ctx, search)
+	if err != nil {
+		// A search with nothing to search for is reported back to the caller as is
+		if errors.Is(err, domain.ErrInvalidInput) {
+			logr.Info("Invalid search", zap.String("query", search.Query), zap.Error(err))
+			return domain.PaginatedPostSearchResults{}, err
+		}
+
+		logr.Error("Error searching posts", zap.Error(err))
+		return domain.PaginatedPostSearchResults{}, domain.ErrInternalServer
+	}
+
+	posts := make([]*domain.Post, len(results.Results))
+	for i := range results.Results {
+		posts[i] = &results.Results[i].Post
+	}
+	if err := h.withDetails(ctx, posts...); err != nil {
+		logr.Error("Error filling in post details", zap.Error(err))
+		return domain.PaginatedPostSearchResults{}, domain.ErrInternalServer
 	}
 
-	logr.Info("Posts listed successfully", zap.String("user_id", userID), zap.Int("count", len(posts)))
-	return posts, nil
+	logr.Info("Posts searched successfully", zap.String("query", search.Query), zap.Int("total", results.Pagination.TotalSize))
+	return results, nil
 }
 
+// Delete soft deletes the post, only its author or a moderator can
 func (h *service) Delete(ctx context.Context, id string) error {
 	logr := h.logger.With(zap.String("method", "Delete"))
 
-	if err := h.postsRepo.Delete(ctx, id); err != nil {
+	post, err := h.getPost(ctx, id)
+	if err != nil {
+		logr.Info("Unable to retrieve post", zap.String("id", id), zap.Error(err))
+		return err
+	}
+
+	if err := authorize(ctx, post); err != nil {
+		logr.Info("Caller may not delete the post", zap.String("id", id), zap.Error(err))
+		return err
+	}
+
+	err = h.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := h.postsRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return h.record(ctx, domain.AuditPostDelete, id, post, nil)
+	})
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			logr.Info("Post not found", zap.String("id", id))
-			return domain.ErrUserNotFound
+			return domain.ErrPostNotFound
 		}
 
 		logr.Error("Error deleting post", zap.Error(err))
@@ -97,6 +406,231 @@ func (h *service) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// Restore undoes the soft delete of a post, only its author or a moderator can
+func (h *service) Restore(ctx context.Context, id string) (*domain.Post, error) {
+	logr := h.logger.With(zap.String("method", "Restore"))
+
+	deleted, err := h.postsRepo.GetWithDeleted(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("Post not found", zap.String("id", id))
+			return nil, domain.ErrPostNotFound
+		}
+
+		logr.Error("Error retrieving post", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	if err := authorize(ctx, deleted); err != nil {
+		logr.Info("Caller may not restore the post", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	var post *domain.Post
+	err = h.tx.Transaction(ctx, func(ctx context.Context) error {
+		post, err = h.postsRepo.Restore(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Restoring a post that is not deleted changes nothing, so there is nothing to record
+		if !deleted.DeletedAt.Valid {
+			return nil
+		}
+		return h.record(ctx, domain.AuditPostRestore, id, deleted, post)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("Post not found", zap.String("id", id))
+			return nil, domain.ErrPostNotFound
+		}
+
+		if errors.Is(err, domain.ErrPostAuthorDeleted) {
+			logr.Info("Post author is deleted", zap.String("id", id))
+			return nil, domain.ErrPostAuthorDeleted
+		}
+
+		logr.Error("Error restoring post", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	if err := h.withDetails(ctx, post); err != nil {
+		logr.Error("Error filling in post details", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	logr.Info("Post restored successfully", zap.String("id", id))
+	return post, nil
+}
+
+// ListRevisions returns every version of the post, oldest first, the last one being the current version
+func (h *service) ListRevisions(ctx context.Context, id string) ([]domain.PostRevision, error) {
+	logr := h.logger.With(zap.String("method", "ListRevisions"))
+
+	post, err := h.getPost(ctx, id)
+	if err != nil {
+		logr.Info("Unable to retrieve post", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	revisions, err := h.postsRepo.ListRevisions(ctx, id)
+	if err != nil {
+		logr.Error("Error listing post revisions", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	version := 1
+	if len(revisions) > 0 {
+		version = revisions[len(revisions)-1].Version + 1
+	}
+
+	revisions = append(revisions, domain.PostRevision{
+		PostID:    post.ID,
+		Version:   version,
+		Title:     post.Title,
+		Body:      post.Body,
+		CreatedAt: post.UpdatedAt,
+	})
+
+	logr.Info("Post revisions listed successfully", zap.String("id", id), zap.Int("count", len(revisions)))
+	return revisions, nil
+}
+
+// DiffRevisions returns the changes made between two versions of the post
+func (h *service) DiffRevisions(ctx context.Context, id string, from int, to int) (domain.PostDiff, error) {
+	logr := h.logger.With(zap.String("method", "DiffRevisions"))
+
+	revisions, err := h.ListRevisions(ctx, id)
+	if err != nil {
+		return domain.PostDiff{}, err
+	}
+
+	var fromRevision, toRevision *domain.PostRevision
+	for i := range revisions {
+		if revisions[i].Version == from {
+			fromRevision = &revisions[i]
+		}
+		if revisions[i].Version == to {
+			toRevision = &revisions[i]
+		}
+	}
+
+	if fromRevision == nil || toRevision == nil {
+		logr.Info("Post revision not found", zap.String("id", id), zap.Int("from", from), zap.Int("to", to))
+		return domain.PostDiff{}, domain.ErrPostRevisionNotFound
+	}
+
+	return domain.PostDiff{
+		PostID: id,
+		From:   from,
+		To:     to,
+		Title:  diff.Lines(fromRevision.Title, toRevision.Title),
+		Body:   diff.Lines(fromRevision.Body, toRevision.Body),
+	}, nil
+}
+
+// record appends the change to the audit log, it must be called in the transaction of the change so the event is
+// only kept if the change is
+func (h *service) record(ctx context.Context, action domain.AuditAction, targetID string, before any, after any) error {
+	event, err := domain.NewAuditEvent(ctx, action, targetID, before, after)
+	if err != nil {
+		return err
+	}
+	return h.audit.Create(ctx, &event)
+}
+
+// withDetails fills in the tags and reaction counts of the posts, marking the reaction kinds the caller reacted with
+func (h *service) withDetails(ctx context.Context, posts ...*domain.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	var userID string
+	if identity, ok := domain.IdentityFromContext(ctx); ok {
+		userID = identity.UserID
+	}
+
+	ids := make([]string, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	counts, err := h.reactionsRepo.Counts(ctx, userID, ids...)
+	if err != nil {
+		return err
+	}
+
+	tags, err := h.tagsRepo.ForPosts(ctx, ids...)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		post.Reactions = counts[post.ID]
+		post.Tags = tags[post.ID]
+	}
+	return nil
+}
+
+// mentioned resolves the @mentions of the body to users, mentions of unknown users are left as plain text
+func (h *service) mentioned(ctx context.Context, body string) ([]domain.User, error) {
+	mentions := domain.ParseMentions(body)
+	if mentions.Empty() {
+		return nil, nil
+	}
+	return h.usersRepo.Mentioned(ctx, mentions)
+}
+
+// notifyMentioned emails the users the post newly mentions, its author is not told about their own mentions.
+// The post is saved by then, so failing to send an email is only logged
+func (h *service) notifyMentioned(ctx context.Context, logr *zap.Logger, post *domain.Post, mentioned []domain.User, added []string) {
+	link := h.publicURL + postPath + post.ID
+
+	for _, user := range mentioned {
+		if user.ID == post.UserID || !slices.Contains(added, user.ID) {
+			continue
+		}
+
+		email := domain.Email{
+			To:      user.Email,
+			Subject: "You were mentioned in a post",
+			Body: fmt.Sprintf("Hi %s,\n\n"+
+				"You were mentioned in the post \"%s\", you can read it at the link below:\n\n"+
+				"%s\n",
+				user.Firstname, post.Title, link),
+		}
+
+		if err := h.mailer.Send(ctx, email); err != nil {
+			logr.Error("Error sending mention email", zap.String("post_id", post.ID), zap.String("user_id", user.ID), zap.Error(err))
+			continue
+		}
+		logr.Info("Mention email sent successfully", zap.String("post_id", post.ID), zap.String("user_id", user.ID))
+	}
+}
+
+// userIDs returns the ids of the users
+func userIDs(users []domain.User) []string {
+	ids := make([]string, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids
+}
+
+func (h *service) getPost(ctx context.Context, id string) (*domain.Post, error) {
+	post, err := h.postsRepo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPostNotFound
+		}
+
+		h.logger.Error("Error retrieving post", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	return post, nil
+}
+
 func (h *service) validateUserID(ctx context.Context, userID string) error{
 	if err := h.usersRepo.Validate(ctx, userID); err != nil {
 		return domain.ErrUserNotFound
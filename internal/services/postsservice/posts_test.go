@@ -2,30 +2,63 @@ package postsservice_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
+	"github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
 	"github.com/victor-nach/postr-backend/internal/domain"
+	domainmocks "github.com/victor-nach/postr-backend/internal/domain/mocks"
 	"github.com/victor-nach/postr-backend/internal/services/postsservice"
 	"github.com/victor-nach/postr-backend/internal/services/postsservice/mocks"
+	"github.com/victor-nach/postr-backend/pkg/diff"
 )
 
+// inTx runs the function of a transaction right away, there is no database behind these tests
+type inTx struct{}
+
+func (inTx) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// noTags stands in for the tags repository where the tags of the posts do not matter, they have none
+type noTags struct{}
+
+func (noTags) Set(ctx context.Context, postID string, names []string) error {
+	return nil
+}
+
+func (noTags) ForPosts(ctx context.Context, postIDs ...string) (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+// noMentions stands in for the mentions repository where the mentions of the posts do not matter, they have none
+type noMentions struct{}
+
+func (noMentions) Set(ctx context.Context, postID string, userIDs []string) ([]string, error) {
+	return []string{}, nil
+}
+
 func TestService_Create(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
 	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
 
 	logger := zap.NewNop()
-	svc := postsservice.New(mockPostsRepo, mockUsersRepo, logger)
+	mockAudit := mocks.NewMockauditRepo(ctrl)
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mockAudit, nil, "", logger)
 
 	ctx := context.Background()
 	post := &domain.Post{
@@ -35,11 +68,72 @@ func TestService_Create(t *testing.T) {
 		CreatedAt: time.Now(),
 	}
 
-	mockUsersRepo.EXPECT().Validate(ctx, post.UserID).Return(nil)
+	verifiedAt := time.Now()
+	mockUsersRepo.EXPECT().Get(ctx, post.UserID).Return(&domain.User{ID: post.UserID, EmailVerifiedAt: &verifiedAt}, nil)
 	mockPostsRepo.EXPECT().Create(ctx, post).Return(nil)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, event *domain.AuditEvent) error {
+		require.Equal(t, domain.AuditPostCreate, event.Action)
+		require.Equal(t, "post", event.TargetType)
+		require.Equal(t, post.ID, event.TargetID)
+		require.Nil(t, event.Before)
+		require.Contains(t, string(event.After), `"title":"Title 1"`)
+		return nil
+	})
 
 	err := svc.Create(ctx, post)
 	require.NoError(t, err)
+
+	// A change that cannot be audited is not made
+	mockUsersRepo.EXPECT().Get(ctx, post.UserID).Return(&domain.User{ID: post.UserID, EmailVerifiedAt: &verifiedAt}, nil)
+	mockPostsRepo.EXPECT().Create(ctx, post).Return(nil)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).Return(errors.New("disk I/O error"))
+
+	err = svc.Create(ctx, post)
+	require.Equal(t, domain.ErrInternalServer, err)
+}
+
+func TestService_Create_Unverified(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mocks.NewMockauditRepo(ctrl), nil, "", zap.NewNop())
+
+	ctx := context.Background()
+	post := &domain.Post{ID: uuid.NewString(), UserID: uuid.NewString(), Title: "Title 1"}
+
+	mockUsersRepo.EXPECT().Get(ctx, post.UserID).Return(&domain.User{ID: post.UserID}, nil)
+
+	err := svc.Create(ctx, post)
+	require.Equal(t, domain.ErrEmailNotVerified, err)
+
+	mockUsersRepo.EXPECT().Get(ctx, post.UserID).Return(nil, gorm.ErrRecordNotFound)
+
+	err = svc.Create(ctx, post)
+	require.Equal(t, domain.ErrUserNotFound, err)
+}
+
+func TestService_Create_InvalidReference(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mocks.NewMockauditRepo(ctrl), nil, "", zap.NewNop())
+
+	ctx := context.Background()
+	post := &domain.Post{ID: uuid.NewString(), UserID: uuid.NewString(), Title: "Title 1"}
+
+	// The author can be deleted between the check and the insert
+	verifiedAt := time.Now()
+	mockUsersRepo.EXPECT().Get(ctx, post.UserID).Return(&domain.User{ID: post.UserID, EmailVerifiedAt: &verifiedAt}, nil)
+	mockPostsRepo.EXPECT().Create(ctx, post).Return(domain.ErrInvalidReference)
+
+	err := svc.Create(ctx, post)
+	require.Equal(t, domain.ErrInvalidReference, err)
 }
 
 func TestService_List_Success(t *testing.T) {
@@ -48,9 +142,10 @@ func TestService_List_Success(t *testing.T) {
 
 	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
 	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
 
 	logger := zap.NewNop()
-	svc := postsservice.New(mockPostsRepo, mockUsersRepo, logger)
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mocks.NewMockauditRepo(ctrl), nil, "", logger)
 
 	ctx := context.Background()
 	userID := uuid.NewString()
@@ -70,12 +165,24 @@ func TestService_List_Success(t *testing.T) {
 		},
 	}
 
+	expected := domain.PaginatedPosts{
+		Pagination: domain.Pagination{CurrentPage: 1, TotalPages: 1, TotalSize: 2},
+		Posts:      expectedPosts,
+	}
+
+	likes := domain.NewReactionCounts(map[domain.ReactionKind]int{domain.ReactionLike: 2}, nil)
+
 	mockUsersRepo.EXPECT().Validate(ctx, userID).Return(nil)
-	mockPostsRepo.EXPECT().ListByUserID(ctx, userID).Return(expectedPosts, nil)
+	mockPostsRepo.EXPECT().List(ctx, domain.PostQuery{UserID: userID}).Return(expected, nil)
+	mockReactionsRepo.EXPECT().Counts(ctx, "", expectedPosts[0].ID, expectedPosts[1].ID).
+		Return(map[string][]domain.ReactionCount{expectedPosts[0].ID: likes}, nil)
 
-	posts, err := svc.List(ctx, userID)
+	posts, err := svc.List(ctx, domain.PostQuery{UserID: userID})
 	require.NoError(t, err)
-	require.Equal(t, expectedPosts, posts)
+	require.Equal(t, expected.Pagination, posts.Pagination)
+	require.Len(t, posts.Posts, 2)
+	require.Equal(t, likes, posts.Posts[0].Reactions)
+	require.Nil(t, posts.Posts[1].Reactions)
 }
 
 func TestService_List_InvalidUser(t *testing.T) {
@@ -84,37 +191,146 @@ func TestService_List_InvalidUser(t *testing.T) {
 
 	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
 	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
 
 	logger := zap.NewNop()
-	svc := postsservice.New(mockPostsRepo, mockUsersRepo, logger)
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mocks.NewMockauditRepo(ctrl), nil, "", logger)
 
 	ctx := context.Background()
 	userID := uuid.NewString()
 
 	mockUsersRepo.EXPECT().Validate(ctx, userID).Return(domain.ErrUserNotFound)
 
-	posts, err := svc.List(ctx, userID)
+	posts, err := svc.List(ctx, domain.PostQuery{UserID: userID})
 	require.Error(t, err)
 	require.Equal(t, domain.ErrUserNotFound, err)
-	require.Empty(t, posts)
+	require.Empty(t, posts.Posts)
 }
 
-func TestService_Delete_Success(t *testing.T) {
+func TestService_List_All(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
+
+	logger := zap.NewNop()
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mocks.NewMockauditRepo(ctrl), nil, "", logger)
+
+	ctx := context.Background()
+	expectedPosts := []domain.Post{{ID: uuid.NewString(), UserID: uuid.NewString(), Title: "Post 1"}}
+
+	// Without a user filter there is no user to validate
+	mockPostsRepo.EXPECT().List(ctx, domain.PostQuery{}).Return(domain.PaginatedPosts{Posts: expectedPosts}, nil)
+	mockReactionsRepo.EXPECT().Counts(ctx, "", expectedPosts[0].ID).Return(map[string][]domain.ReactionCount{}, nil)
+
+	posts, err := svc.List(ctx, domain.PostQuery{})
+	require.NoError(t, err)
+	require.Equal(t, expectedPosts, posts.Posts)
+}
+
+func TestService_List_InvalidCursor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
+
+	logger := zap.NewNop()
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mocks.NewMockauditRepo(ctrl), nil, "", logger)
+
+	ctx := context.Background()
+	query := domain.PostQuery{Page: domain.PageRequest{Cursor: "stale", PageSize: 10}}
+	cursorErr := domain.ErrInvalidInput.WithFieldErrors(validation.Errors{"cursor": errors.New("is invalid")})
+
+	// Cursor errors are the caller's to fix, so they are passed on rather than reported as internal errors
+	mockPostsRepo.EXPECT().List(ctx, query).Return(domain.PaginatedPosts{}, cursorErr)
+
+	_, err := svc.List(ctx, query)
+	require.ErrorIs(t, err, domain.ErrInvalidInput)
+	require.Equal(t, cursorErr, err)
+}
+
+func TestService_Get_NotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
 	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
 
 	logger := zap.NewNop()
-	svc := postsservice.New(mockPostsRepo, mockUsersRepo, logger)
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mocks.NewMockauditRepo(ctrl), nil, "", logger)
 
 	ctx := context.Background()
 	postID := uuid.NewString()
 
-	mockPostsRepo.EXPECT().Delete(ctx, postID).Return(nil)
+	mockPostsRepo.EXPECT().Get(ctx, postID).Return(nil, gorm.ErrRecordNotFound)
 
-	err := svc.Delete(ctx, postID)
+	post, err := svc.Get(ctx, postID)
+	require.Equal(t, domain.ErrPostNotFound, err)
+	require.Nil(t, post)
+}
+
+func TestService_Get_Reactions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
+	svc := postsservice.New(mockPostsRepo, mocks.NewMockusersRepo(ctrl), mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mocks.NewMockauditRepo(ctrl), nil, "", zap.NewNop())
+
+	post := &domain.Post{ID: uuid.NewString(), UserID: uuid.NewString(), Title: "Liked"}
+	viewer := uuid.NewString()
+	ctx := domain.ContextWithIdentity(context.Background(), domain.Identity{UserID: viewer})
+
+	// The counts are those seen by the caller
+	reactions := domain.NewReactionCounts(
+		map[domain.ReactionKind]int{domain.ReactionLike: 3, domain.ReactionWow: 1},
+		map[domain.ReactionKind]bool{domain.ReactionLike: true},
+	)
+	mockPostsRepo.EXPECT().Get(ctx, post.ID).Return(post, nil)
+	mockReactionsRepo.EXPECT().Counts(ctx, viewer, post.ID).Return(map[string][]domain.ReactionCount{post.ID: reactions}, nil)
+
+	got, err := svc.Get(ctx, post.ID)
+	require.NoError(t, err)
+	require.Equal(t, reactions, got.Reactions)
+
+	mockPostsRepo.EXPECT().Get(ctx, post.ID).Return(post, nil)
+	mockReactionsRepo.EXPECT().Counts(ctx, viewer, post.ID).Return(nil, errors.New("database is locked"))
+
+	_, err = svc.Get(ctx, post.ID)
+	require.Equal(t, domain.ErrInternalServer, err)
+}
+
+func TestService_Delete_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
+
+	logger := zap.NewNop()
+	mockAudit := mocks.NewMockauditRepo(ctrl)
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mockAudit, nil, "", logger)
+
+	post := &domain.Post{ID: uuid.NewString(), UserID: uuid.NewString()}
+	ctx := domain.ContextWithIdentity(context.Background(), domain.Identity{UserID: post.UserID})
+
+	mockPostsRepo.EXPECT().Get(ctx, post.ID).Return(post, nil)
+	mockPostsRepo.EXPECT().Delete(ctx, post.ID).Return(nil)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, event *domain.AuditEvent) error {
+		require.Equal(t, domain.AuditPostDelete, event.Action)
+		require.Equal(t, post.UserID, event.ActorID)
+		require.NotNil(t, event.Before)
+		require.Nil(t, event.After)
+		return nil
+	})
+
+	err := svc.Delete(ctx, post.ID)
 	require.NoError(t, err)
 }
 
@@ -124,16 +340,584 @@ func TestService_Delete_NotFound(t *testing.T) {
 
 	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
 	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
 
 	logger := zap.NewNop()
-	svc := postsservice.New(mockPostsRepo, mockUsersRepo, logger)
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mocks.NewMockauditRepo(ctrl), nil, "", logger)
 
-	ctx := context.Background()
+	ctx := domain.ContextWithIdentity(context.Background(), domain.Identity{UserID: uuid.NewString()})
 	postID := uuid.NewString()
 
-	mockPostsRepo.EXPECT().Delete(ctx, postID).Return(gorm.ErrRecordNotFound)
+	mockPostsRepo.EXPECT().Get(ctx, postID).Return(nil, gorm.ErrRecordNotFound)
 
 	err := svc.Delete(ctx, postID)
 	require.Error(t, err)
-	require.Equal(t, domain.ErrUserNotFound, err)
-}
\ No newline at end of file
+	require.Equal(t, domain.ErrPostNotFound, err)
+}
+
+func TestService_Restore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
+
+	logger := zap.NewNop()
+	mockAudit := mocks.NewMockauditRepo(ctrl)
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mockAudit, nil, "", logger)
+
+	post := &domain.Post{ID: uuid.NewString(), UserID: uuid.NewString(), Title: "Back again"}
+	deleted := &domain.Post{ID: post.ID, UserID: post.UserID, Title: post.Title, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}
+	ctx := domain.ContextWithIdentity(context.Background(), domain.Identity{UserID: post.UserID})
+
+	mockReactionsRepo.EXPECT().Counts(ctx, post.UserID, post.ID).Return(map[string][]domain.ReactionCount{}, nil).Times(2)
+	mockPostsRepo.EXPECT().GetWithDeleted(ctx, post.ID).Return(deleted, nil)
+	mockPostsRepo.EXPECT().Restore(ctx, post.ID).Return(post, nil)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, event *domain.AuditEvent) error {
+		require.Equal(t, domain.AuditPostRestore, event.Action)
+		require.Contains(t, string(event.After), `"deletedAt":null`)
+		return nil
+	})
+	restored, err := svc.Restore(ctx, post.ID)
+	require.NoError(t, err)
+	require.Equal(t, post, restored)
+
+	// Restoring a post that is not deleted changes nothing, so nothing is recorded
+	mockPostsRepo.EXPECT().GetWithDeleted(ctx, post.ID).Return(post, nil)
+	mockPostsRepo.EXPECT().Restore(ctx, post.ID).Return(post, nil)
+	_, err = svc.Restore(ctx, post.ID)
+	require.NoError(t, err)
+
+	mockPostsRepo.EXPECT().GetWithDeleted(ctx, post.ID).Return(nil, gorm.ErrRecordNotFound)
+	_, err = svc.Restore(ctx, post.ID)
+	require.Equal(t, domain.ErrPostNotFound, err)
+
+	mockPostsRepo.EXPECT().GetWithDeleted(ctx, post.ID).Return(post, nil)
+	mockPostsRepo.EXPECT().Restore(ctx, post.ID).Return(nil, domain.ErrPostAuthorDeleted)
+	_, err = svc.Restore(ctx, post.ID)
+	require.Equal(t, domain.ErrPostAuthorDeleted, err)
+}
+
+func TestService_Authorization(t *testing.T) {
+	post := &domain.Post{ID: uuid.NewString(), UserID: uuid.NewString(), Title: "Title 1", Body: "Body 1"}
+	title := "Title 2"
+
+	tests := []struct {
+		name     string
+		identity *domain.Identity
+		want     error
+	}{
+		{"author", &domain.Identity{UserID: post.UserID}, nil},
+		{"admin", &domain.Identity{UserID: uuid.NewString(), Roles: []domain.Role{domain.RoleAdmin}}, nil},
+		{"moderator", &domain.Identity{UserID: uuid.NewString(), Roles: []domain.Role{domain.RoleModerator}}, nil},
+		{"another member", &domain.Identity{UserID: uuid.NewString(), Roles: []domain.Role{domain.RoleMember}}, domain.ErrPostForbidden},
+		{"anonymous", nil, domain.ErrUnauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+			mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+			mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
+			mockAudit := mocks.NewMockauditRepo(ctrl)
+			svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mockAudit, nil, "", zap.NewNop())
+
+			ctx := context.Background()
+			if tt.identity != nil {
+				ctx = domain.ContextWithIdentity(ctx, *tt.identity)
+			}
+
+			allowed := 0
+			if tt.want == nil {
+				allowed = 1
+			}
+
+			// Each post is fetched fresh, an update changes the fetched post in place
+			mockPostsRepo.EXPECT().Get(ctx, post.ID).DoAndReturn(func(context.Context, string) (*domain.Post, error) {
+				p := *post
+				return &p, nil
+			}).Times(2)
+			mockPostsRepo.EXPECT().GetWithDeleted(ctx, post.ID).Return(post, nil)
+			mockPostsRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil).Times(allowed)
+			mockPostsRepo.EXPECT().Delete(ctx, post.ID).Return(nil).Times(allowed)
+			mockPostsRepo.EXPECT().Restore(ctx, post.ID).Return(post, nil).Times(allowed)
+			// The updated and the restored posts come back with their reactions
+			mockReactionsRepo.EXPECT().Counts(ctx, gomock.Any(), post.ID).Return(map[string][]domain.ReactionCount{}, nil).Times(2 * allowed)
+			// The post is not deleted, so only the update and the delete are recorded
+			mockAudit.EXPECT().Create(ctx, gomock.Any()).Return(nil).Times(2 * allowed)
+
+			_, err := svc.Update(ctx, post.ID, domain.PostUpdate{Title: &title})
+			require.Equal(t, tt.want, err)
+
+			err = svc.Delete(ctx, post.ID)
+			require.Equal(t, tt.want, err)
+
+			_, err = svc.Restore(ctx, post.ID)
+			require.Equal(t, tt.want, err)
+		})
+	}
+}
+
+func TestService_Update_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
+
+	logger := zap.NewNop()
+	mockAudit := mocks.NewMockauditRepo(ctrl)
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mockAudit, nil, "", logger)
+
+	existing := &domain.Post{
+		ID:        uuid.NewString(),
+		UserID:    uuid.NewString(),
+		Title:     "Title 1",
+		Body:      "Body 1",
+		CreatedAt: time.Now(),
+	}
+	ctx := domain.ContextWithIdentity(context.Background(), domain.Identity{UserID: existing.UserID})
+	title := "Title 2"
+
+	mockPostsRepo.EXPECT().Get(ctx, existing.ID).Return(existing, nil)
+	mockPostsRepo.EXPECT().Update(ctx, gomock.AssignableToTypeOf(&domain.Post{})).
+		DoAndReturn(func(ctx context.Context, p *domain.Post) error {
+			require.Equal(t, "Title 2", p.Title)
+			require.Equal(t, "Body 1", p.Body)
+			require.False(t, p.UpdatedAt.IsZero())
+			return nil
+		})
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, event *domain.AuditEvent) error {
+		require.Equal(t, domain.AuditPostUpdate, event.Action)
+		require.Contains(t, string(event.Before), `"title":"Title 1"`)
+		require.Contains(t, string(event.After), `"title":"Title 2"`)
+		return nil
+	})
+	mockReactionsRepo.EXPECT().Counts(ctx, existing.UserID, existing.ID).Return(map[string][]domain.ReactionCount{}, nil)
+
+	post, err := svc.Update(ctx, existing.ID, domain.PostUpdate{Title: &title})
+	require.NoError(t, err)
+	require.Equal(t, "Title 2", post.Title)
+}
+
+func TestService_Update_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
+
+	logger := zap.NewNop()
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mocks.NewMockauditRepo(ctrl), nil, "", logger)
+
+	ctx := context.Background()
+	postID := uuid.NewString()
+	title := "Title 2"
+
+	mockPostsRepo.EXPECT().Get(ctx, postID).Return(nil, gorm.ErrRecordNotFound)
+
+	post, err := svc.Update(ctx, postID, domain.PostUpdate{Title: &title})
+	require.Equal(t, domain.ErrPostNotFound, err)
+	require.Nil(t, post)
+}
+
+func TestService_ListRevisions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
+
+	logger := zap.NewNop()
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mocks.NewMockauditRepo(ctrl), nil, "", logger)
+
+	ctx := context.Background()
+	post := &domain.Post{ID: uuid.NewString(), Title: "Title 3", Body: "Body 3", UpdatedAt: time.Now()}
+	stored := []domain.PostRevision{
+		{ID: uuid.NewString(), PostID: post.ID, Version: 1, Title: "Title 1", Body: "Body 1"},
+		{ID: uuid.NewString(), PostID: post.ID, Version: 2, Title: "Title 2", Body: "Body 1"},
+	}
+
+	mockPostsRepo.EXPECT().Get(ctx, post.ID).Return(post, nil)
+	mockPostsRepo.EXPECT().ListRevisions(ctx, post.ID).Return(stored, nil)
+
+	revisions, err := svc.ListRevisions(ctx, post.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 3)
+	require.Equal(t, 3, revisions[2].Version, "the current version comes last")
+	require.Equal(t, "Title 3", revisions[2].Title)
+}
+
+func TestService_DiffRevisions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
+
+	logger := zap.NewNop()
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mocks.NewMockauditRepo(ctrl), nil, "", logger)
+
+	ctx := context.Background()
+	post := &domain.Post{ID: uuid.NewString(), Title: "Title", Body: "line one\nline three", UpdatedAt: time.Now()}
+	stored := []domain.PostRevision{
+		{ID: uuid.NewString(), PostID: post.ID, Version: 1, Title: "Title", Body: "line one\nline two"},
+	}
+
+	mockPostsRepo.EXPECT().Get(ctx, post.ID).Return(post, nil).Times(2)
+	mockPostsRepo.EXPECT().ListRevisions(ctx, post.ID).Return(stored, nil).Times(2)
+
+	postDiff, err := svc.DiffRevisions(ctx, post.ID, 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, []diff.Line{{Op: diff.Equal, Text: "Title"}}, postDiff.Title)
+	require.Equal(t, []diff.Line{
+		{Op: diff.Equal, Text: "line one"},
+		{Op: diff.Delete, Text: "line two"},
+		{Op: diff.Insert, Text: "line three"},
+	}, postDiff.Body)
+
+	_, err = svc.DiffRevisions(ctx, post.ID, 1, 5)
+	require.Equal(t, domain.ErrPostRevisionNotFound, err)
+}
+
+func TestService_Search(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
+
+	logger := zap.NewNop()
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mocks.NewMockauditRepo(ctrl), nil, "", logger)
+
+	ctx := context.Background()
+	search := domain.PostSearch{Query: "beach", Page: domain.PageRequest{PageNumber: 1, PageSize: 10}}
+	expected := domain.PaginatedPostSearchResults{
+		Pagination: domain.Pagination{CurrentPage: 1, TotalPages: 1, TotalSize: 1},
+		Results: []domain.PostSearchResult{{
+			Post:           domain.Post{ID: uuid.NewString(), Title: "A Day at the Beach"},
+			TitleHighlight: "A Day at the <mark>Beach</mark>",
+		}},
+	}
+
+	mockPostsRepo.EXPECT().Search(ctx, search).Return(expected, nil)
+	mockReactionsRepo.EXPECT().Counts(ctx, "", expected.Results[0].ID).Return(map[string][]domain.ReactionCount{}, nil)
+	results, err := svc.Search(ctx, search)
+	require.NoError(t, err)
+	require.Equal(t, expected, results)
+
+	// Invalid searches are passed on, anything else is an internal error
+	mockPostsRepo.EXPECT().Search(ctx, search).Return(domain.PaginatedPostSearchResults{}, domain.ErrInvalidInput)
+	_, err = svc.Search(ctx, search)
+	require.Equal(t, domain.ErrInvalidInput, err)
+
+	mockPostsRepo.EXPECT().Search(ctx, search).Return(domain.PaginatedPostSearchResults{}, errors.New("no such table: posts_fts"))
+	_, err = svc.Search(ctx, search)
+	require.Equal(t, domain.ErrInternalServer, err)
+}
+
+func TestService_Feed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
+	svc := postsservice.New(mockPostsRepo, mocks.NewMockusersRepo(ctrl), mockReactionsRepo, noTags{}, noMentions{}, inTx{}, mocks.NewMockauditRepo(ctrl), nil, "", zap.NewNop())
+
+	reader := uuid.NewString()
+	ctx := domain.ContextWithIdentity(context.Background(), domain.Identity{UserID: reader})
+	page := domain.PageRequest{PageSize: 10, Keyset: true}
+
+	post := domain.Post{ID: uuid.NewString(), UserID: uuid.NewString(), Title: "Followed"}
+	likes := domain.NewReactionCounts(map[domain.ReactionKind]int{domain.ReactionLike: 1}, map[domain.ReactionKind]bool{domain.ReactionLike: true})
+
+	mockPostsRepo.EXPECT().Feed(ctx, reader, page).Return(domain.PaginatedPosts{Posts: []domain.Post{post}}, nil)
+	mockReactionsRepo.EXPECT().Counts(ctx, reader, post.ID).Return(map[string][]domain.ReactionCount{post.ID: likes}, nil)
+
+	feed, err := svc.Feed(ctx, reader, page)
+	require.NoError(t, err)
+	require.Len(t, feed.Posts, 1)
+	require.Equal(t, likes, feed.Posts[0].Reactions)
+
+	// Unusable cursors are passed on, anything else is an internal error
+	mockPostsRepo.EXPECT().Feed(ctx, reader, page).Return(domain.PaginatedPosts{}, domain.ErrInvalidInput)
+	_, err = svc.Feed(ctx, reader, page)
+	require.Equal(t, domain.ErrInvalidInput, err)
+
+	mockPostsRepo.EXPECT().Feed(ctx, reader, page).Return(domain.PaginatedPosts{}, errors.New("no such index"))
+	_, err = svc.Feed(ctx, reader, page)
+	require.Equal(t, domain.ErrInternalServer, err)
+}
+
+func TestService_Create_Tags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockTagsRepo := mocks.NewMocktagsRepo(ctrl)
+	mockAudit := mocks.NewMockauditRepo(ctrl)
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mocks.NewMockreactionsRepo(ctrl), mockTagsRepo, noMentions{}, inTx{}, mockAudit, nil, "", zap.NewNop())
+
+	ctx := context.Background()
+	post := &domain.Post{
+		ID:     uuid.NewString(),
+		UserID: uuid.NewString(),
+		Title:  "Weekend",
+		// Fragments of links and entities, and tags of digits only, are not hashtags
+		Body:      "Off to the #Beach with #friends, see https://example.com/#top &#39; #2025",
+		Tags:      []string{"#Travel", "beach"},
+		CreatedAt: time.Now(),
+	}
+
+	verifiedAt := time.Now()
+	mockUsersRepo.EXPECT().Get(ctx, post.UserID).Return(&domain.User{ID: post.UserID, EmailVerifiedAt: &verifiedAt}, nil)
+	mockPostsRepo.EXPECT().Create(ctx, post).Return(nil)
+	mockTagsRepo.EXPECT().Set(ctx, post.ID, []string{"beach", "friends", "travel"}).Return(nil)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+	require.NoError(t, svc.Create(ctx, post))
+	require.Equal(t, []string{"beach", "friends", "travel"}, post.Tags)
+}
+
+func TestService_Create_TooManyTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockTagsRepo := mocks.NewMocktagsRepo(ctrl)
+	mockAudit := mocks.NewMockauditRepo(ctrl)
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mocks.NewMockreactionsRepo(ctrl), mockTagsRepo, noMentions{}, inTx{}, mockAudit, nil, "", zap.NewNop())
+
+	// The given tags are kept first, then the hashtags in the order they are written
+	var body strings.Builder
+	for i := range 20000 {
+		fmt.Fprintf(&body, "#tag%05d ", 20000-i)
+	}
+	ctx := context.Background()
+	post := &domain.Post{ID: uuid.NewString(), UserID: uuid.NewString(), Title: "Tags", Body: body.String(), Tags: []string{"zebra", "apple"}, CreatedAt: time.Now()}
+	want := []string{"apple", "tag19993", "tag19994", "tag19995", "tag19996", "tag19997", "tag19998", "tag19999", "tag20000", "zebra"}
+
+	verifiedAt := time.Now()
+	mockUsersRepo.EXPECT().Get(ctx, post.UserID).Return(&domain.User{ID: post.UserID, EmailVerifiedAt: &verifiedAt}, nil)
+	mockPostsRepo.EXPECT().Create(ctx, post).Return(nil)
+	mockTagsRepo.EXPECT().Set(ctx, post.ID, want).Return(nil)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+	require.NoError(t, svc.Create(ctx, post))
+	require.Len(t, post.Tags, domain.MaxPostTags)
+	require.Equal(t, want, post.Tags)
+}
+
+func TestService_Update_Tags(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func() domain.PostUpdate
+		want   []string
+	}{
+		{
+			// The tags given with the post are kept, the hashtags are read from the new body
+			name: "body",
+			update: func() domain.PostUpdate {
+				body := "Now about #hiking"
+				return domain.PostUpdate{Body: &body}
+			},
+			want: []string{"hiking", "travel"},
+		},
+		{
+			name: "tags",
+			update: func() domain.PostUpdate {
+				tags := []string{"food"}
+				return domain.PostUpdate{Tags: &tags}
+			},
+			want: []string{"beach", "food"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+			mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
+			mockTagsRepo := mocks.NewMocktagsRepo(ctrl)
+			mockAudit := mocks.NewMockauditRepo(ctrl)
+			svc := postsservice.New(mockPostsRepo, mocks.NewMockusersRepo(ctrl), mockReactionsRepo, mockTagsRepo, noMentions{}, inTx{}, mockAudit, nil, "", zap.NewNop())
+
+			existing := &domain.Post{ID: uuid.NewString(), UserID: uuid.NewString(), Title: "Weekend", Body: "At the #beach"}
+			ctx := domain.ContextWithIdentity(context.Background(), domain.Identity{UserID: existing.UserID})
+
+			mockPostsRepo.EXPECT().Get(ctx, existing.ID).Return(existing, nil)
+			mockTagsRepo.EXPECT().ForPosts(ctx, existing.ID).Return(map[string][]string{existing.ID: {"beach", "travel"}}, nil)
+			mockPostsRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+			mockTagsRepo.EXPECT().Set(ctx, existing.ID, tt.want).Return(nil)
+			mockAudit.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+			mockReactionsRepo.EXPECT().Counts(ctx, existing.UserID, existing.ID).Return(map[string][]domain.ReactionCount{}, nil)
+			mockTagsRepo.EXPECT().ForPosts(ctx, existing.ID).Return(map[string][]string{existing.ID: tt.want}, nil)
+
+			post, err := svc.Update(ctx, existing.ID, tt.update())
+			require.NoError(t, err)
+			require.Equal(t, tt.want, post.Tags)
+		})
+	}
+}
+
+func TestService_Create_Mentions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockMentionsRepo := mocks.NewMockmentionsRepo(ctrl)
+	mockAudit := mocks.NewMockauditRepo(ctrl)
+	mockMailer := domainmocks.NewMockMailer(ctrl)
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mocks.NewMockreactionsRepo(ctrl), noTags{}, mockMentionsRepo, inTx{}, mockAudit, mockMailer, "http://localhost:8080", zap.NewNop())
+
+	author := domain.User{ID: uuid.NewString(), Firstname: "Ada"}
+	ann := domain.User{ID: uuid.NewString(), Firstname: "Ann", Email: "ann@example.com"}
+	bob := domain.User{ID: uuid.NewString(), Firstname: "Bob", Email: "bob@example.com"}
+
+	ctx := context.Background()
+	post := &domain.Post{
+		ID:     uuid.NewString(),
+		UserID: author.ID,
+		Title:  "Lunch",
+		// Email addresses and unknown handles are not mentions of anyone
+		Body:      "Lunch with @Ann, @" + bob.ID + ", @nobody and me @ada, mail ada@example.com",
+		CreatedAt: time.Now(),
+	}
+
+	verifiedAt := time.Now()
+	mockUsersRepo.EXPECT().Get(ctx, author.ID).Return(&domain.User{ID: author.ID, EmailVerifiedAt: &verifiedAt}, nil)
+	mockUsersRepo.EXPECT().Mentioned(ctx, domain.Mentions{Handles: []string{"ann", "nobody", "ada"}, UserIDs: []string{bob.ID}}).
+		Return([]domain.User{author, ann, bob}, nil)
+	mockPostsRepo.EXPECT().Create(ctx, post).Return(nil)
+	mockMentionsRepo.EXPECT().Set(ctx, post.ID, []string{author.ID, ann.ID, bob.ID}).Return([]string{author.ID, ann.ID, bob.ID}, nil)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+	// The author is not notified of their own mention, and a failed email does not fail the post
+	mockMailer.EXPECT().Send(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, email domain.Email) error {
+		require.Equal(t, ann.Email, email.To)
+		require.Contains(t, email.Body, "http://localhost:8080/posts/"+post.ID)
+		return errors.New("mail server down")
+	})
+	mockMailer.EXPECT().Send(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, email domain.Email) error {
+		require.Equal(t, bob.Email, email.To)
+		return nil
+	})
+
+	require.NoError(t, svc.Create(ctx, post))
+	require.Equal(t, "Lunch with @Ann, @"+bob.ID+", @nobody and me @ada, mail ada@example.com", post.Body)
+}
+
+func TestService_Create_TooManyMentions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockMentionsRepo := mocks.NewMockmentionsRepo(ctrl)
+	mockAudit := mocks.NewMockauditRepo(ctrl)
+	mockMailer := domainmocks.NewMockMailer(ctrl)
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mocks.NewMockreactionsRepo(ctrl), noTags{}, mockMentionsRepo, inTx{}, mockAudit, mockMailer, "", zap.NewNop())
+
+	// Only the first mentions are looked up and emailed, however many the body has
+	var body strings.Builder
+	var handles []string
+	var users []domain.User
+	for i := range 20000 {
+		handle := fmt.Sprintf("user%05d", i)
+		fmt.Fprintf(&body, "@%s ", handle)
+		if i < domain.MaxPostMentions {
+			handles = append(handles, handle)
+			users = append(users, domain.User{ID: uuid.NewString(), Email: handle + "@example.com"})
+		}
+	}
+	ctx := context.Background()
+	post := &domain.Post{ID: uuid.NewString(), UserID: uuid.NewString(), Title: "Everyone", Body: body.String(), CreatedAt: time.Now()}
+
+	verifiedAt := time.Now()
+	mockUsersRepo.EXPECT().Get(ctx, post.UserID).Return(&domain.User{ID: post.UserID, EmailVerifiedAt: &verifiedAt}, nil)
+	mockUsersRepo.EXPECT().Mentioned(ctx, domain.Mentions{Handles: handles, UserIDs: []string{}}).Return(users, nil)
+	mockPostsRepo.EXPECT().Create(ctx, post).Return(nil)
+	mockMentionsRepo.EXPECT().Set(ctx, post.ID, gomock.Len(domain.MaxPostMentions)).DoAndReturn(func(ctx context.Context, postID string, userIDs []string) ([]string, error) {
+		return userIDs, nil
+	})
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+	mockMailer.EXPECT().Send(ctx, gomock.Any()).Return(nil).Times(domain.MaxPostMentions)
+
+	require.NoError(t, svc.Create(ctx, post))
+}
+
+func TestService_Update_Mentions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockReactionsRepo := mocks.NewMockreactionsRepo(ctrl)
+	mockMentionsRepo := mocks.NewMockmentionsRepo(ctrl)
+	mockAudit := mocks.NewMockauditRepo(ctrl)
+	mockMailer := domainmocks.NewMockMailer(ctrl)
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mockReactionsRepo, noTags{}, mockMentionsRepo, inTx{}, mockAudit, mockMailer, "", zap.NewNop())
+
+	ann := domain.User{ID: uuid.NewString(), Firstname: "Ann", Email: "ann@example.com"}
+	bob := domain.User{ID: uuid.NewString(), Firstname: "Bob", Email: "bob@example.com"}
+
+	existing := &domain.Post{ID: uuid.NewString(), UserID: uuid.NewString(), Title: "Lunch", Body: "Lunch with @ann"}
+	ctx := domain.ContextWithIdentity(context.Background(), domain.Identity{UserID: existing.UserID})
+
+	// Only the users the new body newly mentions are notified
+	body := "Lunch with @ann and @bob"
+	mockPostsRepo.EXPECT().Get(ctx, existing.ID).Return(existing, nil)
+	mockUsersRepo.EXPECT().Mentioned(ctx, domain.Mentions{Handles: []string{"ann", "bob"}, UserIDs: []string{}}).Return([]domain.User{ann, bob}, nil)
+	mockPostsRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+	mockMentionsRepo.EXPECT().Set(ctx, existing.ID, []string{ann.ID, bob.ID}).Return([]string{bob.ID}, nil)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+	mockReactionsRepo.EXPECT().Counts(ctx, existing.UserID, existing.ID).Return(map[string][]domain.ReactionCount{}, nil)
+	mockMailer.EXPECT().Send(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, email domain.Email) error {
+		require.Equal(t, bob.Email, email.To)
+		return nil
+	})
+
+	_, err := svc.Update(ctx, existing.ID, domain.PostUpdate{Body: &body})
+	require.NoError(t, err)
+
+	// Leaving the body as it is leaves the mentions as they are
+	title := "Brunch"
+	mockPostsRepo.EXPECT().Get(ctx, existing.ID).Return(&domain.Post{ID: existing.ID, UserID: existing.UserID, Body: body}, nil)
+	mockPostsRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+	mockReactionsRepo.EXPECT().Counts(ctx, existing.UserID, existing.ID).Return(map[string][]domain.ReactionCount{}, nil)
+
+	_, err = svc.Update(ctx, existing.ID, domain.PostUpdate{Title: &title})
+	require.NoError(t, err)
+}
+
+func TestService_List_Mentions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostsRepo := mocks.NewMockpostsRepo(ctrl)
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	svc := postsservice.New(mockPostsRepo, mockUsersRepo, mocks.NewMockreactionsRepo(ctrl), noTags{}, noMentions{}, inTx{}, mocks.NewMockauditRepo(ctrl), nil, "", zap.NewNop())
+
+	ctx := context.Background()
+	query := domain.PostQuery{MentionedUserID: uuid.NewString(), Page: domain.PageRequest{PageNumber: 1, PageSize: 10}}
+
+	mockUsersRepo.EXPECT().Validate(ctx, query.MentionedUserID).Return(domain.ErrUserNotFound)
+
+	_, err := svc.List(ctx, query)
+	require.ErrorIs(t, err, domain.ErrUserNotFound)
+}
@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/victor-nach/postr-backend/internal/services/reactionsservice (interfaces: postsRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mocks/mock_postsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/reactionsservice postsRepo
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/victor-nach/postr-backend/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockpostsRepo is a mock of postsRepo interface.
+type MockpostsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockpostsRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockpostsRepoMockRecorder is the mock recorder for MockpostsRepo.
+type MockpostsRepoMockRecorder struct {
+	mock *MockpostsRepo
+}
+
+// NewMockpostsRepo creates a new mock instance.
+func NewMockpostsRepo(ctrl *gomock.Controller) *MockpostsRepo {
+	mock := &MockpostsRepo{ctrl: ctrl}
+	mock.recorder = &MockpostsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockpostsRepo) EXPECT() *MockpostsRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockpostsRepo) Get(ctx context.Context, id string) (*domain.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*domain.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockpostsRepoMockRecorder) Get(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockpostsRepo)(nil).Get), ctx, id)
+}
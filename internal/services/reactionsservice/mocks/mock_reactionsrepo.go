@@ -0,0 +1,92 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/victor-nach/postr-backend/internal/services/reactionsservice (interfaces: reactionsRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mocks/mock_reactionsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/reactionsservice reactionsRepo
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/victor-nach/postr-backend/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockreactionsRepo is a mock of reactionsRepo interface.
+type MockreactionsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockreactionsRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockreactionsRepoMockRecorder is the mock recorder for MockreactionsRepo.
+type MockreactionsRepoMockRecorder struct {
+	mock *MockreactionsRepo
+}
+
+// NewMockreactionsRepo creates a new mock instance.
+func NewMockreactionsRepo(ctrl *gomock.Controller) *MockreactionsRepo {
+	mock := &MockreactionsRepo{ctrl: ctrl}
+	mock.recorder = &MockreactionsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockreactionsRepo) EXPECT() *MockreactionsRepoMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockreactionsRepo) Add(ctx context.Context, reaction *domain.Reaction) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, reaction)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockreactionsRepoMockRecorder) Add(ctx, reaction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockreactionsRepo)(nil).Add), ctx, reaction)
+}
+
+// Counts mocks base method.
+func (m *MockreactionsRepo) Counts(ctx context.Context, userID string, postIDs ...string) (map[string][]domain.ReactionCount, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, userID}
+	for _, a := range postIDs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Counts", varargs...)
+	ret0, _ := ret[0].(map[string][]domain.ReactionCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Counts indicates an expected call of Counts.
+func (mr *MockreactionsRepoMockRecorder) Counts(ctx, userID any, postIDs ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, userID}, postIDs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Counts", reflect.TypeOf((*MockreactionsRepo)(nil).Counts), varargs...)
+}
+
+// Remove mocks base method.
+func (m *MockreactionsRepo) Remove(ctx context.Context, reaction domain.Reaction) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", ctx, reaction)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockreactionsRepoMockRecorder) Remove(ctx, reaction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockreactionsRepo)(nil).Remove), ctx, reaction)
+}
@@ -0,0 +1,109 @@
+package reactionsservice
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type service struct {
+	reactionsRepo reactionsRepo
+	postsRepo     postsRepo
+	logger        *zap.Logger
+}
+
+// New creates the reactions service
+func New(reactionsRepo reactionsRepo, postsRepo postsRepo, logger *zap.Logger) domain.ReactionService {
+	logger = logger.With(zap.String("package", "reactionsservice"))
+
+	return &service{
+		reactionsRepo: reactionsRepo,
+		postsRepo:     postsRepo,
+		logger:        logger,
+	}
+}
+
+//go:generate mockgen -destination=./mocks/mock_reactionsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/reactionsservice reactionsRepo
+type reactionsRepo interface {
+	Add(ctx context.Context, reaction *domain.Reaction) (bool, error)
+	Remove(ctx context.Context, reaction domain.Reaction) (bool, error)
+	Counts(ctx context.Context, userID string, postIDs ...string) (map[string][]domain.ReactionCount, error)
+}
+
+//go:generate mockgen -destination=./mocks/mock_postsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/reactionsservice postsRepo
+type postsRepo interface {
+	Get(ctx context.Context, id string) (*domain.Post, error)
+}
+
+// React adds the reaction to its post, reacting again with the same kind leaves the counts as they are
+func (h *service) React(ctx context.Context, reaction domain.Reaction) ([]domain.ReactionCount, error) {
+	logr := h.logger.With(zap.String("method", "React"))
+
+	if err := h.checkPost(ctx, reaction.PostID); err != nil {
+		logr.Info("Unable to retrieve post", zap.String("post_id", reaction.PostID), zap.Error(err))
+		return nil, err
+	}
+
+	added, err := h.reactionsRepo.Add(ctx, &reaction)
+	if err != nil {
+		// The post was deleted since it was checked
+		if errors.Is(err, domain.ErrInvalidReference) {
+			logr.Info("Post not found", zap.String("post_id", reaction.PostID))
+			return nil, domain.ErrPostNotFound
+		}
+
+		logr.Error("Error adding reaction", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	logr.Info("Reacted to post successfully",
+		zap.String("post_id", reaction.PostID), zap.String("kind", string(reaction.Kind)), zap.Bool("added", added))
+	return h.counts(ctx, reaction)
+}
+
+// Unreact takes the reaction off its post, there being none leaves the counts as they are
+func (h *service) Unreact(ctx context.Context, reaction domain.Reaction) ([]domain.ReactionCount, error) {
+	logr := h.logger.With(zap.String("method", "Unreact"))
+
+	if err := h.checkPost(ctx, reaction.PostID); err != nil {
+		logr.Info("Unable to retrieve post", zap.String("post_id", reaction.PostID), zap.Error(err))
+		return nil, err
+	}
+
+	removed, err := h.reactionsRepo.Remove(ctx, reaction)
+	if err != nil {
+		logr.Error("Error removing reaction", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	logr.Info("Reaction removed successfully",
+		zap.String("post_id", reaction.PostID), zap.String("kind", string(reaction.Kind)), zap.Bool("removed", removed))
+	return h.counts(ctx, reaction)
+}
+
+// counts returns the reaction counts of the post of the reaction, as seen by the user who reacted
+func (h *service) counts(ctx context.Context, reaction domain.Reaction) ([]domain.ReactionCount, error) {
+	counts, err := h.reactionsRepo.Counts(ctx, reaction.UserID, reaction.PostID)
+	if err != nil {
+		h.logger.Error("Error counting reactions", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+	return counts[reaction.PostID], nil
+}
+
+// checkPost makes sure the post exists and is not deleted
+func (h *service) checkPost(ctx context.Context, postID string) error {
+	if _, err := h.postsRepo.Get(ctx, postID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrPostNotFound
+		}
+
+		h.logger.Error("Error retrieving post", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package reactionsservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+	"github.com/victor-nach/postr-backend/internal/services/reactionsservice"
+	"github.com/victor-nach/postr-backend/internal/services/reactionsservice/mocks"
+)
+
+func TestService_React(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reactions := mocks.NewMockreactionsRepo(ctrl)
+	posts := mocks.NewMockpostsRepo(ctrl)
+	svc := reactionsservice.New(reactions, posts, zap.NewNop())
+	ctx := context.Background()
+
+	reaction := domain.Reaction{PostID: "p1", UserID: "u1", Kind: domain.ReactionLike}
+	want := domain.NewReactionCounts(map[domain.ReactionKind]int{domain.ReactionLike: 1}, map[domain.ReactionKind]bool{domain.ReactionLike: true})
+
+	// Reacting twice with the same kind is no different from reacting once
+	for _, added := range []bool{true, false} {
+		posts.EXPECT().Get(ctx, "p1").Return(&domain.Post{ID: "p1"}, nil)
+		reactions.EXPECT().Add(ctx, &reaction).Return(added, nil)
+		reactions.EXPECT().Counts(ctx, "u1", "p1").Return(map[string][]domain.ReactionCount{"p1": want}, nil)
+
+		got, err := svc.React(ctx, reaction)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+}
+
+func TestService_React_Errors(t *testing.T) {
+	ctx := context.Background()
+	reaction := domain.Reaction{PostID: "p1", UserID: "u1", Kind: domain.ReactionLove}
+
+	tests := []struct {
+		name    string
+		setup   func(reactions *mocks.MockreactionsRepo, posts *mocks.MockpostsRepo)
+		wantErr error
+	}{
+		{
+			name: "post not found",
+			setup: func(reactions *mocks.MockreactionsRepo, posts *mocks.MockpostsRepo) {
+				posts.EXPECT().Get(ctx, "p1").Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: domain.ErrPostNotFound,
+		},
+		{
+			name: "post deleted in the meantime",
+			setup: func(reactions *mocks.MockreactionsRepo, posts *mocks.MockpostsRepo) {
+				posts.EXPECT().Get(ctx, "p1").Return(&domain.Post{ID: "p1"}, nil)
+				reactions.EXPECT().Add(ctx, gomock.Any()).Return(false, domain.ErrInvalidReference)
+			},
+			wantErr: domain.ErrPostNotFound,
+		},
+		{
+			name: "repository error",
+			setup: func(reactions *mocks.MockreactionsRepo, posts *mocks.MockpostsRepo) {
+				posts.EXPECT().Get(ctx, "p1").Return(&domain.Post{ID: "p1"}, nil)
+				reactions.EXPECT().Add(ctx, gomock.Any()).Return(true, nil)
+				reactions.EXPECT().Counts(ctx, "u1", "p1").Return(nil, errors.New("database is locked"))
+			},
+			wantErr: domain.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			reactions := mocks.NewMockreactionsRepo(ctrl)
+			posts := mocks.NewMockpostsRepo(ctrl)
+			svc := reactionsservice.New(reactions, posts, zap.NewNop())
+			tt.setup(reactions, posts)
+
+			_, err := svc.React(ctx, reaction)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestService_Unreact(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reactions := mocks.NewMockreactionsRepo(ctrl)
+	posts := mocks.NewMockpostsRepo(ctrl)
+	svc := reactionsservice.New(reactions, posts, zap.NewNop())
+	ctx := context.Background()
+
+	reaction := domain.Reaction{PostID: "p1", UserID: "u1", Kind: domain.ReactionLike}
+	want := domain.NewReactionCounts(nil, nil)
+
+	// Taking off a reaction that is not there changes nothing
+	for _, removed := range []bool{true, false} {
+		posts.EXPECT().Get(ctx, "p1").Return(&domain.Post{ID: "p1"}, nil)
+		reactions.EXPECT().Remove(ctx, reaction).Return(removed, nil)
+		reactions.EXPECT().Counts(ctx, "u1", "p1").Return(map[string][]domain.ReactionCount{"p1": want}, nil)
+
+		got, err := svc.Unreact(ctx, reaction)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	posts.EXPECT().Get(ctx, "p2").Return(nil, gorm.ErrRecordNotFound)
+	_, err := svc.Unreact(ctx, domain.Reaction{PostID: "p2", UserID: "u1", Kind: domain.ReactionLike})
+	require.Equal(t, domain.ErrPostNotFound, err)
+}
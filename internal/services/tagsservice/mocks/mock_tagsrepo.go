@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/victor-nach/postr-backend/internal/services/tagsservice (interfaces: tagsRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mocks/mock_tagsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/tagsservice tagsRepo
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/victor-nach/postr-backend/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MocktagsRepo is a mock of tagsRepo interface.
+type MocktagsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MocktagsRepoMockRecorder
+	isgomock struct{}
+}
+
+// MocktagsRepoMockRecorder is the mock recorder for MocktagsRepo.
+type MocktagsRepoMockRecorder struct {
+	mock *MocktagsRepo
+}
+
+// NewMocktagsRepo creates a new mock instance.
+func NewMocktagsRepo(ctrl *gomock.Controller) *MocktagsRepo {
+	mock := &MocktagsRepo{ctrl: ctrl}
+	mock.recorder = &MocktagsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktagsRepo) EXPECT() *MocktagsRepoMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MocktagsRepo) List(ctx context.Context, query domain.TagQuery) (domain.PaginatedTags, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query)
+	ret0, _ := ret[0].(domain.PaginatedTags)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MocktagsRepoMockRecorder) List(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MocktagsRepo)(nil).List), ctx, query)
+}
@@ -0,0 +1,42 @@
+package tagsservice
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+type service struct {
+	repo   tagsRepo
+	logger *zap.Logger
+}
+
+// New creates the tags service, which reads the tags in use. Posts are tagged by the posts service
+func New(repo tagsRepo, logger *zap.Logger) domain.TagService {
+	logger = logger.With(zap.String("package", "tagsservice"))
+
+	return &service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+//go:generate mockgen -destination=./mocks/mock_tagsrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/tagsservice tagsRepo
+type tagsRepo interface {
+	List(ctx context.Context, query domain.TagQuery) (domain.PaginatedTags, error)
+}
+
+func (h *service) List(ctx context.Context, query domain.TagQuery) (domain.PaginatedTags, error) {
+	logr := h.logger.With(zap.String("method", "List"))
+
+	tags, err := h.repo.List(ctx, query)
+	if err != nil {
+		logr.Error("Error listing tags", zap.Error(err))
+		return domain.PaginatedTags{}, domain.ErrInternalServer
+	}
+
+	logr.Info("Tags listed successfully", zap.String("prefix", query.Prefix), zap.Int("count", len(tags.Tags)))
+	return tags, nil
+}
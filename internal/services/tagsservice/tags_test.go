@@ -0,0 +1,36 @@
+package tagsservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+	"github.com/victor-nach/postr-backend/internal/services/tagsservice/mocks"
+)
+
+func TestService_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMocktagsRepo(ctrl)
+	svc := New(mockRepo, zap.NewNop())
+
+	ctx := context.Background()
+	query := domain.TagQuery{Prefix: "go", Page: domain.PageRequest{PageNumber: 1, PageSize: 10}}
+	tags := domain.PaginatedTags{
+		Pagination: domain.Pagination{CurrentPage: 1, TotalPages: 1, TotalSize: 1},
+		Tags:       []domain.Tag{{ID: "t1", Name: "golang", Posts: 3}},
+	}
+
+	mockRepo.EXPECT().List(ctx, query).Return(tags, nil)
+	listed, err := svc.List(ctx, query)
+	require.NoError(t, err)
+	require.Equal(t, tags, listed)
+
+	mockRepo.EXPECT().List(ctx, query).Return(domain.PaginatedTags{}, errors.New("no such table: tags"))
+	_, err = svc.List(ctx, query)
+	require.Equal(t, domain.ErrInternalServer, err)
+}
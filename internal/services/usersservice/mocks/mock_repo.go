@@ -70,6 +70,20 @@ func (mr *MockusersRepoMockRecorder) Create(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockusersRepo)(nil).Create), ctx, user)
 }
 
+// Delete mocks base method.
+func (m *MockusersRepo) Delete(ctx context.Context, id string, policy domain.UserDeletePolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockusersRepoMockRecorder) Delete(ctx, id, policy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockusersRepo)(nil).Delete), ctx, id, policy)
+}
+
 // Get mocks base method.
 func (m *MockusersRepo) Get(ctx context.Context, id string) (*domain.User, error) {
 	m.ctrl.T.Helper()
@@ -85,19 +99,106 @@ func (mr *MockusersRepoMockRecorder) Get(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockusersRepo)(nil).Get), ctx, id)
 }
 
+// GetWithDeleted mocks base method.
+func (m *MockusersRepo) GetWithDeleted(ctx context.Context, id string) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithDeleted", ctx, id)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithDeleted indicates an expected call of GetWithDeleted.
+func (mr *MockusersRepoMockRecorder) GetWithDeleted(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithDeleted", reflect.TypeOf((*MockusersRepo)(nil).GetWithDeleted), ctx, id)
+}
+
+// GrantRole mocks base method.
+func (m *MockusersRepo) GrantRole(ctx context.Context, userID string, role domain.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantRole", ctx, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantRole indicates an expected call of GrantRole.
+func (mr *MockusersRepoMockRecorder) GrantRole(ctx, userID, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantRole", reflect.TypeOf((*MockusersRepo)(nil).GrantRole), ctx, userID, role)
+}
+
 // List mocks base method.
-func (m *MockusersRepo) List(ctx context.Context, pageNumber, pageSize int) (domain.PaginatedUsers, error) {
+func (m *MockusersRepo) List(ctx context.Context, query domain.UserQuery) (domain.PaginatedUsers, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx, pageNumber, pageSize)
+	ret := m.ctrl.Call(m, "List", ctx, query)
 	ret0, _ := ret[0].(domain.PaginatedUsers)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockusersRepoMockRecorder) List(ctx, pageNumber, pageSize any) *gomock.Call {
+func (mr *MockusersRepoMockRecorder) List(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockusersRepo)(nil).List), ctx, query)
+}
+
+// ListRoles mocks base method.
+func (m *MockusersRepo) ListRoles(ctx context.Context, userID string) ([]domain.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRoles", ctx, userID)
+	ret0, _ := ret[0].([]domain.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRoles indicates an expected call of ListRoles.
+func (mr *MockusersRepoMockRecorder) ListRoles(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoles", reflect.TypeOf((*MockusersRepo)(nil).ListRoles), ctx, userID)
+}
+
+// Restore mocks base method.
+func (m *MockusersRepo) Restore(ctx context.Context, id string) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockusersRepoMockRecorder) Restore(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockusersRepo)(nil).Restore), ctx, id)
+}
+
+// RevokeRole mocks base method.
+func (m *MockusersRepo) RevokeRole(ctx context.Context, userID string, role domain.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRole", ctx, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRole indicates an expected call of RevokeRole.
+func (mr *MockusersRepoMockRecorder) RevokeRole(ctx, userID, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRole", reflect.TypeOf((*MockusersRepo)(nil).RevokeRole), ctx, userID, role)
+}
+
+// Update mocks base method.
+func (m *MockusersRepo) Update(ctx context.Context, user *domain.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockusersRepoMockRecorder) Update(ctx, user any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockusersRepo)(nil).List), ctx, pageNumber, pageSize)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockusersRepo)(nil).Update), ctx, user)
 }
 
 // Validate mocks base method.
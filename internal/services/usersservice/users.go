@@ -3,22 +3,32 @@ package usersservice
 import (
 	"context"
 	"errors"
-	
+	"slices"
+
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
 	"github.com/victor-nach/postr-backend/internal/domain"
 )
 
 type service struct {
-	repo   usersRepo
-	logger *zap.Logger
+	repo         usersRepo
+	tx           transactor
+	audit        auditRepo
+	verification domain.VerificationService
+	logger       *zap.Logger
 }
 
-func New(repo usersRepo, logger *zap.Logger) domain.UserService {
+// New creates the users service. Every change is recorded in the audit log in the transaction of the change,
+// verification emails new users a link confirming their email
+func New(repo usersRepo, tx transactor, audit auditRepo, verification domain.VerificationService, logger *zap.Logger) domain.UserService {
 	return &service{
-		repo:   repo,
-		logger: logger,
+		repo:         repo,
+		tx:           tx,
+		audit:        audit,
+		verification: verification,
+		logger:       logger,
 	}
 }
 
@@ -26,21 +36,60 @@ func New(repo usersRepo, logger *zap.Logger) domain.UserService {
 type usersRepo interface {
 	Create(ctx context.Context, user *domain.User) error
 	Get(ctx context.Context, id string) (*domain.User, error)
-	List(ctx context.Context, pageNumber int, pageSize int) (domain.PaginatedUsers, error)
+	GetWithDeleted(ctx context.Context, id string) (*domain.User, error)
+	Update(ctx context.Context, user *domain.User) error
+	List(ctx context.Context, query domain.UserQuery) (domain.PaginatedUsers, error)
 	Count(ctx context.Context, ) (int, error)
 	Validate(ctx context.Context, userID string) error
+	Delete(ctx context.Context, id string, policy domain.UserDeletePolicy) error
+	Restore(ctx context.Context, id string) (*domain.User, error)
+	ListRoles(ctx context.Context, userID string) ([]domain.Role, error)
+	GrantRole(ctx context.Context, userID string, role domain.Role) error
+	RevokeRole(ctx context.Context, userID string, role domain.Role) error
+}
+
+//go:generate mockgen -destination=./mocks/mock_auditrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/usersservice auditRepo
+type auditRepo interface {
+	Create(ctx context.Context, event *domain.AuditEvent) error
+}
+
+// transactor runs fn in a database transaction, the repositories called with the context handed to fn take part in it
+type transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
-func (h *service) Create(ctx context.Context, user *domain.User) error {
+func (h *service) Create(ctx context.Context, user *domain.User, password string) error {
 	logr := h.logger.With(zap.String("method", "Create"))
 
-	if err := h.repo.Create(ctx, user); err != nil {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		logr.Error("Error hashing password", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+	user.PasswordHash = string(hash)
+
+	err = h.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := h.repo.Create(ctx, user); err != nil {
+			return err
+		}
+		return h.record(ctx, domain.AuditUserCreate, user.ID, nil, user)
+	})
+	if err != nil {
+		// Constraint violations, such as a taken email, are reported back to the caller as is
+		var derr domain.DomainError
+		if errors.As(err, &derr) {
+			logr.Info("User violates a constraint", zap.Error(err))
+			return derr
+		}
+
 		logr.Error("Error creating user", zap.Error(err))
-		return err
+		return domain.ErrInternalServer
 	}
 
 	logr.Info("User created successfully", zap.Any("user", user))
 
+	h.sendVerification(ctx, logr, user.ID)
+
 	return nil
 }
 
@@ -63,11 +112,66 @@ func (h *service) Get(ctx context.Context, id string) (*domain.User, error) {
 	return user, nil
 }
 
-func (h *service) List(ctx context.Context, pageNumber int, pageSize int) (domain.PaginatedUsers, error) {
+func (h *service) Update(ctx context.Context, id string, update domain.UserUpdate) (*domain.User, error) {
+	logr := h.logger.With(zap.String("method", "Update"))
+
+	user, err := h.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("User not found", zap.String("id", id))
+			return nil, domain.ErrUserNotFound
+		}
+
+		logr.Error("Error retrieving user", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	before := *user
+	emailChanged := update.Email != nil && *update.Email != user.Email
+	update.Apply(user)
+
+	err = h.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := h.repo.Update(ctx, user); err != nil {
+			return err
+		}
+		return h.record(ctx, domain.AuditUserUpdate, user.ID, before, user)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("User not found", zap.String("id", id))
+			return nil, domain.ErrUserNotFound
+		}
+
+		var derr domain.DomainError
+		if errors.As(err, &derr) {
+			logr.Info("User violates a constraint", zap.Error(err))
+			return nil, derr
+		}
+
+		logr.Error("Error updating user", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	logr.Info("User updated successfully", zap.Any("user", user))
+
+	if emailChanged {
+		h.sendVerification(ctx, logr, user.ID)
+	}
+
+	return user, nil
+}
+
+func (h *service) List(ctx context.Context, query domain.UserQuery) (domain.PaginatedUsers, error) {
 	logr := h.logger.With(zap.String("method", "List"))
 
-	paginatedUsers, err := h.repo.List(ctx, pageNumber, pageSize)
+	paginatedUsers, err := h.repo.List(ctx, query)
 	if err != nil {
+		// An unusable cursor is reported back to the caller as is
+		if errors.Is(err, domain.ErrInvalidInput) {
+			logr.Info("Invalid user query", zap.Error(err))
+			return domain.PaginatedUsers{}, err
+		}
+
 		logr.Error("Error listing users", zap.Error(err))
 		return domain.PaginatedUsers{}, domain.ErrInternalServer
 	}
@@ -87,4 +191,214 @@ func (h *service) Count(ctx context.Context) (int, error) {
 
 	logr.Info("Users count retrieved successfully", zap.Int("count", count))
 	return count, nil
-}
\ No newline at end of file
+}
+
+func (h *service) Delete(ctx context.Context, id string, policy domain.UserDeletePolicy) error {
+	logr := h.logger.With(zap.String("method", "Delete"))
+
+	// The tombstone user holds reassigned posts and is never deleted itself
+	if id == domain.DeletedUserID {
+		logr.Info("Refusing to delete the tombstone user")
+		return domain.ErrUserNotFound
+	}
+
+	err := h.tx.Transaction(ctx, func(ctx context.Context) error {
+		user, err := h.repo.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := h.repo.Delete(ctx, id, policy); err != nil {
+			return err
+		}
+		return h.record(ctx, domain.AuditUserDelete, id, user, nil)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("User not found", zap.String("id", id))
+			return domain.ErrUserNotFound
+		}
+
+		if errors.Is(err, domain.ErrUserHasPosts) {
+			logr.Info("User still has posts", zap.String("id", id))
+			return domain.ErrUserHasPosts
+		}
+
+		logr.Error("Error deleting user", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	logr.Info("User deleted successfully", zap.String("id", id), zap.String("policy", string(policy)))
+	return nil
+}
+
+// Restore undoes the soft delete of a user, bringing back the posts that were deleted with them
+func (h *service) Restore(ctx context.Context, id string) (*domain.User, error) {
+	logr := h.logger.With(zap.String("method", "Restore"))
+
+	if id == domain.DeletedUserID {
+		logr.Info("Refusing to restore the tombstone user")
+		return nil, domain.ErrUserNotFound
+	}
+
+	var user *domain.User
+	err := h.tx.Transaction(ctx, func(ctx context.Context) error {
+		deleted, err := h.repo.GetWithDeleted(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		user, err = h.repo.Restore(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Restoring a user that is not deleted changes nothing, so there is nothing to record
+		if !deleted.DeletedAt.Valid {
+			return nil
+		}
+		return h.record(ctx, domain.AuditUserRestore, id, deleted, user)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("User not found", zap.String("id", id))
+			return nil, domain.ErrUserNotFound
+		}
+
+		logr.Error("Error restoring user", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	logr.Info("User restored successfully", zap.String("id", id))
+	return user, nil
+}
+
+func (h *service) ListRoles(ctx context.Context, id string) ([]domain.Role, error) {
+	logr := h.logger.With(zap.String("method", "ListRoles"))
+
+	if err := h.repo.Validate(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			logr.Info("User not found", zap.String("id", id))
+			return nil, domain.ErrUserNotFound
+		}
+
+		logr.Error("Error validating user", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	return h.listRoles(ctx, logr, id)
+}
+
+func (h *service) GrantRole(ctx context.Context, id string, role domain.Role) ([]domain.Role, error) {
+	logr := h.logger.With(zap.String("method", "GrantRole"))
+
+	// The tombstone user cannot sign in, roles would be of no use to it
+	if id == domain.DeletedUserID {
+		logr.Info("Refusing to grant a role to the tombstone user")
+		return nil, domain.ErrUserNotFound
+	}
+
+	var roles []domain.Role
+	err := h.tx.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		roles, err = h.changeRoles(ctx, domain.AuditUserRoleGrant, id, func() error {
+			return h.repo.GrantRole(ctx, id, role)
+		})
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("User not found", zap.String("id", id))
+			return nil, domain.ErrUserNotFound
+		}
+
+		logr.Error("Error granting role", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	logr.Info("Role granted successfully", zap.String("id", id), zap.String("role", string(role)))
+	return roles, nil
+}
+
+func (h *service) RevokeRole(ctx context.Context, id string, role domain.Role) ([]domain.Role, error) {
+	logr := h.logger.With(zap.String("method", "RevokeRole"))
+
+	var roles []domain.Role
+	err := h.tx.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		roles, err = h.changeRoles(ctx, domain.AuditUserRoleRevoke, id, func() error {
+			return h.repo.RevokeRole(ctx, id, role)
+		})
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("User not found", zap.String("id", id))
+			return nil, domain.ErrUserNotFound
+		}
+
+		if errors.Is(err, domain.ErrLastAdmin) {
+			logr.Info("Refusing to revoke the role of the last admin", zap.String("id", id))
+			return nil, domain.ErrLastAdmin
+		}
+
+		logr.Error("Error revoking role", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	logr.Info("Role revoked successfully", zap.String("id", id), zap.String("role", string(role)))
+	return roles, nil
+}
+
+func (h *service) listRoles(ctx context.Context, logr *zap.Logger, id string) ([]domain.Role, error) {
+	roles, err := h.repo.ListRoles(ctx, id)
+	if err != nil {
+		logr.Error("Error listing roles", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+	return roles, nil
+}
+
+// changeRoles makes the change to the user's roles, records the roles they had before and after it and returns
+// the roles they have now
+func (h *service) changeRoles(ctx context.Context, action domain.AuditAction, id string, change func() error) ([]domain.Role, error) {
+	before, err := h.repo.ListRoles(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(); err != nil {
+		return nil, err
+	}
+	after, err := h.repo.ListRoles(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Granting a role the user has, or revoking one they do not, changes nothing
+	if slices.Equal(before, after) {
+		return after, nil
+	}
+	return after, h.record(ctx, action, id, rolesSnapshot{Roles: before}, rolesSnapshot{Roles: after})
+}
+
+// rolesSnapshot is the state of a user's roles in the audit log
+type rolesSnapshot struct {
+	Roles []domain.Role `json:"roles"`
+}
+
+// record appends the change to the audit log, it must be called in the transaction of the change so the event is
+// only kept if the change is
+func (h *service) record(ctx context.Context, action domain.AuditAction, targetID string, before any, after any) error {
+	event, err := domain.NewAuditEvent(ctx, action, targetID, before, after)
+	if err != nil {
+		return err
+	}
+	return h.audit.Create(ctx, &event)
+}
+
+// sendVerification emails the user a verification link. The user is saved by then, so failing to send it is
+// only logged, the user can ask for another one
+func (h *service) sendVerification(ctx context.Context, logr *zap.Logger, userID string) {
+	if err := h.verification.SendVerification(ctx, userID); err != nil {
+		logr.Warn("Unable to send verification email", zap.String("user_id", userID), zap.Error(err))
+	}
+}
@@ -2,29 +2,42 @@ package usersservice
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/google/uuid"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/victor-nach/postr-backend/internal/domain"
+	domainmocks "github.com/victor-nach/postr-backend/internal/domain/mocks"
 	"github.com/victor-nach/postr-backend/internal/services/usersservice/mocks"
 )
 
+// inTx runs the function of a transaction right away, there is no database behind these tests
+type inTx struct{}
+
+func (inTx) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func TestService_Create(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockusersRepo(ctrl)
+	mockAudit := mocks.NewMockauditRepo(ctrl)
+	mockVerification := domainmocks.NewMockVerificationService(ctrl)
 	logger := zap.NewNop()
-	svc := New(mockRepo, logger)
+	svc := New(mockRepo, inTx{}, mockAudit, mockVerification, logger)
 
-	ctx := context.Background()
+	ctx := domain.ContextWithRequest(context.Background(), domain.RequestInfo{ID: "req-1", IPAddress: "203.0.113.7"})
 	user := &domain.User{
 		ID: uuid.NewString(),
 		Firstname: "Alice",
@@ -42,20 +55,75 @@ func TestService_Create(t *testing.T) {
 			require.Equal(t, "Alice", u.Firstname)
 			require.Equal(t, "Smith", u.Lastname)
 			require.Equal(t, "alice@example.com", u.Email)
+			require.NoError(t, bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte("correct horse")), "only a hash of the password should be stored")
+			require.False(t, u.EmailVerified(), "new users should start unverified")
 			return nil
 		})
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, event *domain.AuditEvent) error {
+		require.Equal(t, domain.AuditUserCreate, event.Action)
+		require.Equal(t, "user", event.TargetType)
+		require.Equal(t, user.ID, event.TargetID)
+		require.Empty(t, event.ActorID, "signing up is anonymous")
+		require.Equal(t, "req-1", event.RequestID)
+		require.Equal(t, "203.0.113.7", event.IPAddress)
+		require.Nil(t, event.Before)
+		require.Contains(t, string(event.After), `"email":"alice@example.com"`)
+		require.NotContains(t, string(event.After), "correct horse")
+		return nil
+	})
+	mockVerification.EXPECT().SendVerification(ctx, user.ID).Return(nil)
+
+	err := svc.Create(ctx, user, "correct horse")
+	require.NoError(t, err)
+
+	// The user is created even if the verification email cannot be sent, they can ask for another one
+	mockRepo.EXPECT().Create(ctx, user).Return(nil)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+	mockVerification.EXPECT().SendVerification(ctx, user.ID).Return(domain.ErrInternalServer)
 
-	err := svc.Create(ctx, user)
+	err = svc.Create(ctx, user, "correct horse")
 	require.NoError(t, err)
 }
 
+func TestService_Create_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	mockAudit := mocks.NewMockauditRepo(ctrl)
+	svc := New(mockRepo, inTx{}, mockAudit, domainmocks.NewMockVerificationService(ctrl), zap.NewNop())
+
+	ctx := context.Background()
+	user := &domain.User{ID: uuid.NewString(), Email: "taken@example.com"}
+
+	// Constraint violations keep their field errors
+	taken := domain.ErrEmailAlreadyRegistered.WithFieldErrors(validation.Errors{"email": errors.New("is already taken")})
+	mockRepo.EXPECT().Create(ctx, user).Return(taken)
+
+	err := svc.Create(ctx, user, "correct horse")
+	require.Equal(t, taken, err)
+
+	// Anything else is not leaked to the caller
+	mockRepo.EXPECT().Create(ctx, user).Return(errors.New("disk I/O error"))
+
+	err = svc.Create(ctx, user, "correct horse")
+	require.Equal(t, domain.ErrInternalServer, err)
+
+	// A change that cannot be audited is not made
+	mockRepo.EXPECT().Create(ctx, user).Return(nil)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).Return(errors.New("disk I/O error"))
+
+	err = svc.Create(ctx, user, "correct horse")
+	require.Equal(t, domain.ErrInternalServer, err)
+}
+
 func TestService_Get_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockusersRepo(ctrl)
 	logger := zap.NewNop()
-	svc := New(mockRepo, logger)
+	svc := New(mockRepo, inTx{}, mocks.NewMockauditRepo(ctrl), domainmocks.NewMockVerificationService(ctrl), logger)
 
 	ctx := context.Background()
 	userID := uuid.NewString()
@@ -80,7 +148,7 @@ func TestService_Get_NotFound(t *testing.T) {
 
 	mockRepo := mocks.NewMockusersRepo(ctrl)
 	logger := zap.NewNop()
-	svc := New(mockRepo, logger)
+	svc := New(mockRepo, inTx{}, mocks.NewMockauditRepo(ctrl), domainmocks.NewMockVerificationService(ctrl), logger)
 
 	ctx := context.Background()
 	userID := uuid.NewString()
@@ -93,16 +161,134 @@ func TestService_Get_NotFound(t *testing.T) {
 	require.Equal(t, domain.ErrUserNotFound, err)
 }
 
+func TestService_Update_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	mockAudit := mocks.NewMockauditRepo(ctrl)
+	logger := zap.NewNop()
+	svc := New(mockRepo, inTx{}, mockAudit, domainmocks.NewMockVerificationService(ctrl), logger)
+
+	ctx := domain.ContextWithIdentity(context.Background(), domain.Identity{UserID: "admin-1", APIKeyID: "key-1"})
+	userID := uuid.NewString()
+	existing := &domain.User{
+		ID:        userID,
+		Firstname: "Dana",
+		Lastname:  "Scully",
+		Email:     "dana@example.com",
+		City:      "Washington",
+		CreatedAt: time.Now(),
+	}
+	city := "Baltimore"
+
+	mockRepo.EXPECT().Get(ctx, userID).Return(existing, nil)
+	mockRepo.EXPECT().Update(ctx, gomock.AssignableToTypeOf(&domain.User{})).
+		DoAndReturn(func(ctx context.Context, u *domain.User) error {
+			require.Equal(t, "Baltimore", u.City)
+			require.Equal(t, "Dana", u.Firstname, "fields not in the update should be unchanged")
+			require.Equal(t, "dana@example.com", u.Email)
+			return nil
+		})
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, event *domain.AuditEvent) error {
+		require.Equal(t, domain.AuditUserUpdate, event.Action)
+		require.Equal(t, "admin-1", event.ActorID)
+		require.Equal(t, "key-1", event.ActorAPIKeyID)
+		require.Contains(t, string(event.Before), `"city":"Washington"`)
+		require.Contains(t, string(event.After), `"city":"Baltimore"`)
+		return nil
+	})
+
+	user, err := svc.Update(ctx, userID, domain.UserUpdate{City: &city})
+	require.NoError(t, err)
+	require.Equal(t, "Baltimore", user.City)
+}
+
+func TestService_Update_Email(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	mockAudit := mocks.NewMockauditRepo(ctrl)
+	mockVerification := domainmocks.NewMockVerificationService(ctrl)
+	svc := New(mockRepo, inTx{}, mockAudit, mockVerification, zap.NewNop())
+
+	ctx := context.Background()
+	verifiedAt := time.Now()
+	user := &domain.User{ID: uuid.NewString(), Email: "dana@example.com", EmailVerifiedAt: &verifiedAt}
+
+	// Keeping the email keeps it verified
+	same := "dana@example.com"
+	mockRepo.EXPECT().Get(ctx, user.ID).Return(user, nil)
+	mockRepo.EXPECT().Update(ctx, user).Return(nil)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+	updated, err := svc.Update(ctx, user.ID, domain.UserUpdate{Email: &same})
+	require.NoError(t, err)
+	require.True(t, updated.EmailVerified())
+
+	// A new email has to be verified again
+	email := "dana@example.org"
+	mockRepo.EXPECT().Get(ctx, user.ID).Return(user, nil)
+	mockRepo.EXPECT().Update(ctx, user).Return(nil)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+	mockVerification.EXPECT().SendVerification(ctx, user.ID).Return(nil)
+
+	updated, err = svc.Update(ctx, user.ID, domain.UserUpdate{Email: &email})
+	require.NoError(t, err)
+	require.Equal(t, "dana@example.org", updated.Email)
+	require.False(t, updated.EmailVerified())
+}
+
+func TestService_Update_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	logger := zap.NewNop()
+	svc := New(mockRepo, inTx{}, mocks.NewMockauditRepo(ctrl), domainmocks.NewMockVerificationService(ctrl), logger)
+
+	ctx := context.Background()
+	userID := uuid.NewString()
+	city := "Baltimore"
+
+	mockRepo.EXPECT().Get(ctx, userID).Return(nil, gorm.ErrRecordNotFound)
+
+	user, err := svc.Update(ctx, userID, domain.UserUpdate{City: &city})
+	require.Error(t, err)
+	require.Nil(t, user)
+	require.Equal(t, domain.ErrUserNotFound, err)
+}
+
+func TestService_Update_EmailTaken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	svc := New(mockRepo, inTx{}, mocks.NewMockauditRepo(ctrl), domainmocks.NewMockVerificationService(ctrl), zap.NewNop())
+
+	ctx := context.Background()
+	user := &domain.User{ID: uuid.NewString(), Email: "free@example.com"}
+	email := "taken@example.com"
+
+	mockRepo.EXPECT().Get(ctx, user.ID).Return(user, nil)
+	mockRepo.EXPECT().Update(ctx, user).Return(domain.ErrEmailAlreadyRegistered)
+
+	updated, err := svc.Update(ctx, user.ID, domain.UserUpdate{Email: &email})
+	require.Nil(t, updated)
+	require.Equal(t, domain.ErrEmailAlreadyRegistered, err)
+}
+
 func TestService_List(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockusersRepo(ctrl)
 	logger := zap.NewNop()
-	svc := New(mockRepo, logger)
+	svc := New(mockRepo, inTx{}, mocks.NewMockauditRepo(ctrl), domainmocks.NewMockVerificationService(ctrl), logger)
 
 	ctx := context.Background()
-	pageNumber, pageSize := 1, 10
+	query := domain.UserQuery{City: "Chicago", Page: domain.PageRequest{PageNumber: 1, PageSize: 10}}
 
 	expectedPaginated := domain.PaginatedUsers{
 		Pagination: domain.Pagination{
@@ -121,9 +307,9 @@ func TestService_List(t *testing.T) {
 		},
 	}
 
-	mockRepo.EXPECT().List(ctx, pageNumber, pageSize).Return(expectedPaginated, nil)
+	mockRepo.EXPECT().List(ctx, query).Return(expectedPaginated, nil)
 
-	result, err := svc.List(ctx, pageNumber, pageSize)
+	result, err := svc.List(ctx, query)
 	require.NoError(t, err)
 	require.Equal(t, expectedPaginated, result)
 }
@@ -134,7 +320,7 @@ func TestService_Count(t *testing.T) {
 
 	mockRepo := mocks.NewMockusersRepo(ctrl)
 	logger := zap.NewNop()
-	svc := New(mockRepo, logger)
+	svc := New(mockRepo, inTx{}, mocks.NewMockauditRepo(ctrl), domainmocks.NewMockVerificationService(ctrl), logger)
 
 	ctx := context.Background()
 	expectedCount := 42
@@ -145,3 +331,181 @@ func TestService_Count(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expectedCount, count)
 }
+
+func TestService_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	logger := zap.NewNop()
+	mockAudit := mocks.NewMockauditRepo(ctrl)
+	svc := New(mockRepo, inTx{}, mockAudit, domainmocks.NewMockVerificationService(ctrl), logger)
+
+	ctx := context.Background()
+	userID := uuid.NewString()
+
+	mockRepo.EXPECT().Get(ctx, userID).Return(&domain.User{ID: userID, Firstname: "Fox"}, nil)
+	mockRepo.EXPECT().Delete(ctx, userID, domain.UserDeleteCascade).Return(nil)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, event *domain.AuditEvent) error {
+		require.Equal(t, domain.AuditUserDelete, event.Action)
+		require.Equal(t, userID, event.TargetID)
+		require.Contains(t, string(event.Before), `"firstname":"Fox"`)
+		require.Nil(t, event.After)
+		return nil
+	})
+
+	err := svc.Delete(ctx, userID, domain.UserDeleteCascade)
+	require.NoError(t, err)
+}
+
+func TestService_Delete_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	logger := zap.NewNop()
+	svc := New(mockRepo, inTx{}, mocks.NewMockauditRepo(ctrl), domainmocks.NewMockVerificationService(ctrl), logger)
+
+	ctx := context.Background()
+	userID := uuid.NewString()
+
+	mockRepo.EXPECT().Get(ctx, userID).Return(&domain.User{ID: userID}, nil)
+	mockRepo.EXPECT().Delete(ctx, userID, domain.UserDeleteRestrict).Return(domain.ErrUserHasPosts)
+	err := svc.Delete(ctx, userID, domain.UserDeleteRestrict)
+	require.Equal(t, domain.ErrUserHasPosts, err)
+
+	mockRepo.EXPECT().Get(ctx, userID).Return(nil, gorm.ErrRecordNotFound)
+	err = svc.Delete(ctx, userID, domain.UserDeleteRestrict)
+	require.Equal(t, domain.ErrUserNotFound, err)
+
+	// The tombstone user never reaches the repository
+	err = svc.Delete(ctx, domain.DeletedUserID, domain.UserDeleteCascade)
+	require.Equal(t, domain.ErrUserNotFound, err)
+}
+
+func TestService_Restore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	logger := zap.NewNop()
+	mockAudit := mocks.NewMockauditRepo(ctrl)
+	svc := New(mockRepo, inTx{}, mockAudit, domainmocks.NewMockVerificationService(ctrl), logger)
+
+	ctx := context.Background()
+	user := &domain.User{ID: uuid.NewString(), Firstname: "Lazarus"}
+	deleted := &domain.User{ID: user.ID, Firstname: "Lazarus", DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}
+
+	mockRepo.EXPECT().GetWithDeleted(ctx, user.ID).Return(deleted, nil)
+	mockRepo.EXPECT().Restore(ctx, user.ID).Return(user, nil)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, event *domain.AuditEvent) error {
+		require.Equal(t, domain.AuditUserRestore, event.Action)
+		require.NotContains(t, string(event.Before), `"deletedAt":null`)
+		require.Contains(t, string(event.After), `"deletedAt":null`)
+		return nil
+	})
+	restored, err := svc.Restore(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, user, restored)
+
+	// Restoring a user that is not deleted changes nothing, so nothing is recorded
+	mockRepo.EXPECT().GetWithDeleted(ctx, user.ID).Return(user, nil)
+	mockRepo.EXPECT().Restore(ctx, user.ID).Return(user, nil)
+	_, err = svc.Restore(ctx, user.ID)
+	require.NoError(t, err)
+
+	mockRepo.EXPECT().GetWithDeleted(ctx, user.ID).Return(nil, gorm.ErrRecordNotFound)
+	_, err = svc.Restore(ctx, user.ID)
+	require.Equal(t, domain.ErrUserNotFound, err)
+
+	// The tombstone user never reaches the repository
+	_, err = svc.Restore(ctx, domain.DeletedUserID)
+	require.Equal(t, domain.ErrUserNotFound, err)
+}
+
+func TestService_Roles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	mockAudit := mocks.NewMockauditRepo(ctrl)
+	svc := New(mockRepo, inTx{}, mockAudit, domainmocks.NewMockVerificationService(ctrl), zap.NewNop())
+
+	ctx := context.Background()
+	id := uuid.NewString()
+
+	gomock.InOrder(
+		mockRepo.EXPECT().ListRoles(ctx, id).Return([]domain.Role{domain.RoleMember}, nil),
+		mockRepo.EXPECT().GrantRole(ctx, id, domain.RoleModerator).Return(nil),
+		mockRepo.EXPECT().ListRoles(ctx, id).Return([]domain.Role{domain.RoleMember, domain.RoleModerator}, nil),
+	)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, event *domain.AuditEvent) error {
+		require.Equal(t, domain.AuditUserRoleGrant, event.Action)
+		require.JSONEq(t, `{"roles":["member"]}`, string(event.Before))
+		require.JSONEq(t, `{"roles":["member","moderator"]}`, string(event.After))
+		return nil
+	})
+	roles, err := svc.GrantRole(ctx, id, domain.RoleModerator)
+	require.NoError(t, err)
+	require.Equal(t, []domain.Role{domain.RoleMember, domain.RoleModerator}, roles)
+
+	// Granting a role the user already has changes nothing, so nothing is recorded
+	gomock.InOrder(
+		mockRepo.EXPECT().ListRoles(ctx, id).Return([]domain.Role{domain.RoleMember, domain.RoleModerator}, nil),
+		mockRepo.EXPECT().GrantRole(ctx, id, domain.RoleModerator).Return(nil),
+		mockRepo.EXPECT().ListRoles(ctx, id).Return([]domain.Role{domain.RoleMember, domain.RoleModerator}, nil),
+	)
+	_, err = svc.GrantRole(ctx, id, domain.RoleModerator)
+	require.NoError(t, err)
+
+	gomock.InOrder(
+		mockRepo.EXPECT().ListRoles(ctx, id).Return([]domain.Role{domain.RoleMember, domain.RoleModerator}, nil),
+		mockRepo.EXPECT().RevokeRole(ctx, id, domain.RoleModerator).Return(nil),
+		mockRepo.EXPECT().ListRoles(ctx, id).Return([]domain.Role{domain.RoleMember}, nil),
+	)
+	mockAudit.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, event *domain.AuditEvent) error {
+		require.Equal(t, domain.AuditUserRoleRevoke, event.Action)
+		return nil
+	})
+	roles, err = svc.RevokeRole(ctx, id, domain.RoleModerator)
+	require.NoError(t, err)
+	require.Equal(t, []domain.Role{domain.RoleMember}, roles)
+
+	mockRepo.EXPECT().Validate(ctx, id).Return(nil)
+	mockRepo.EXPECT().ListRoles(ctx, id).Return([]domain.Role{domain.RoleMember}, nil)
+	roles, err = svc.ListRoles(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, []domain.Role{domain.RoleMember}, roles)
+}
+
+func TestService_Roles_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockusersRepo(ctrl)
+	svc := New(mockRepo, inTx{}, mocks.NewMockauditRepo(ctrl), domainmocks.NewMockVerificationService(ctrl), zap.NewNop())
+
+	ctx := context.Background()
+	id := uuid.NewString()
+
+	mockRepo.EXPECT().Validate(ctx, id).Return(domain.ErrUserNotFound)
+	_, err := svc.ListRoles(ctx, id)
+	require.Equal(t, domain.ErrUserNotFound, err)
+
+	mockRepo.EXPECT().ListRoles(ctx, id).Return([]domain.Role{}, nil).Times(3)
+	mockRepo.EXPECT().GrantRole(ctx, id, domain.RoleAdmin).Return(gorm.ErrRecordNotFound)
+	_, err = svc.GrantRole(ctx, id, domain.RoleAdmin)
+	require.Equal(t, domain.ErrUserNotFound, err)
+
+	// The tombstone user is never granted a role
+	_, err = svc.GrantRole(ctx, domain.DeletedUserID, domain.RoleAdmin)
+	require.Equal(t, domain.ErrUserNotFound, err)
+
+	mockRepo.EXPECT().RevokeRole(ctx, id, domain.RoleAdmin).Return(domain.ErrLastAdmin)
+	_, err = svc.RevokeRole(ctx, id, domain.RoleAdmin)
+	require.Equal(t, domain.ErrLastAdmin, err)
+
+	mockRepo.EXPECT().RevokeRole(ctx, id, domain.RoleMember).Return(errors.New("database is locked"))
+	_, err = svc.RevokeRole(ctx, id, domain.RoleMember)
+	require.Equal(t, domain.ErrInternalServer, err)
+}
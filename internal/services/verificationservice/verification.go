@@ -0,0 +1,203 @@
+package verificationservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+)
+
+const (
+	tokenIssuer = "postr-backend"
+	// tokenAudience tells verification tokens apart from the access tokens signed with the same secret
+	tokenAudience = "email-verification"
+
+	// verifyPath is the endpoint the emailed link points to
+	verifyPath = "/auth/verify"
+)
+
+// verificationClaims are the claims of a verification token, which is only good for the email it was sent to
+type verificationClaims struct {
+	jwt.RegisteredClaims
+	Email string `json:"email"`
+}
+
+type service struct {
+	usersRepo usersRepo
+	mailer    domain.Mailer
+	secret    []byte
+	tokenTTL  time.Duration
+	publicURL string
+	now       func() time.Time
+	logger    *zap.Logger
+}
+
+// New creates the verification service. Tokens are signed with secret and expire after tokenTTL, the emailed
+// link points to the API at publicURL
+func New(usersRepo usersRepo, mailer domain.Mailer, secret []byte, tokenTTL time.Duration, publicURL string, logger *zap.Logger, opts ...Option) domain.VerificationService {
+	logger = logger.With(zap.String("package", "verificationservice"))
+
+	svc := &service{
+		usersRepo: usersRepo,
+		mailer:    mailer,
+		secret:    secret,
+		tokenTTL:  tokenTTL,
+		publicURL: publicURL,
+		now:       time.Now,
+		logger:    logger,
+	}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+// Option changes how the service is set up
+type Option func(*service)
+
+// WithClock makes the service read the time from now instead of the system clock
+func WithClock(now func() time.Time) Option {
+	return func(h *service) {
+		h.now = now
+	}
+}
+
+//go:generate mockgen -destination=./mocks/mock_usersrepo.go -package=mocks github.com/victor-nach/postr-backend/internal/services/verificationservice usersRepo
+type usersRepo interface {
+	Get(ctx context.Context, id string) (*domain.User, error)
+	MarkEmailVerified(ctx context.Context, id string, email string, at time.Time) error
+}
+
+func (h *service) SendVerification(ctx context.Context, userID string) error {
+	logr := h.logger.With(zap.String("method", "SendVerification"))
+
+	user, err := h.getUser(ctx, userID)
+	if err != nil {
+		logr.Info("Unable to retrieve user", zap.String("user_id", userID), zap.Error(err))
+		return err
+	}
+
+	if user.EmailVerified() {
+		logr.Info("Email already verified", zap.String("user_id", userID))
+		return domain.ErrEmailAlreadyVerified
+	}
+
+	now := h.now()
+	expiresAt := now.Add(h.tokenTTL)
+
+	claims := verificationClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    tokenIssuer,
+			Audience:  jwt.ClaimStrings{tokenAudience},
+			Subject:   user.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Email: user.Email,
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(h.secret)
+	if err != nil {
+		logr.Error("Error signing verification token", zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	link := h.publicURL + verifyPath + "?" + url.Values{"token": {token}}.Encode()
+
+	email := domain.Email{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Confirm that this is your email address by opening the link below:\n\n"+
+			"%s\n\n"+
+			"The link expires on %s. If you did not sign up to postr, you can ignore this email.\n",
+			user.Firstname, link, expiresAt.UTC().Format(time.RFC1123)),
+	}
+
+	if err := h.mailer.Send(ctx, email); err != nil {
+		logr.Error("Error sending verification email", zap.String("user_id", userID), zap.Error(err))
+		return domain.ErrInternalServer
+	}
+
+	logr.Info("Verification email sent successfully", zap.String("user_id", userID))
+	return nil
+}
+
+// VerifyEmail verifies the email of the token's user. Verifying an email twice changes nothing, while tokens
+// sent to an email the user has changed since are rejected
+func (h *service) VerifyEmail(ctx context.Context, token string) (*domain.User, error) {
+	logr := h.logger.With(zap.String("method", "VerifyEmail"))
+
+	claims := &verificationClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+		return h.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithAudience(tokenAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(h.now),
+	)
+	if err != nil || claims.Subject == "" || claims.Email == "" {
+		logr.Info("Invalid verification token", zap.Error(err))
+		return nil, domain.ErrInvalidVerificationToken
+	}
+
+	user, err := h.getUser(ctx, claims.Subject)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			logr.Info("Verification token of a deleted user", zap.String("user_id", claims.Subject))
+			return nil, domain.ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+
+	if user.Email != claims.Email {
+		logr.Info("Verification token of a previous email", zap.String("user_id", user.ID))
+		return nil, domain.ErrInvalidVerificationToken
+	}
+
+	if user.EmailVerified() {
+		logr.Info("Email already verified", zap.String("user_id", user.ID))
+		return user, nil
+	}
+
+	now := h.now()
+	if err := h.usersRepo.MarkEmailVerified(ctx, user.ID, claims.Email, now); err != nil {
+		// The user changed their email, or was deleted, in the meantime
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logr.Info("Verification token of a previous email", zap.String("user_id", user.ID))
+			return nil, domain.ErrInvalidVerificationToken
+		}
+
+		logr.Error("Error verifying email", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+	user.EmailVerifiedAt = &now
+
+	logr.Info("Email verified successfully", zap.String("user_id", user.ID))
+	return user, nil
+}
+
+func (h *service) getUser(ctx context.Context, id string) (*domain.User, error) {
+	user, err := h.usersRepo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+
+		h.logger.Error("Error retrieving user", zap.Error(err))
+		return nil, domain.ErrInternalServer
+	}
+
+	return user, nil
+}
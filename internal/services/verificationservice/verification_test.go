@@ -0,0 +1,138 @@
+package verificationservice_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/victor-nach/postr-backend/internal/domain"
+	domainmocks "github.com/victor-nach/postr-backend/internal/domain/mocks"
+	"github.com/victor-nach/postr-backend/internal/services/verificationservice"
+	"github.com/victor-nach/postr-backend/internal/services/verificationservice/mocks"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// sendToken sends a verification email to the user and returns the token in its link
+func sendToken(t *testing.T, svc domain.VerificationService, mockUsersRepo *mocks.MockusersRepo, mockMailer *domainmocks.MockMailer, user domain.User) string {
+	ctx := context.Background()
+
+	var sent domain.Email
+	mockUsersRepo.EXPECT().Get(ctx, user.ID).Return(&user, nil)
+	mockMailer.EXPECT().Send(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, email domain.Email) error {
+		sent = email
+		return nil
+	})
+
+	require.NoError(t, svc.SendVerification(ctx, user.ID))
+	require.Equal(t, user.Email, sent.To)
+
+	link := regexp.MustCompile(`http://localhost:8080/auth/verify\?\S+`).FindString(sent.Body)
+	require.NotEmpty(t, link, "the email should link to the verify endpoint")
+
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	return parsed.Query().Get("token")
+}
+
+func TestService_SendAndVerify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockMailer := domainmocks.NewMockMailer(ctrl)
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	svc := verificationservice.New(mockUsersRepo, mockMailer, testSecret, 24*time.Hour, "http://localhost:8080", zap.NewNop(), verificationservice.WithClock(func() time.Time { return now }))
+
+	ctx := context.Background()
+	user := domain.User{ID: "u1", Firstname: "Alice", Email: "alice@example.com"}
+	token := sendToken(t, svc, mockUsersRepo, mockMailer, user)
+
+	mockUsersRepo.EXPECT().Get(ctx, "u1").Return(&user, nil)
+	mockUsersRepo.EXPECT().MarkEmailVerified(ctx, "u1", "alice@example.com", now).Return(nil)
+
+	verified, err := svc.VerifyEmail(ctx, token)
+	require.NoError(t, err)
+	require.True(t, verified.EmailVerified())
+	require.Equal(t, now, *verified.EmailVerifiedAt)
+
+	// Following the link again changes nothing
+	mockUsersRepo.EXPECT().Get(ctx, "u1").Return(verified, nil)
+
+	again, err := svc.VerifyEmail(ctx, token)
+	require.NoError(t, err)
+	require.Equal(t, verified, again)
+}
+
+func TestService_SendVerification_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockMailer := domainmocks.NewMockMailer(ctrl)
+	svc := verificationservice.New(mockUsersRepo, mockMailer, testSecret, 24*time.Hour, "http://localhost:8080", zap.NewNop())
+	ctx := context.Background()
+	verifiedAt := time.Now()
+
+	mockUsersRepo.EXPECT().Get(ctx, "u1").Return(nil, gorm.ErrRecordNotFound)
+	require.Equal(t, domain.ErrUserNotFound, svc.SendVerification(ctx, "u1"))
+
+	mockUsersRepo.EXPECT().Get(ctx, "u1").Return(&domain.User{ID: "u1", Email: "alice@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+	require.Equal(t, domain.ErrEmailAlreadyVerified, svc.SendVerification(ctx, "u1"))
+
+	mockUsersRepo.EXPECT().Get(ctx, "u1").Return(&domain.User{ID: "u1", Email: "alice@example.com"}, nil)
+	mockMailer.EXPECT().Send(ctx, gomock.Any()).Return(errors.New("disk full"))
+	require.Equal(t, domain.ErrInternalServer, svc.SendVerification(ctx, "u1"))
+}
+
+func TestService_VerifyEmail_InvalidToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsersRepo := mocks.NewMockusersRepo(ctrl)
+	mockMailer := domainmocks.NewMockMailer(ctrl)
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	clock := now
+	svc := verificationservice.New(mockUsersRepo, mockMailer, testSecret, 24*time.Hour, "http://localhost:8080", zap.NewNop(), verificationservice.WithClock(func() time.Time { return clock }))
+
+	ctx := context.Background()
+	user := domain.User{ID: "u1", Firstname: "Alice", Email: "alice@example.com"}
+	token := sendToken(t, svc, mockUsersRepo, mockMailer, user)
+
+	_, err := svc.VerifyEmail(ctx, "not-a-token")
+	require.Equal(t, domain.ErrInvalidVerificationToken, err)
+
+	// Signed with another secret
+	other := verificationservice.New(mockUsersRepo, mockMailer, []byte("another secret that is long enough"), 24*time.Hour, "http://localhost:8080", zap.NewNop())
+	_, err = other.VerifyEmail(ctx, token)
+	require.Equal(t, domain.ErrInvalidVerificationToken, err)
+
+	// Sent to the email the user had before changing it
+	mockUsersRepo.EXPECT().Get(ctx, "u1").Return(&domain.User{ID: "u1", Email: "alice@example.org"}, nil)
+	_, err = svc.VerifyEmail(ctx, token)
+	require.Equal(t, domain.ErrInvalidVerificationToken, err)
+
+	// The email changed between reading the user and verifying it
+	mockUsersRepo.EXPECT().Get(ctx, "u1").Return(&user, nil)
+	mockUsersRepo.EXPECT().MarkEmailVerified(ctx, "u1", "alice@example.com", now).Return(gorm.ErrRecordNotFound)
+	_, err = svc.VerifyEmail(ctx, token)
+	require.Equal(t, domain.ErrInvalidVerificationToken, err)
+
+	// Of a deleted user
+	mockUsersRepo.EXPECT().Get(ctx, "u1").Return(nil, gorm.ErrRecordNotFound)
+	_, err = svc.VerifyEmail(ctx, token)
+	require.Equal(t, domain.ErrInvalidVerificationToken, err)
+
+	// Expired
+	clock = now.Add(25 * time.Hour)
+	_, err = svc.VerifyEmail(ctx, token)
+	require.Equal(t, domain.ErrInvalidVerificationToken, err)
+}
@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS idx_posts_user_id;
+
+CREATE TABLE posts_old (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+INSERT INTO posts_old (id, user_id, title, body, created_at)
+SELECT id, user_id, title, body, created_at FROM posts;
+
+DROP TABLE posts;
+
+ALTER TABLE posts_old RENAME TO posts;
@@ -0,0 +1,19 @@
+-- SQLite cannot alter a foreign key in place, so rebuild posts with an explicit
+-- ON DELETE RESTRICT. The application decides what happens to a deleted user's posts.
+CREATE TABLE posts_new (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT
+);
+
+INSERT INTO posts_new (id, user_id, title, body, created_at)
+SELECT id, user_id, title, body, created_at FROM posts;
+
+DROP TABLE posts;
+
+ALTER TABLE posts_new RENAME TO posts;
+
+CREATE INDEX IF NOT EXISTS idx_posts_user_id ON posts(user_id);
@@ -0,0 +1,15 @@
+-- Track when a post was last edited
+ALTER TABLE posts ADD COLUMN updated_at DATETIME;
+UPDATE posts SET updated_at = created_at;
+
+-- Previous versions of edited posts, the current version lives in posts
+CREATE TABLE IF NOT EXISTS post_revisions (
+    id TEXT PRIMARY KEY,
+    post_id TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    UNIQUE (post_id, version)
+);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_posts_title;
+DROP INDEX IF EXISTS idx_posts_created_at;
+DROP INDEX IF EXISTS idx_posts_user_id_title;
+DROP INDEX IF EXISTS idx_posts_user_id_created_at;
+
+CREATE INDEX IF NOT EXISTS idx_posts_user_id ON posts(user_id);
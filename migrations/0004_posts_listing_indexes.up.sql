@@ -0,0 +1,8 @@
+-- Post listings are ordered by (created_at, id) or (title, id), optionally per user,
+-- these indexes let keyset pages seek straight to their cursor.
+DROP INDEX IF EXISTS idx_posts_user_id;
+
+CREATE INDEX IF NOT EXISTS idx_posts_user_id_created_at ON posts(user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_posts_user_id_title ON posts(user_id, title, id);
+CREATE INDEX IF NOT EXISTS idx_posts_created_at ON posts(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_posts_title ON posts(title, id);
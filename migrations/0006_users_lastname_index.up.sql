@@ -0,0 +1,2 @@
+-- The user listing can be sorted, and cursor paged, by (lastname, id)
+CREATE INDEX IF NOT EXISTS idx_users_lastname ON users(lastname, id);
@@ -0,0 +1,24 @@
+-- Full-text index over post titles and bodies. posts has no INTEGER PRIMARY KEY, so its rowids
+-- may change on VACUUM and cannot back an external content table; the index keeps its own copy
+-- of the text keyed by post_id instead, kept in sync by the triggers below.
+CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(
+    post_id UNINDEXED,
+    title,
+    body,
+    tokenize = 'porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS posts_fts_insert AFTER INSERT ON posts BEGIN
+    INSERT INTO posts_fts (post_id, title, body) VALUES (new.id, new.title, new.body);
+END;
+
+CREATE TRIGGER IF NOT EXISTS posts_fts_update AFTER UPDATE OF title, body ON posts BEGIN
+    UPDATE posts_fts SET title = new.title, body = new.body WHERE post_id = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS posts_fts_delete AFTER DELETE ON posts BEGIN
+    DELETE FROM posts_fts WHERE post_id = old.id;
+END;
+
+INSERT INTO posts_fts (post_id, title, body)
+SELECT id, title, body FROM posts;
@@ -0,0 +1,10 @@
+-- Tombstones do not survive the rollback
+DELETE FROM post_revisions WHERE post_id IN (SELECT id FROM posts WHERE deleted_at IS NOT NULL);
+DELETE FROM posts WHERE deleted_at IS NOT NULL;
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_posts_deleted_at;
+DROP INDEX IF EXISTS idx_users_deleted_at;
+
+ALTER TABLE posts DROP COLUMN deleted_at;
+ALTER TABLE users DROP COLUMN deleted_at;
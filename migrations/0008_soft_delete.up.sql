@@ -0,0 +1,6 @@
+-- Deleted users and posts are kept as tombstones until they are purged
+ALTER TABLE users ADD COLUMN deleted_at DATETIME;
+ALTER TABLE posts ADD COLUMN deleted_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_posts_deleted_at ON posts(deleted_at);
@@ -0,0 +1,2 @@
+-- Users sign in with a password, existing users have none until they set one
+ALTER TABLE users ADD COLUMN password_hash TEXT NOT NULL DEFAULT '';
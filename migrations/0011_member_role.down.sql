@@ -0,0 +1 @@
+DELETE FROM user_roles WHERE role IN ('member', 'moderator');
@@ -0,0 +1,3 @@
+-- Every user is a member, which lets them post
+INSERT OR IGNORE INTO user_roles (user_id, role)
+SELECT id, 'member' FROM users WHERE id <> '00000000-0000-0000-0000-000000000000';
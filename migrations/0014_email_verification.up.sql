@@ -0,0 +1,4 @@
+-- New users have to verify their email, existing users are taken as verified so they can keep posting
+ALTER TABLE users ADD COLUMN email_verified_at DATETIME;
+
+UPDATE users SET email_verified_at = created_at;
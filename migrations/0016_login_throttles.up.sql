@@ -0,0 +1,11 @@
+-- Recent failed logins per account and per IP address, keyed "account:<email>" and "ip:<address>".
+-- Failures back off and then lock out further logins, lockouts counts how often that happened
+CREATE TABLE IF NOT EXISTS login_throttles (
+    key TEXT PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failed_at DATETIME,
+    locked_until DATETIME,
+    lockouts INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_throttles_last_failed_at ON login_throttles (last_failed_at);
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS comments_count_delete;
+DROP TRIGGER IF EXISTS comments_count_insert;
+ALTER TABLE posts DROP COLUMN comment_count;
+DROP INDEX IF EXISTS idx_comments_user_id;
+DROP INDEX IF EXISTS idx_comments_post_id_created_at;
+DROP TABLE IF EXISTS comments;
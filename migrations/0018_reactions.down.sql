@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS reactions_count_delete;
+DROP TRIGGER IF EXISTS reactions_count_insert;
+DROP TABLE IF EXISTS reaction_counts;
+DROP INDEX IF EXISTS idx_reactions_user_id;
+DROP TABLE IF EXISTS reactions;
@@ -0,0 +1,30 @@
+-- Reactions to posts, at most one of each kind per user and post. reaction_counts is kept up to date by the
+-- triggers below, in the statement that adds or removes the reaction, so concurrent reactions never lose a count
+CREATE TABLE IF NOT EXISTS reactions (
+    post_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, user_id, kind),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_reactions_user_id ON reactions (user_id);
+
+CREATE TABLE IF NOT EXISTS reaction_counts (
+    post_id TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, kind),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+CREATE TRIGGER IF NOT EXISTS reactions_count_insert AFTER INSERT ON reactions BEGIN
+    INSERT INTO reaction_counts (post_id, kind, count) VALUES (new.post_id, new.kind, 1)
+        ON CONFLICT (post_id, kind) DO UPDATE SET count = count + 1;
+END;
+
+CREATE TRIGGER IF NOT EXISTS reactions_count_delete AFTER DELETE ON reactions BEGIN
+    UPDATE reaction_counts SET count = count - 1 WHERE post_id = old.post_id AND kind = old.kind;
+END;
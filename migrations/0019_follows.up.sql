@@ -0,0 +1,16 @@
+-- Users following each other. A user follows another at most once, and the follows of purged users go with them
+CREATE TABLE IF NOT EXISTS follows (
+    id TEXT PRIMARY KEY,
+    follower_id TEXT NOT NULL,
+    followee_id TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (followee_id) REFERENCES users(id) ON DELETE CASCADE,
+    CHECK (follower_id <> followee_id)
+);
+
+-- The feed looks follows up by (follower_id, followee_id), the follower and following lists are paged most recent
+-- first
+CREATE UNIQUE INDEX IF NOT EXISTS idx_follows_follower_id_followee_id ON follows (follower_id, followee_id);
+CREATE INDEX IF NOT EXISTS idx_follows_follower_id_created_at ON follows (follower_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_follows_followee_id_created_at ON follows (followee_id, created_at, id);
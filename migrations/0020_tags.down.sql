@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_post_tags_tag_id_post_id;
+DROP TABLE IF EXISTS post_tags;
+DROP INDEX IF EXISTS idx_tags_name;
+DROP TABLE IF EXISTS tags;
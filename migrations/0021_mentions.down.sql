@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_mentions_user_id_post_id;
+DROP TABLE IF EXISTS mentions;
+DROP INDEX IF EXISTS idx_users_handle;
+ALTER TABLE users DROP COLUMN handle;
@@ -0,0 +1,16 @@
+-- Users can pick a handle to be @mentioned by, handles are stored lowercased and users without one have NULL
+ALTER TABLE users ADD COLUMN handle TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_handle ON users (handle);
+
+-- Users @mentioned in posts. A post mentions a user at most once, the mentions of a user are listed by user
+CREATE TABLE IF NOT EXISTS mentions (
+    post_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, user_id),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_mentions_user_id_post_id ON mentions (user_id, post_id);
@@ -0,0 +1 @@
+-- The offsets times were stored with are not kept, times stay in UTC
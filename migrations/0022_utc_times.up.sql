@@ -0,0 +1,150 @@
+-- Times used to be stored with the offset of the server's time zone, which text comparisons and sorts get
+-- wrong across zones and daylight saving changes. Rewrite them in UTC as they are now stored. The driver
+-- writes "2006-01-02 15:04:05.999999999 -0700 MST", at times followed by a monotonic clock reading: the
+-- offset follows the first space after the seconds, and is subtracted to get UTC
+
+UPDATE users SET created_at = strftime('%Y-%m-%d %H:%M:%S', substr(created_at, 1, 19),
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(created_at, 20, instr(substr(created_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND created_at NOT GLOB '* +0000 UTC*';
+
+UPDATE users SET deleted_at = strftime('%Y-%m-%d %H:%M:%S', substr(deleted_at, 1, 19),
+        iif(substr(deleted_at, instr(substr(deleted_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(deleted_at, instr(substr(deleted_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(deleted_at, instr(substr(deleted_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(deleted_at, instr(substr(deleted_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(deleted_at, 20, instr(substr(deleted_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE deleted_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND deleted_at NOT GLOB '* +0000 UTC*';
+
+UPDATE users SET email_verified_at = strftime('%Y-%m-%d %H:%M:%S', substr(email_verified_at, 1, 19),
+        iif(substr(email_verified_at, instr(substr(email_verified_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(email_verified_at, instr(substr(email_verified_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(email_verified_at, instr(substr(email_verified_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(email_verified_at, instr(substr(email_verified_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(email_verified_at, 20, instr(substr(email_verified_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE email_verified_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND email_verified_at NOT GLOB '* +0000 UTC*';
+
+UPDATE posts SET created_at = strftime('%Y-%m-%d %H:%M:%S', substr(created_at, 1, 19),
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(created_at, 20, instr(substr(created_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND created_at NOT GLOB '* +0000 UTC*';
+
+UPDATE posts SET updated_at = strftime('%Y-%m-%d %H:%M:%S', substr(updated_at, 1, 19),
+        iif(substr(updated_at, instr(substr(updated_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(updated_at, instr(substr(updated_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(updated_at, instr(substr(updated_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(updated_at, instr(substr(updated_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(updated_at, 20, instr(substr(updated_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE updated_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND updated_at NOT GLOB '* +0000 UTC*';
+
+UPDATE posts SET deleted_at = strftime('%Y-%m-%d %H:%M:%S', substr(deleted_at, 1, 19),
+        iif(substr(deleted_at, instr(substr(deleted_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(deleted_at, instr(substr(deleted_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(deleted_at, instr(substr(deleted_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(deleted_at, instr(substr(deleted_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(deleted_at, 20, instr(substr(deleted_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE deleted_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND deleted_at NOT GLOB '* +0000 UTC*';
+
+UPDATE post_revisions SET created_at = strftime('%Y-%m-%d %H:%M:%S', substr(created_at, 1, 19),
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(created_at, 20, instr(substr(created_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND created_at NOT GLOB '* +0000 UTC*';
+
+UPDATE user_roles SET created_at = strftime('%Y-%m-%d %H:%M:%S', substr(created_at, 1, 19),
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(created_at, 20, instr(substr(created_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND created_at NOT GLOB '* +0000 UTC*';
+
+UPDATE api_keys SET created_at = strftime('%Y-%m-%d %H:%M:%S', substr(created_at, 1, 19),
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(created_at, 20, instr(substr(created_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND created_at NOT GLOB '* +0000 UTC*';
+
+UPDATE api_keys SET last_used_at = strftime('%Y-%m-%d %H:%M:%S', substr(last_used_at, 1, 19),
+        iif(substr(last_used_at, instr(substr(last_used_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(last_used_at, instr(substr(last_used_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(last_used_at, instr(substr(last_used_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(last_used_at, instr(substr(last_used_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(last_used_at, 20, instr(substr(last_used_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE last_used_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND last_used_at NOT GLOB '* +0000 UTC*';
+
+UPDATE api_keys SET revoked_at = strftime('%Y-%m-%d %H:%M:%S', substr(revoked_at, 1, 19),
+        iif(substr(revoked_at, instr(substr(revoked_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(revoked_at, instr(substr(revoked_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(revoked_at, instr(substr(revoked_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(revoked_at, instr(substr(revoked_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(revoked_at, 20, instr(substr(revoked_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE revoked_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND revoked_at NOT GLOB '* +0000 UTC*';
+
+UPDATE sessions SET created_at = strftime('%Y-%m-%d %H:%M:%S', substr(created_at, 1, 19),
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(created_at, 20, instr(substr(created_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND created_at NOT GLOB '* +0000 UTC*';
+
+UPDATE sessions SET last_used_at = strftime('%Y-%m-%d %H:%M:%S', substr(last_used_at, 1, 19),
+        iif(substr(last_used_at, instr(substr(last_used_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(last_used_at, instr(substr(last_used_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(last_used_at, instr(substr(last_used_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(last_used_at, instr(substr(last_used_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(last_used_at, 20, instr(substr(last_used_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE last_used_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND last_used_at NOT GLOB '* +0000 UTC*';
+
+UPDATE sessions SET expires_at = strftime('%Y-%m-%d %H:%M:%S', substr(expires_at, 1, 19),
+        iif(substr(expires_at, instr(substr(expires_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(expires_at, instr(substr(expires_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(expires_at, instr(substr(expires_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(expires_at, instr(substr(expires_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(expires_at, 20, instr(substr(expires_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE expires_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND expires_at NOT GLOB '* +0000 UTC*';
+
+UPDATE sessions SET revoked_at = strftime('%Y-%m-%d %H:%M:%S', substr(revoked_at, 1, 19),
+        iif(substr(revoked_at, instr(substr(revoked_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(revoked_at, instr(substr(revoked_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(revoked_at, instr(substr(revoked_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(revoked_at, instr(substr(revoked_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(revoked_at, 20, instr(substr(revoked_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE revoked_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND revoked_at NOT GLOB '* +0000 UTC*';
+
+-- Audit events are otherwise never changed
+DROP TRIGGER IF EXISTS audit_events_no_update;
+
+UPDATE audit_events SET created_at = strftime('%Y-%m-%d %H:%M:%S', substr(created_at, 1, 19),
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(created_at, 20, instr(substr(created_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND created_at NOT GLOB '* +0000 UTC*';
+
+CREATE TRIGGER IF NOT EXISTS audit_events_no_update BEFORE UPDATE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit events cannot be changed');
+END;
+
+UPDATE login_throttles SET last_failed_at = strftime('%Y-%m-%d %H:%M:%S', substr(last_failed_at, 1, 19),
+        iif(substr(last_failed_at, instr(substr(last_failed_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(last_failed_at, instr(substr(last_failed_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(last_failed_at, instr(substr(last_failed_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(last_failed_at, instr(substr(last_failed_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(last_failed_at, 20, instr(substr(last_failed_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE last_failed_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND last_failed_at NOT GLOB '* +0000 UTC*';
+
+UPDATE login_throttles SET locked_until = strftime('%Y-%m-%d %H:%M:%S', substr(locked_until, 1, 19),
+        iif(substr(locked_until, instr(substr(locked_until, 20), ' ') + 20, 1) = '-', '+', '-') || substr(locked_until, instr(substr(locked_until, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(locked_until, instr(substr(locked_until, 20), ' ') + 20, 1) = '-', '+', '-') || substr(locked_until, instr(substr(locked_until, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(locked_until, 20, instr(substr(locked_until, 20), ' ') - 1) || ' +0000 UTC'
+WHERE locked_until GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND locked_until NOT GLOB '* +0000 UTC*';
+
+UPDATE comments SET created_at = strftime('%Y-%m-%d %H:%M:%S', substr(created_at, 1, 19),
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(created_at, 20, instr(substr(created_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND created_at NOT GLOB '* +0000 UTC*';
+
+UPDATE reactions SET created_at = strftime('%Y-%m-%d %H:%M:%S', substr(created_at, 1, 19),
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(created_at, 20, instr(substr(created_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND created_at NOT GLOB '* +0000 UTC*';
+
+UPDATE follows SET created_at = strftime('%Y-%m-%d %H:%M:%S', substr(created_at, 1, 19),
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(created_at, 20, instr(substr(created_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND created_at NOT GLOB '* +0000 UTC*';
+
+UPDATE tags SET created_at = strftime('%Y-%m-%d %H:%M:%S', substr(created_at, 1, 19),
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(created_at, 20, instr(substr(created_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND created_at NOT GLOB '* +0000 UTC*';
+
+UPDATE mentions SET created_at = strftime('%Y-%m-%d %H:%M:%S', substr(created_at, 1, 19),
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 21, 2) || ' hours',
+        iif(substr(created_at, instr(substr(created_at, 20), ' ') + 20, 1) = '-', '+', '-') || substr(created_at, instr(substr(created_at, 20), ' ') + 23, 2) || ' minutes'
+    ) || substr(created_at, 20, instr(substr(created_at, 20), ' ') - 1) || ' +0000 UTC'
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *' AND created_at NOT GLOB '* +0000 UTC*';
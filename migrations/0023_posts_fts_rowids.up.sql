@@ -0,0 +1,44 @@
+-- The index triggers looked index rows up by post_id, which FTS5 cannot index, so every edit and delete
+-- scanned the whole index. Each post now gets a stable integer id from posts_fts_ids, used as the rowid of
+-- its index row, so the triggers find it through the rowid. posts' own rowids may change on VACUUM and
+-- cannot serve instead.
+DROP TRIGGER IF EXISTS posts_fts_delete;
+DROP TRIGGER IF EXISTS posts_fts_update;
+DROP TRIGGER IF EXISTS posts_fts_insert;
+
+DROP TABLE IF EXISTS posts_fts;
+
+CREATE TABLE IF NOT EXISTS posts_fts_ids (
+    id INTEGER PRIMARY KEY,
+    post_id TEXT NOT NULL UNIQUE
+);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(
+    post_id UNINDEXED,
+    title,
+    body,
+    tokenize = 'porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS posts_fts_insert AFTER INSERT ON posts BEGIN
+    INSERT INTO posts_fts_ids (post_id) VALUES (new.id);
+    INSERT INTO posts_fts (rowid, post_id, title, body)
+    VALUES ((SELECT id FROM posts_fts_ids WHERE post_id = new.id), new.id, new.title, new.body);
+END;
+
+CREATE TRIGGER IF NOT EXISTS posts_fts_update AFTER UPDATE OF title, body ON posts BEGIN
+    UPDATE posts_fts SET title = new.title, body = new.body
+    WHERE rowid = (SELECT id FROM posts_fts_ids WHERE post_id = old.id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS posts_fts_delete AFTER DELETE ON posts BEGIN
+    DELETE FROM posts_fts WHERE rowid = (SELECT id FROM posts_fts_ids WHERE post_id = old.id);
+    DELETE FROM posts_fts_ids WHERE post_id = old.id;
+END;
+
+INSERT INTO posts_fts_ids (post_id)
+SELECT id FROM posts;
+
+INSERT INTO posts_fts (rowid, post_id, title, body)
+SELECT posts_fts_ids.id, posts.id, posts.title, posts.body
+FROM posts JOIN posts_fts_ids ON posts_fts_ids.post_id = posts.id;
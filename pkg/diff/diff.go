@@ -0,0 +1,144 @@
+package diff
+
+import (
+	"slices"
+	"strings"
+)
+
+// Op is the kind of change applied to a line
+type Op string
+
+const (
+	Equal  Op = "equal"
+	Insert Op = "insert"
+	Delete Op = "delete"
+)
+
+// Line is a single line of an edit script
+type Line struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines returns the line by line edit script that turns a into b,
+// based on the longest common subsequence of their lines
+func Lines(a, b string) []Line {
+	x, y := split(a), split(b)
+
+	// Lines are compared by number, each distinct line getting its own
+	numbers := map[string]int{}
+	number := func(texts []string) []int {
+		numbered := make([]int, len(texts))
+		for i, text := range texts {
+			n, ok := numbers[text]
+			if !ok {
+				n = len(numbers)
+				numbers[text] = n
+			}
+			numbered[i] = n
+		}
+		return numbered
+	}
+
+	d := differ{x: x, y: y}
+	return d.script(make([]Line, 0, max(len(x), len(y))), number(x), number(y), 0, 0)
+}
+
+// differ holds the lines of the two texts, which script works through by number
+type differ struct {
+	x, y []string
+}
+
+// script appends the edit script that turns x into y to lines, i and j being where x and y start in the texts.
+// Following Hirschberg, it halves x and finds where a longest common subsequence crosses the half in y, so the
+// subsequence is found in space linear in the lines
+func (d differ) script(lines []Line, x, y []int, i, j int) []Line {
+	for len(x) > 0 && len(y) > 0 && x[0] == y[0] {
+		lines = append(lines, Line{Op: Equal, Text: d.x[i]})
+		x, y = x[1:], y[1:]
+		i, j = i+1, j+1
+	}
+	common := 0
+	for common < len(x) && common < len(y) && x[len(x)-1-common] == y[len(y)-1-common] {
+		common++
+	}
+	x, y = x[:len(x)-common], y[:len(y)-common]
+
+	switch {
+	case len(x) == 0:
+		lines = appendLines(lines, Insert, d.y[j:j+len(y)])
+	case len(y) == 0:
+		lines = appendLines(lines, Delete, d.x[i:i+len(x)])
+	case len(x) == 1:
+		if k := slices.Index(y, x[0]); k >= 0 {
+			lines = appendLines(lines, Insert, d.y[j:j+k])
+			lines = append(lines, Line{Op: Equal, Text: d.x[i]})
+			lines = appendLines(lines, Insert, d.y[j+k+1:j+len(y)])
+		} else {
+			lines = appendLines(lines, Delete, d.x[i:i+1])
+			lines = appendLines(lines, Insert, d.y[j:j+len(y)])
+		}
+	default:
+		half := len(x) / 2
+		front, back := lcsFront(x[:half], y), lcsBack(x[half:], y)
+
+		// The earliest crossing keeps deletions ahead of insertions
+		cross := 0
+		for k := range front {
+			if front[k]+back[k] > front[cross]+back[cross] {
+				cross = k
+			}
+		}
+		lines = d.script(lines, x[:half], y[:cross], i, j)
+		lines = d.script(lines, x[half:], y[cross:], i+half, j+cross)
+	}
+
+	i += len(x)
+	return appendLines(lines, Equal, d.x[i:i+common])
+}
+
+// lcsFront returns the length of the longest common subsequence of x and y[:j] for every j
+func lcsFront(x, y []int) []int {
+	prev, cur := make([]int, len(y)+1), make([]int, len(y)+1)
+	for i := range x {
+		for j := range y {
+			if x[i] == y[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+// lcsBack returns the length of the longest common subsequence of x and y[j:] for every j
+func lcsBack(x, y []int) []int {
+	prev, cur := make([]int, len(y)+1), make([]int, len(y)+1)
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				cur[j] = prev[j+1] + 1
+			} else {
+				cur[j] = max(prev[j], cur[j+1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+func appendLines(lines []Line, op Op, texts []string) []Line {
+	for _, text := range texts {
+		lines = append(lines, Line{Op: op, Text: text})
+	}
+	return lines
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
@@ -0,0 +1,85 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLines(t *testing.T) {
+	a := "first line\nsecond line\nthird line"
+	b := "first line\nchanged line\nthird line\nfourth line"
+
+	expected := []Line{
+		{Op: Equal, Text: "first line"},
+		{Op: Delete, Text: "second line"},
+		{Op: Insert, Text: "changed line"},
+		{Op: Equal, Text: "third line"},
+		{Op: Insert, Text: "fourth line"},
+	}
+
+	require.Equal(t, expected, Lines(a, b))
+}
+
+func TestLines_Empty(t *testing.T) {
+	require.Equal(t, []Line{{Op: Insert, Text: "new"}}, Lines("", "new"))
+	require.Equal(t, []Line{{Op: Delete, Text: "old"}}, Lines("old", ""))
+	require.Empty(t, Lines("", ""))
+}
+
+func TestLines_LongestCommonSubsequence(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		equal int
+	}{
+		{"swapped", "a\nb", "b\na", 1},
+		{"interleaved", "a\nb\nc\nd\ne\nf", "b\nx\nd\ny\nf\na", 3},
+		{"repeated", "a\na\nb\na\na", "a\nb\nb\na", 3},
+		{"disjoint", "a\nb\nc", "x\ny", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := Lines(tt.a, tt.b)
+
+			// The script reads back as both texts, keeping as many lines as can be kept
+			var from, to []string
+			equal := 0
+			for _, line := range lines {
+				if line.Op != Insert {
+					from = append(from, line.Text)
+				}
+				if line.Op != Delete {
+					to = append(to, line.Text)
+				}
+				if line.Op == Equal {
+					equal++
+				}
+			}
+			require.Equal(t, tt.a, strings.Join(from, "\n"))
+			require.Equal(t, tt.b, strings.Join(to, "\n"))
+			require.Equal(t, tt.equal, equal)
+		})
+	}
+}
+
+func TestLines_Large(t *testing.T) {
+	// A table of every pair of lines would take gigabytes here
+	var a, b strings.Builder
+	for i := range 10000 {
+		fmt.Fprintf(&a, "%d\n", i%2)
+		fmt.Fprintf(&b, "%d\n", (i+1)%2)
+	}
+
+	lines := Lines(a.String(), b.String())
+	equal := 0
+	for _, line := range lines {
+		if line.Op == Equal {
+			equal++
+		}
+	}
+	require.Equal(t, 10000, equal)
+}
@@ -0,0 +1,164 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets that have refilled are dropped, a full bucket is no different from none
+const sweepInterval = time.Minute
+
+// Limit lets Requests through every Per, in bursts of up to Requests. The zero Limit lets everything through
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// ParseLimit parses a limit written as requests/period, such as "60/1m", or "0" for no limit
+func ParseLimit(s string) (Limit, error) {
+	if strings.TrimSpace(s) == "0" {
+		return Limit{}, nil
+	}
+
+	requests, per, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid limit %q, must be requests/period such as 60/1m", s)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q, requests must be a positive number", s)
+	}
+
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q, period must be a positive duration such as 1m", s)
+	}
+
+	return Limit{Requests: n, Per: d}, nil
+}
+
+// Unlimited reports whether the limit lets everything through
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Per <= 0
+}
+
+func (l Limit) String() string {
+	if l.Unlimited() {
+		return "0"
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Per)
+}
+
+// interval is how long the bucket takes to win back one request
+func (l Limit) interval() time.Duration {
+	return l.Per / time.Duration(l.Requests)
+}
+
+// Result is the outcome of a request against its bucket
+type Result struct {
+	Allowed bool
+	// Remaining is how many more requests would be let through right now
+	Remaining int
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is how long until the next request would be let through, zero when it would be now
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	limit  Limit
+	// updatedAt is when tokens was last brought up to date
+	updatedAt time.Time
+}
+
+// Limiter keeps a token bucket per key. Each bucket starts full, every request takes a token out and tokens
+// trickle back in at the rate of the bucket's limit
+type Limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	sweptAt time.Time
+	now     func() time.Time
+}
+
+func New() *Limiter {
+	return &Limiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token out of the key's bucket if there is one left. A bucket keeps the limit it was created
+// with until it refills and is dropped
+func (l *Limiter) Allow(key string, limit Limit) Result {
+	if limit.Unlimited() {
+		return Result{Allowed: true, Remaining: math.MaxInt}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok || b.limit != limit {
+		b = &bucket{tokens: float64(limit.Requests), limit: limit, updatedAt: now}
+		l.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.updatedAt)
+	b.tokens = math.Min(float64(limit.Requests), b.tokens+elapsed.Seconds()/limit.interval().Seconds())
+	b.updatedAt = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	result := Result{
+		Allowed:   allowed,
+		Remaining: int(b.tokens),
+		Reset:     durationFor(float64(limit.Requests)-b.tokens, limit),
+	}
+	if b.tokens < 1 {
+		result.RetryAfter = durationFor(1-b.tokens, limit)
+	}
+	return result
+}
+
+// Refund puts back a token Allow took out of the key's bucket, for requests that turned out not to count
+func (l *Limiter) Refund(key string, limit Limit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok || b.limit != limit {
+		return
+	}
+	b.tokens = math.Min(float64(limit.Requests), b.tokens+1)
+}
+
+// sweep drops the buckets that have refilled since they were last used
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.sweptAt) < sweepInterval {
+		return
+	}
+	l.sweptAt = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.updatedAt) >= b.limit.Per {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// durationFor returns how long the limit takes to win back the given number of tokens
+func durationFor(tokens float64, limit Limit) time.Duration {
+	return time.Duration(math.Ceil(tokens * float64(limit.interval())))
+}
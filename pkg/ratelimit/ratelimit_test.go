@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	limit, err := ParseLimit("60/1m")
+	require.NoError(t, err)
+	require.Equal(t, Limit{Requests: 60, Per: time.Minute}, limit)
+	require.Equal(t, "60/1m0s", limit.String())
+
+	limit, err = ParseLimit("0")
+	require.NoError(t, err)
+	require.True(t, limit.Unlimited())
+
+	for _, invalid := range []string{"", "60", "60/", "-1/1m", "ten/1m", "60/0s", "60/soon"} {
+		_, err := ParseLimit(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	limiter := New()
+	limiter.now = func() time.Time { return now }
+	limit := Limit{Requests: 3, Per: 3 * time.Second}
+
+	// The bucket starts full, so a burst of up to Requests goes through
+	for remaining := 2; remaining >= 0; remaining-- {
+		result := limiter.Allow("alice", limit)
+		require.True(t, result.Allowed)
+		require.Equal(t, remaining, result.Remaining)
+	}
+
+	result := limiter.Allow("alice", limit)
+	require.False(t, result.Allowed)
+	require.Equal(t, 0, result.Remaining)
+	require.Equal(t, time.Second, result.RetryAfter)
+	require.Equal(t, 3*time.Second, result.Reset)
+
+	// Other keys have buckets of their own
+	require.True(t, limiter.Allow("bob", limit).Allowed)
+
+	// Tokens trickle back in at Requests every Per
+	now = now.Add(time.Second)
+	result = limiter.Allow("alice", limit)
+	require.True(t, result.Allowed)
+	require.Equal(t, 0, result.Remaining)
+	require.False(t, limiter.Allow("alice", limit).Allowed)
+
+	// The bucket never holds more than Requests
+	now = now.Add(time.Hour)
+	result = limiter.Allow("alice", limit)
+	require.True(t, result.Allowed)
+	require.Equal(t, 2, result.Remaining)
+	require.Zero(t, result.RetryAfter)
+}
+
+func TestLimiter_Refund(t *testing.T) {
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	limiter := New()
+	limiter.now = func() time.Time { return now }
+	limit := Limit{Requests: 2, Per: time.Minute}
+
+	limiter.Allow("alice", limit)
+	limiter.Allow("alice", limit)
+	limiter.Refund("alice", limit)
+	require.True(t, limiter.Allow("alice", limit).Allowed)
+	require.False(t, limiter.Allow("alice", limit).Allowed)
+
+	// A bucket never holds more than Requests
+	limiter.Refund("bob", limit)
+	limiter.Allow("bob", limit)
+	limiter.Refund("bob", limit)
+	limiter.Refund("bob", limit)
+	require.Equal(t, 1, limiter.Allow("bob", limit).Remaining)
+}
+
+func TestLimiter_Sweep(t *testing.T) {
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	limiter := New()
+	limiter.now = func() time.Time { return now }
+
+	limiter.Allow("alice", Limit{Requests: 1, Per: time.Second})
+	limiter.Allow("bob", Limit{Requests: 1, Per: time.Hour})
+
+	now = now.Add(2 * sweepInterval)
+	limiter.Allow("carol", Limit{Requests: 1, Per: time.Second})
+
+	require.NotContains(t, limiter.buckets, "alice", "refilled buckets should be dropped")
+	require.Contains(t, limiter.buckets, "bob")
+	require.Contains(t, limiter.buckets, "carol")
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	limiter := New()
+
+	for i := 0; i < 100; i++ {
+		require.True(t, limiter.Allow("alice", Limit{}).Allowed)
+	}
+	require.Empty(t, limiter.buckets)
+}